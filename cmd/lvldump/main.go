@@ -0,0 +1,84 @@
+package main
+
+import "os"
+import "fmt"
+import "flag"
+import "bytes"
+import "errors"
+import "path/filepath"
+
+import "github.com/tinne26/transition/src/debug"
+import "github.com/tinne26/transition/src/game/level"
+
+// Dumps the Go-built levels in the level file format. Run it from
+// the root of the repository:
+// > go run ./cmd/lvldump [--out <dir>] [--check]
+//
+// Before writing anything, the exported files are loaded back and
+// exported again, and the process fails unless both exports match
+// exactly. Since the exports list all blocks, savepoints, entries
+// and triggers sorted and with absolute positions, this ensures the
+// loaded levels are identical block-for-block to the code-built ones.
+//
+// The exports are printed to stdout unless an output directory is
+// given. Don't write them over assets/levels: the committed level
+// files are edited by hand, with relative positions and variables
+// that the flat exports don't preserve. With --check, the exports
+// are only verified, not written.
+
+func main() {
+	outDir := flag.String("out", "", "output directory for the level files (stdout if empty)")
+	checkOnly := flag.Bool("check", false, "verify the export without writing anything")
+	flag.Parse()
+
+	// build levels from code
+	err := level.CreateAllFromCode(os.DirFS("."))
+	if err != nil { debug.Fatal(err) }
+
+	// export all levels first (loading levels modifies entry points)
+	numLevels := level.NumLevels()
+	exports := make([][]byte, numLevels)
+	for i := 0; i < numLevels; i++ {
+		var buffer bytes.Buffer
+		err = level.Get(level.Key(i)).Export(&buffer)
+		if err != nil { debug.Fatal(level.FilePath(level.Key(i)), ": ", err) }
+		exports[i] = buffer.Bytes()
+	}
+
+	// load exported files back and verify
+	for i := 0; i < numLevels; i++ {
+		err = verifyExport(exports[i], level.FilePath(level.Key(i)))
+		if err != nil { debug.Fatal(err) }
+	}
+
+	// write files or print them
+	for i := 0; i < numLevels; i++ {
+		path := level.FilePath(level.Key(i))
+		switch {
+		case *checkOnly:
+			// nothing to write
+		case *outDir == "":
+			fmt.Printf("# ---- %s ----\n", path)
+			_, err = os.Stdout.Write(exports[i])
+			if err != nil { debug.Fatal(err) }
+			continue
+		default:
+			path = filepath.Join(*outDir, filepath.Base(path))
+			err = os.WriteFile(path, exports[i], 0644)
+			if err != nil { debug.Fatal(err) }
+		}
+		fmt.Fprintf(os.Stderr, "%s: ok (%d bytes)\n", path, len(exports[i]))
+	}
+}
+
+func verifyExport(export []byte, name string) error {
+	lvl, err := level.Load(bytes.NewReader(export), name)
+	if err != nil { return err }
+	var buffer bytes.Buffer
+	err = lvl.Export(&buffer)
+	if err != nil { return err }
+	if !bytes.Equal(buffer.Bytes(), export) {
+		return errors.New(name + ": level loaded from export doesn't match the original")
+	}
+	return nil
+}
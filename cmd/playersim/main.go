@@ -0,0 +1,47 @@
+package main
+
+import "os"
+import "flag"
+
+import "github.com/tinne26/transition/src/debug"
+import "github.com/tinne26/transition/src/game/level"
+import "github.com/tinne26/transition/src/game/player/motion"
+import "github.com/tinne26/transition/src/game/player/playersim"
+
+// Runs a headless player simulation and writes the resulting motion
+// trace to stdout. Run it from the root of the repository:
+// > go run ./cmd/playersim [--entry start_save_left] [--ticks N] script.txt
+//
+// See playersim.ParseScript() for the script format. By default,
+// the simulation runs for as many ticks as the script has.
+
+func main() {
+	entryName := flag.String("entry", "start_save_left", "entry point where the player starts")
+	numTicks := flag.Int("ticks", 0, "number of ticks to simulate (default: script length)")
+	flag.Parse()
+	if flag.NArg() != 1 { debug.Fatal("expected a single script file argument") }
+
+	// load assets
+	filesys := os.DirFS(".")
+	err := level.CreateAll(filesys)
+	if err != nil { debug.Fatal(err) }
+	err = motion.LoadAnimations(filesys)
+	if err != nil { debug.Fatal(err) }
+
+	// parse script
+	file, err := os.Open(flag.Arg(0))
+	if err != nil { debug.Fatal(err) }
+	script, err := playersim.ParseScript(file)
+	file.Close()
+	if err != nil { debug.Fatal(err) }
+	entryKey, found := level.LookupEntryKey(*entryName)
+	if !found { debug.Fatal("unknown entry '" + *entryName + "'") }
+	if *numTicks <= 0 { *numTicks = script.Len() }
+
+	// simulate and write trace
+	sim := playersim.NewAtEntry(entryKey, script)
+	trace, err := sim.Run(*numTicks)
+	if err != nil { debug.Fatal(err) }
+	err = playersim.WriteTrace(os.Stdout, trace)
+	if err != nil { debug.Fatal(err) }
+}
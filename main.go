@@ -5,9 +5,11 @@ import "embed"
 
 import "github.com/hajimehoshi/ebiten/v2"
 
+import "github.com/tinne26/transition/src/lang"
 import "github.com/tinne26/transition/src/debug"
 import "github.com/tinne26/transition/src/shaders"
 import "github.com/tinne26/transition/src/utils"
+import "github.com/tinne26/transition/src/text"
 import "github.com/tinne26/transition/src/game"
 import "github.com/tinne26/transition/src/game/level"
 import "github.com/tinne26/transition/src/game/hint"
@@ -42,10 +44,16 @@ func main() {
 	// better at home if you want
 	err = shaders.LoadAll()
 	if err != nil { debug.Fatal(err) }
+	langCode, found := utils.OsArgValue("--lang")
+	if !found { langCode = lang.Fallback }
+	err = lang.Load(filesys, langCode)
+	if err != nil { debug.Fatal(err) }
 	err = level.CreateAll(filesys)
 	if err != nil { debug.Fatal(err) }
 	err = hint.LoadHintGraphics(filesys)
 	if err != nil { debug.Fatal(err) }
+	err = text.LoadAndSetFont(filesys, "assets/fonts/main.txt")
+	if err != nil { debug.Fatal(err) }
 
 	// set window icon
 	ico16, err := utils.LoadFsImage(filesys, "assets/ico/16x16.png")
@@ -61,4 +69,6 @@ func main() {
 	if err != nil { debug.Fatal(err) }
 	err = ebiten.RunGame(gg)
 	if err != nil { debug.Fatal(err) }
+	err = gg.Close()
+	if err != nil { debug.Fatal(err) }
 }
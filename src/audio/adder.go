@@ -1,19 +1,64 @@
 package audio
 
+import "io"
 import "sync"
+import "errors"
 
 import "github.com/tinne26/transition/src/utils"
 
+// Implemented by sources that can be recycled once an adder
+// with removeOnEOF stops reading from them (see VoicePool).
+type releasable interface {
+	release()
+}
+
 type Adder struct {
 	mutex sync.Mutex
 	sources []StereoProcessor
 	auxBuffer []float32
+	removeOnEOF bool
 }
 
 func NewAdder(sources ...StereoProcessor) *Adder {
 	return &Adder{ sources: sources, auxBuffer: make([]float32, 2048) }
 }
 
+// When enabled, sources that return io.EOF are removed from the
+// adder, and the EOF is not reported. Used for buses, where short
+// sounds come and go while the output keeps going.
+func (self *Adder) SetRemoveOnEOF(remove bool) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.removeOnEOF = remove
+}
+
+// Adds a source to the adder. Safe to call while the adder is
+// being read from the audio thread.
+func (self *Adder) Add(source StereoProcessor) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.sources = append(self.sources, source)
+}
+
+// Removes the given source. Returns false if the source wasn't found.
+func (self *Adder) Remove(source StereoProcessor) bool {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	for i, candidate := range self.sources {
+		if candidate == source {
+			self.sources = append(self.sources[ : i], self.sources[i + 1 : ]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (self *Adder) Len() int {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	return len(self.sources)
+}
+
 func (self *Adder) WriteStereo(buffer []float32) (int, error) {
 	self.mutex.Lock()
 	defer self.mutex.Unlock()
@@ -23,10 +68,8 @@ func (self *Adder) WriteStereo(buffer []float32) (int, error) {
 	if len(buffer) == 0 { return 0, nil }
 
 	// empty case
-	if len(self.sources) == 0 {
-		utils.FastFill(buffer, 0)
-		return len(buffer), nil
-	}
+	utils.FastFill(buffer, 0)
+	if len(self.sources) == 0 { return len(buffer) >> 1, nil }
 
 	// set auxBuffer size matching the input buffer
 	if len(self.auxBuffer) < len(buffer) {
@@ -39,20 +82,23 @@ func (self *Adder) WriteStereo(buffer []float32) (int, error) {
 		self.auxBuffer = self.auxBuffer[ : len(buffer)]
 	}
 
-	// read from first sources
-	n, err := self.sources[0].WriteStereo(buffer)
-	
-	// read from other sources and add (no clipping applied, values may exceed 1.0)
-	for i := 1; i < len(self.sources); i++ {
+	// read from all sources and add (no clipping applied, values may exceed 1.0)
+	var n int
+	var err error
+	for i := 0; i < len(self.sources); {
 		nNth, errNth := self.sources[i].WriteStereo(self.auxBuffer)
-		if errNth != nil && err == nil { err = errNth }
-		if nNth > n {
-			copy(buffer[n*2 : nNth*2], self.auxBuffer[n*2 : nNth*2])
-			n = nNth
-		}
-		for j := 0; j < n*2; j++ {
+		for j := 0; j < nNth*2; j++ {
 			buffer[j] += self.auxBuffer[j]
 		}
+		if nNth > n { n = nNth }
+
+		if errNth != nil && self.removeOnEOF && errors.Is(errNth, io.EOF) {
+			if source, ok := self.sources[i].(releasable); ok { source.release() }
+			self.sources = append(self.sources[ : i], self.sources[i + 1 : ]...)
+			continue
+		}
+		if errNth != nil && err == nil { err = errNth }
+		i += 1
 	}
 
 	return n, err
@@ -73,5 +119,6 @@ func (self *Adder) SeekSample(n int) error {
 func (self *Adder) GetPosition() int64 {
 	self.mutex.Lock()
 	defer self.mutex.Unlock()
+	if len(self.sources) == 0 { return 0 }
 	return self.sources[0].GetPosition() // lame, but whatever
 }
@@ -14,6 +14,11 @@ func min[T Numeric](a, b T) T {
 	return b
 }
 
+func max[T Numeric](a, b T) T {
+	if a >= b { return a }
+	return b
+}
+
 func TimeDurationToOffset(duration time.Duration) int64 {
 	const SampleRate = 44100
 	const BytesPerSample = 4
@@ -0,0 +1,312 @@
+// Package audiotest contains helpers to test audio processing chains
+// offline, without an audio device. Chains are rendered with audio.Render()
+// or audio.RenderTicks(), and the helpers here check the results.
+//
+// Constant sources on a single channel make it easy to follow multiple
+// sources through a mix: for example, for a crossfade, the outgoing BGM
+// can be placed on the left channel and the incoming one on the right.
+package audiotest
+
+import "io"
+import "math"
+import "time"
+import "strconv"
+import "testing"
+
+import "github.com/tinne26/transition/src/audio"
+
+type Channel uint8
+const (
+	Left Channel = iota
+	Right
+	Both // max of both channels
+)
+
+const sampleRate = 44100
+
+func durationToSamples(duration time.Duration) int {
+	return int(audio.TimeDurationToSamples(duration))
+}
+
+func samplesToDuration(samples int) time.Duration {
+	return (time.Duration(samples)*time.Second)/sampleRate
+}
+
+func fmtLevel(level float32) string {
+	return strconv.FormatFloat(float64(level), 'f', 4, 32)
+}
+
+// --- sources ---
+
+var _ audio.StereoProcessor = (*ConstSource)(nil)
+
+// A source that writes the same values forever.
+type ConstSource struct {
+	left, right float32
+	position int64
+}
+
+func NewConstSource(left, right float32) *ConstSource {
+	return &ConstSource{ left: left, right: right }
+}
+
+func (self *ConstSource) WriteStereo(buffer []float32) (int, error) {
+	if len(buffer) & 0b01 != 0 { buffer = buffer[0 : len(buffer) - 1] }
+	for i := 0; i < len(buffer); i += 2 {
+		buffer[i + 0] = self.left
+		buffer[i + 1] = self.right
+	}
+	self.position += int64(len(buffer) >> 1)
+	return len(buffer) >> 1, nil
+}
+
+func (self *ConstSource) SeekSample(n int) error {
+	self.position = int64(n)
+	return nil
+}
+
+func (self *ConstSource) GetPosition() int64 {
+	return self.position
+}
+
+var _ audio.StereoProcessor = (*SineSource)(nil)
+
+// A sine wave source, with the same values on both channels.
+type SineSource struct {
+	frequency float64
+	amplitude float32
+	position int64
+}
+
+func NewSineSource(frequency float64, amplitude float32) *SineSource {
+	return &SineSource{ frequency: frequency, amplitude: amplitude }
+}
+
+func (self *SineSource) WriteStereo(buffer []float32) (int, error) {
+	if len(buffer) & 0b01 != 0 { buffer = buffer[0 : len(buffer) - 1] }
+	for i := 0; i < len(buffer); i += 2 {
+		t := float64(self.position + int64(i >> 1))/sampleRate
+		value := self.amplitude*float32(math.Sin(2*math.Pi*self.frequency*t))
+		buffer[i + 0] = value
+		buffer[i + 1] = value
+	}
+	self.position += int64(len(buffer) >> 1)
+	return len(buffer) >> 1, nil
+}
+
+func (self *SineSource) SeekSample(n int) error {
+	self.position = int64(n)
+	return nil
+}
+
+func (self *SineSource) GetPosition() int64 {
+	return self.position
+}
+
+var _ audio.StereoProcessor = (*ImpulseSource)(nil)
+
+// A source that writes a single sample at the given level
+// on both channels, and silence afterwards.
+type ImpulseSource struct {
+	level float32
+	position int64
+}
+
+func NewImpulseSource(level float32) *ImpulseSource {
+	return &ImpulseSource{ level: level }
+}
+
+func (self *ImpulseSource) WriteStereo(buffer []float32) (int, error) {
+	if len(buffer) & 0b01 != 0 { buffer = buffer[0 : len(buffer) - 1] }
+	for i := 0; i < len(buffer); i++ { buffer[i] = 0 }
+	if self.position == 0 && len(buffer) > 0 {
+		buffer[0], buffer[1] = self.level, self.level
+	}
+	self.position += int64(len(buffer) >> 1)
+	return len(buffer) >> 1, nil
+}
+
+func (self *ImpulseSource) SeekSample(n int) error {
+	self.position = int64(n)
+	return nil
+}
+
+func (self *ImpulseSource) GetPosition() int64 {
+	return self.position
+}
+
+var _ audio.StereoProcessor = (*LimitedSource)(nil)
+
+// Wraps a source so it ends (io.EOF) after the given number of samples.
+type LimitedSource struct {
+	source audio.StereoProcessor
+	samplesLeft int
+}
+
+func NewLimitedSource(source audio.StereoProcessor, numSamples int) *LimitedSource {
+	return &LimitedSource{ source: source, samplesLeft: numSamples }
+}
+
+func (self *LimitedSource) WriteStereo(buffer []float32) (int, error) {
+	if len(buffer) > self.samplesLeft*2 { buffer = buffer[ : self.samplesLeft*2] }
+	if len(buffer) == 0 { return 0, io.EOF }
+	n, err := self.source.WriteStereo(buffer)
+	self.samplesLeft -= n
+	if err == nil && self.samplesLeft == 0 { err = io.EOF }
+	return n, err
+}
+
+func (self *LimitedSource) SeekSample(n int) error {
+	panic("LimitedSource can't seek")
+}
+
+func (self *LimitedSource) GetPosition() int64 {
+	return self.source.GetPosition()
+}
+
+// --- envelopes ---
+
+// Peak levels of a rendered signal over consecutive windows.
+type Envelope struct {
+	window int // in samples
+	levels []float32
+}
+
+// Creates the envelope of the given interleaved stereo samples.
+func NewEnvelope(samples []float32, channel Channel, window time.Duration) *Envelope {
+	windowSamples := durationToSamples(window)
+	if windowSamples <= 0 { panic("window too small") }
+	numSamples := len(samples) >> 1
+	envelope := &Envelope{ window: windowSamples }
+	for start := 0; start < numSamples; start += windowSamples {
+		end := min(start + windowSamples, numSamples)
+		var peak float32
+		for i := start; i < end; i++ {
+			peak = max(peak, channelLevel(samples, i, channel))
+		}
+		envelope.levels = append(envelope.levels, peak)
+	}
+	return envelope
+}
+
+func (self *Envelope) Duration() time.Duration {
+	return samplesToDuration(len(self.levels)*self.window)
+}
+
+// Returns the level of the window containing the given time.
+func (self *Envelope) At(t time.Duration) float32 {
+	index := durationToSamples(t)/self.window
+	if index < 0 || index >= len(self.levels) { panic("time out of envelope range") }
+	return self.levels[index]
+}
+
+// Returns the earliest time after which the level stays within
+// tolerance of target until the end. Returns false if the level
+// isn't even settled at the end.
+func (self *Envelope) SettledAt(target, tolerance float32) (time.Duration, bool) {
+	index := len(self.levels)
+	for index > 0 && abs(self.levels[index - 1] - target) <= tolerance {
+		index -= 1
+	}
+	if index == len(self.levels) { return 0, false }
+	return samplesToDuration(index*self.window), true
+}
+
+// --- assertions ---
+
+// Returns the max absolute value in the samples.
+func Peak(samples []float32) float32 {
+	var peak float32
+	for _, value := range samples {
+		peak = max(peak, abs(value))
+	}
+	return peak
+}
+
+func AssertPeakBelow(t testing.TB, samples []float32, limit float32) {
+	t.Helper()
+	peak := Peak(samples)
+	if peak >= limit {
+		t.Fatalf("peak %s not below %s", fmtLevel(peak), fmtLevel(limit))
+	}
+}
+
+func AssertLevelAt(t testing.TB, envelope *Envelope, at time.Duration, expected, tolerance float32) {
+	t.Helper()
+	level := envelope.At(at)
+	if abs(level - expected) > tolerance {
+		t.Fatalf("level at %s is %s, expected %s", at.String(), fmtLevel(level), fmtLevel(expected))
+	}
+}
+
+// Asserts that the level reaches target by the given time and stays
+// there until the end. Catches fades that never finish.
+func AssertSettledBy(t testing.TB, envelope *Envelope, target float32, by time.Duration, tolerance float32) {
+	t.Helper()
+	at, settled := envelope.SettledAt(target, tolerance)
+	if !settled {
+		t.Fatalf("level never settled at %s (ends at %s)", fmtLevel(target), fmtLevel(envelope.levels[len(envelope.levels) - 1]))
+	}
+	if at > by + samplesToDuration(envelope.window) {
+		t.Fatalf("level settled at %s at %s, expected by %s", fmtLevel(target), at.String(), by.String())
+	}
+}
+
+// Asserts that the level goes linearly from one value to another
+// between the given start and end times.
+func AssertLinearFade(t testing.TB, envelope *Envelope, start, end time.Duration, from, to, tolerance float32) {
+	t.Helper()
+	if end <= start { panic("end <= start") }
+	windowDuration := samplesToDuration(envelope.window)
+	for at := start + windowDuration; at < end - windowDuration; at += windowDuration {
+		progress := float32(at - start)/float32(end - start)
+		expected := from + (to - from)*progress
+		level := envelope.At(at)
+		if abs(level - expected) > tolerance {
+			t.Fatalf("fade level at %s is %s, expected %s", at.String(), fmtLevel(level), fmtLevel(expected))
+		}
+	}
+}
+
+// Asserts the timing of a crossfade rendered with the outgoing
+// source on the left channel and the incoming one on the right.
+// The outgoing source must be silent by outEnd, the incoming one
+// must stay silent until inStart and reach inLevel by inEnd.
+func AssertCrossfade(t testing.TB, samples []float32, outEnd, inStart, inEnd time.Duration, inLevel, tolerance float32) {
+	t.Helper()
+	const Window = time.Millisecond*10
+	outgoing := NewEnvelope(samples, Left, Window)
+	incoming := NewEnvelope(samples, Right, Window)
+	AssertSettledBy(t, outgoing, 0, outEnd, tolerance)
+	for at := time.Duration(0); at < inStart - Window; at += Window {
+		AssertLevelAt(t, incoming, at, 0, tolerance)
+	}
+	AssertSettledBy(t, incoming, inLevel, inEnd, tolerance)
+}
+
+// --- helpers ---
+
+func channelLevel(samples []float32, index int, channel Channel) float32 {
+	switch channel {
+	case Left  : return abs(samples[index*2 + 0])
+	case Right : return abs(samples[index*2 + 1])
+	case Both  : return max(abs(samples[index*2 + 0]), abs(samples[index*2 + 1]))
+	default:
+		panic(channel)
+	}
+}
+
+func abs(value float32) float32 {
+	if value >= 0 { return value }
+	return -value
+}
+
+func min(a, b int) int {
+	if a <= b { return a }
+	return b
+}
+
+func max(a, b float32) float32 {
+	if a >= b { return a }
+	return b
+}
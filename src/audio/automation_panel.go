@@ -2,17 +2,25 @@ package audio
 
 type TriggerKey  uint8
 type ResourceKey uint8
+type ParamKey    uint8
 
 // Most loosely typed mess ever. Very practical.
 type AutomationPanel struct {
 	triggers []func() error
 	resources []any
+	params []automationParam
+}
+
+type automationParam struct {
+	setter func(float32)
+	value float32
 }
 
 func NewAutomationPanel() *AutomationPanel {
 	return &AutomationPanel{
 		triggers: make([]func() error, 0, 4),
 		resources: make([]any, 0, 4),
+		params: make([]automationParam, 0, 4),
 	}
 }
 
@@ -36,4 +44,25 @@ func (self *AutomationPanel) GetResource(key ResourceKey) any {
 	return self.resources[key]
 }
 
+// Registers a parameter, typically driving some effect (e.g. a filter
+// cutoff). The setter is only called when the value changes, so params
+// can be set every tick. The initial value is applied immediately.
+func (self *AutomationPanel) RegisterParam(initialValue float32, setter func(float32)) ParamKey {
+	key := ParamKey(len(self.params))
+	self.params = append(self.params, automationParam{ setter: setter, value: initialValue })
+	setter(initialValue)
+	return key
+}
+
+func (self *AutomationPanel) SetParam(key ParamKey, value float32) {
+	param := &self.params[key]
+	if param.value == value { return }
+	param.value = value
+	param.setter(value)
+}
+
+func (self *AutomationPanel) GetParam(key ParamKey) float32 {
+	return self.params[key].value
+}
+
 
@@ -1,92 +1,97 @@
 package audio
 
-import "io"
 import "time"
 
-import "github.com/hajimehoshi/ebiten/v2/audio"
 import "github.com/tinne26/edau"
 
+// Background music. BGMs are added to the music bus while
+// playing, and removed once fully faded out.
 type BGM struct {
-	source *ProcessorOutAdapterL16[*Fader]
-	player *audio.Player // stays as nil most of the time while not in use
+	fader *Fader
+	source *Gain // volume corrector over the fader
+	bus *Bus // set when registered on a soundscape
+	stems *StemMusic // nil unless created with NewBgmFromStems()
+	tempo Tempo
+	hasTempo bool
+	playing bool
 }
 
 func NewBgmFromLooper(loop *edau.Looper) *BGM {
-	return &BGM{
-		source: NewProcessorOutAdapterL16[*Fader](NewFader(NewProcessorInAdapterL16(loop))),
-	}
+	return NewBgmFromFader(NewFader(NewProcessorInAdapterL16(loop)))
 }
 
 func NewBgmFromFader(fader *Fader) *BGM {
-	return &BGM{
-		source: NewProcessorOutAdapterL16[*Fader](fader),
-	}
+	return &BGM{ fader: fader, source: NewGain(fader, 1.0) }
+}
+
+func NewBgmFromStems(stems *StemMusic) *BGM {
+	bgm := NewBgmFromFader(NewFader(stems))
+	bgm.stems = stems
+	return bgm
+}
+
+// Returns the stems of the BGM, or nil if it's not stem-based.
+func (self *BGM) Stems() *StemMusic {
+	return self.stems
 }
 
 func (self *BGM) SetVolumeCorrectorFactor(factor float32) {
-	self.source.SetVolumeCorrectorFactor(factor)
+	if factor < 0 { panic("factor < 0") }
+	if factor > 1 { panic("factor > 1") }
+	self.source.SetGain(factor)
 }
 
-func (self *BGM) SetUserVolume(volume float32) {
-	self.source.SetUserVolume(volume)
+// Sets the tempo metadata of the BGM, used by the
+// soundscape's MusicClock while the BGM is active.
+func (self *BGM) SetTempo(tempo Tempo) {
+	tempo.validate()
+	self.tempo = tempo
+	self.hasTempo = true
+}
+
+// Returns the tempo of the BGM, if any.
+func (self *BGM) Tempo() (Tempo, bool) {
+	return self.tempo, self.hasTempo
 }
 
 func (self *BGM) SetPosition(position time.Duration) error {
-	if self.player != nil {
-		return self.player.Seek(position)
-	} else {
-		_, err := self.source.Seek(TimeDurationToSamples(position)*4, io.SeekStart)
-		return err
-	}
+	return self.source.SeekSample(int(TimeDurationToSamples(position)))
 }
 
 func (self *BGM) Rewind() error {
-	if self.player != nil {
-		return self.player.Rewind()
-	} else {
-		_, err := self.source.Seek(0, io.SeekStart)
-		return err
-	}
+	return self.source.SeekSample(0)
 }
 
-func (self *BGM) Pause() {
-	if self.player != nil {
-		self.player.Pause()
-	}
+func (self *BGM) IsPlaying() bool {
+	return self.playing
 }
 
-func (self *BGM) DeletePlayer() error {
-	if self.player != nil {
-		err := self.player.Close()
-		self.player = nil
-		if err != nil { return err }
-	}
-	return nil
+// Stops sending the BGM to the music bus. The position is kept.
+func (self *BGM) Pause() {
+	if !self.playing { return }
+	self.bus.Remove(self.source)
+	self.playing = false
 }
 
-func (self *BGM) Play() error {
-	if self.player == nil {
-		var err error
-		self.player, err = audio.CurrentContext().NewPlayer(self.source)
-		if err != nil { return err }
-		self.player.SetBufferSize(time.Millisecond*66)
-	}
-	self.player.Play()
-	return nil
+func (self *BGM) Play() {
+	if self.playing { return }
+	if self.bus == nil { panic("BGM must be registered before playing") }
+	self.bus.Add(self.source)
+	self.playing = true
 }
 
 func (self *BGM) FadeIn(startWait, fadeDuration time.Duration) {
 	waitSamples := TimeDurationToSamples(startWait)
 	fadeSamples := TimeDurationToSamples(fadeDuration)
-	self.source.source.Transition(1.0, waitSamples, fadeSamples)
+	self.fader.Transition(1.0, waitSamples, fadeSamples)
 	self.Play()
 }
 
 func (self *BGM) FadeOut(fadeDuration time.Duration) {
 	fadeSamples := TimeDurationToSamples(fadeDuration)
-	self.source.source.Transition(0.0, 0, fadeSamples)
+	self.fader.Transition(0.0, 0, fadeSamples)
 }
 
 func (self *BGM) FullyFadedOut() bool {
-	return self.source.source.FullyFadedOut()
+	return self.fader.FullyFadedOut()
 }
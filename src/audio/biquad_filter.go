@@ -0,0 +1,170 @@
+package audio
+
+import "sync"
+import "math"
+import "strconv"
+
+var _ StereoProcessor = (*BiquadFilter)(nil)
+
+type FilterType uint8
+const (
+	FilterLowPass FilterType = iota
+	FilterHighPass
+)
+
+func (self FilterType) String() string {
+	switch self {
+	case FilterLowPass: return "low-pass"
+	case FilterHighPass: return "high-pass"
+	default:
+		return "FilterType#" + strconv.Itoa(int(self))
+	}
+}
+
+// Cutoff limits. Low-pass filters at the max cutoff and high-pass
+// filters at the min cutoff are bypassed.
+const (
+	FilterMinCutoff = 20.0
+	FilterMaxCutoff = 20000.0
+)
+
+// Cutoff automation is smoothed exponentially (in the log domain)
+// with this time constant, and coefficients are recomputed every
+// few samples while the cutoff is moving.
+const filterSmoothingSamples = 44100*30/1000 // 30ms
+const filterRecomputeSamples = 16
+
+// A biquad low-pass or high-pass filter (RBJ cookbook formulas).
+// Cutoff changes are smoothed, so they can be automated freely.
+type BiquadFilter struct {
+	mutex sync.Mutex
+	source StereoProcessor
+	filterType FilterType
+	q float64
+	logCutoff float64
+	logCutoffTarget float64
+	b0, b1, b2, a1, a2 float64
+	state [2][4]float64 // x1, x2, y1, y2 for each channel
+	recomputeIn int
+}
+
+func NewBiquadFilter(source StereoProcessor, filterType FilterType, cutoff float32) *BiquadFilter {
+	filter := &BiquadFilter{
+		source: source,
+		filterType: filterType,
+		q: math.Sqrt2/2.0,
+	}
+	filter.logCutoffTarget = math.Log(clampCutoff(cutoff))
+	filter.logCutoff = filter.logCutoffTarget
+	filter.computeCoefficients()
+	return filter
+}
+
+// Sets the cutoff frequency in Hz. The change is smoothed.
+func (self *BiquadFilter) SetCutoff(cutoff float32) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.logCutoffTarget = math.Log(clampCutoff(cutoff))
+}
+
+// Returns the target cutoff frequency in Hz.
+func (self *BiquadFilter) GetCutoff() float32 {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	return float32(math.Exp(self.logCutoffTarget))
+}
+
+// Sets the resonance of the filter. The default is 1/sqrt(2).
+func (self *BiquadFilter) SetQ(q float32) {
+	if q <= 0 { panic("q <= 0") }
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.q = float64(q)
+	self.computeCoefficients()
+}
+
+func (self *BiquadFilter) WriteStereo(buffer []float32) (int, error) {
+	n, err := self.source.WriteStereo(buffer)
+
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if self.isBypassed() {
+		self.state = [2][4]float64{}
+		return n, err
+	}
+
+	for i := 0; i < n << 1; i += 2 {
+		if self.logCutoff != self.logCutoffTarget {
+			if self.recomputeIn <= 0 {
+				self.stepCutoff(filterRecomputeSamples)
+				self.computeCoefficients()
+				self.recomputeIn = filterRecomputeSamples
+			}
+			self.recomputeIn -= 1
+		}
+		buffer[i + 0] = self.process(&self.state[0], buffer[i + 0])
+		buffer[i + 1] = self.process(&self.state[1], buffer[i + 1])
+	}
+	return n, err
+}
+
+func (self *BiquadFilter) SeekSample(n int) error {
+	return self.source.SeekSample(n)
+}
+
+func (self *BiquadFilter) GetPosition() int64 {
+	return self.source.GetPosition()
+}
+
+// --- helpers ---
+
+func (self *BiquadFilter) isBypassed() bool {
+	if self.logCutoff != self.logCutoffTarget { return false }
+	switch self.filterType {
+	case FilterLowPass  : return self.logCutoff >= math.Log(FilterMaxCutoff)
+	case FilterHighPass : return self.logCutoff <= math.Log(FilterMinCutoff)
+	default:
+		panic(self.filterType)
+	}
+}
+
+func (self *BiquadFilter) stepCutoff(samples int) {
+	remaining := math.Pow(1.0 - 1.0/filterSmoothingSamples, float64(samples))
+	self.logCutoff = self.logCutoffTarget + (self.logCutoff - self.logCutoffTarget)*remaining
+	if math.Abs(self.logCutoff - self.logCutoffTarget) < 0.001 {
+		self.logCutoff = self.logCutoffTarget
+	}
+}
+
+func (self *BiquadFilter) computeCoefficients() {
+	w0 := 2.0*math.Pi*math.Exp(self.logCutoff)/44100.0
+	cos, sin := math.Cos(w0), math.Sin(w0)
+	alpha := sin/(2.0*self.q)
+	a0 := 1.0 + alpha
+	switch self.filterType {
+	case FilterLowPass:
+		self.b0 = ((1.0 - cos)/2.0)/a0
+		self.b1 = (1.0 - cos)/a0
+		self.b2 = self.b0
+	case FilterHighPass:
+		self.b0 = ((1.0 + cos)/2.0)/a0
+		self.b1 = -(1.0 + cos)/a0
+		self.b2 = self.b0
+	default:
+		panic(self.filterType)
+	}
+	self.a1 = (-2.0*cos)/a0
+	self.a2 = (1.0 - alpha)/a0
+}
+
+func (self *BiquadFilter) process(state *[4]float64, sample float32) float32 {
+	x := float64(sample)
+	y := self.b0*x + self.b1*state[0] + self.b2*state[1] - self.a1*state[2] - self.a2*state[3]
+	state[1], state[0] = state[0], x
+	state[3], state[2] = state[2], y
+	return float32(y)
+}
+
+func clampCutoff(cutoff float32) float64 {
+	return math.Max(FilterMinCutoff, math.Min(float64(cutoff), FilterMaxCutoff))
+}
@@ -0,0 +1,75 @@
+package audio_test
+
+import "time"
+import "testing"
+
+import "github.com/tinne26/transition/src/audio"
+import "github.com/tinne26/transition/src/audio/audiotest"
+
+func TestBiquadLowPass(t *testing.T) {
+	numSamples := int(audio.TimeDurationToSamples(time.Millisecond*200))
+	filter := audio.NewBiquadFilter(audiotest.NewSineSource(10000, 0.8), audio.FilterLowPass, 500)
+	samples, err := audio.Render(filter, numSamples)
+	if err != nil { t.Fatal(err) }
+	envelope := audiotest.NewEnvelope(samples, audiotest.Both, testWindow)
+	audiotest.AssertSettledBy(t, envelope, 0, testWindow, 0.01)
+
+	// low frequencies go through
+	filter = audio.NewBiquadFilter(audiotest.NewSineSource(50, 0.8), audio.FilterLowPass, 500)
+	samples, err = audio.Render(filter, numSamples)
+	if err != nil { t.Fatal(err) }
+	envelope = audiotest.NewEnvelope(samples, audiotest.Both, time.Millisecond*20)
+	audiotest.AssertSettledBy(t, envelope, 0.8, time.Millisecond*40, 0.01)
+}
+
+func TestBiquadBypass(t *testing.T) {
+	numSamples := int(audio.TimeDurationToSamples(time.Millisecond*100))
+	expected, err := audio.Render(audiotest.NewSineSource(10000, 0.8), numSamples)
+	if err != nil { t.Fatal(err) }
+	filter := audio.NewBiquadFilter(audiotest.NewSineSource(10000, 0.8), audio.FilterLowPass, audio.FilterMaxCutoff)
+	samples, err := audio.Render(filter, numSamples)
+	if err != nil { t.Fatal(err) }
+	assertSameSamples(t, samples, expected)
+}
+
+func TestBiquadCutoffRamp(t *testing.T) {
+	source := audiotest.NewSineSource(10000, 0.8)
+	filter := audio.NewBiquadFilter(source, audio.FilterLowPass, audio.FilterMaxCutoff)
+
+	// the cutoff change is smoothed, but must settle
+	filter.SetCutoff(500)
+	samples, err := audio.Render(filter, int(audio.TimeDurationToSamples(time.Millisecond*300)))
+	if err != nil { t.Fatal(err) }
+	envelope := audiotest.NewEnvelope(samples, audiotest.Both, testWindow)
+	if envelope.At(0) < 0.5 { t.Fatalf("level at 0 is %f, the cutoff change wasn't smoothed", envelope.At(0)) }
+	audiotest.AssertSettledBy(t, envelope, 0, time.Millisecond*100, 0.01)
+
+	// going back to the max cutoff bypasses the filter again
+	filter.SetCutoff(audio.FilterMaxCutoff)
+	samples, err = audio.Render(filter, int(audio.TimeDurationToSamples(time.Millisecond*300)))
+	if err != nil { t.Fatal(err) }
+	envelope = audiotest.NewEnvelope(samples, audiotest.Both, testWindow)
+	audiotest.AssertSettledBy(t, envelope, 0.8, time.Millisecond*100, 0.01)
+
+	reference := audiotest.NewSineSource(10000, 0.8)
+	err = reference.SeekSample(int(source.GetPosition()))
+	if err != nil { t.Fatal(err) }
+	numSamples := int(audio.TimeDurationToSamples(time.Millisecond*100))
+	expected, err := audio.Render(reference, numSamples)
+	if err != nil { t.Fatal(err) }
+	samples, err = audio.Render(filter, numSamples)
+	if err != nil { t.Fatal(err) }
+	assertSameSamples(t, samples, expected)
+}
+
+func assertSameSamples(t *testing.T, samples, expected []float32) {
+	t.Helper()
+	if len(samples) != len(expected) {
+		t.Fatalf("got %d samples, expected %d", len(samples), len(expected))
+	}
+	for i, _ := range samples {
+		if samples[i] != expected[i] {
+			t.Fatalf("sample %d is %f, expected %f", i >> 1, samples[i], expected[i])
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package audio
+
+import "sync"
+import "strconv"
+
+type BusKey uint8
+const (
+	BusMaster BusKey = iota
+	BusMusic
+	BusSFX
+	BusUI
+	BusAmbience
+
+	busEndSentinel
+)
+
+const NumBuses = int(busEndSentinel)
+
+func (self BusKey) String() string {
+	switch self {
+	case BusMaster: return "master"
+	case BusMusic: return "music"
+	case BusSFX: return "sfx"
+	case BusUI: return "ui"
+	case BusAmbience: return "ambience"
+	default:
+		return "BusKey#" + strconv.Itoa(int(self))
+	}
+}
+
+var _ StereoProcessor = (*Bus)(nil)
+
+// Ducking ramps, in samples per full gain unit.
+const duckAttackSamples  = 44100*30/1000  // 30ms
+const duckReleaseSamples = 44100*450/1000 // 450ms
+
+// A bus mixes a changing set of sources with an Adder and applies its
+// own gain on top. Sources that end (io.EOF) are removed automatically,
+// and the bus itself never ends: silence is written when there are no
+// sources. Buses can also be ducked (see Mixer).
+type Bus struct {
+	adder *Adder
+	gain *Gain
+
+	mutex sync.Mutex
+	duckCurrent float32 // gain multiplier, 1 when not ducked
+	duckTarget float32
+}
+
+func NewBus(gain float32) *Bus {
+	adder := NewAdder()
+	adder.SetRemoveOnEOF(true)
+	return &Bus{
+		adder: adder,
+		gain: NewGain(adder, gain),
+		duckCurrent: 1.0,
+		duckTarget: 1.0,
+	}
+}
+
+func (self *Bus) Add(source StereoProcessor) { self.adder.Add(source) }
+func (self *Bus) Remove(source StereoProcessor) bool { return self.adder.Remove(source) }
+func (self *Bus) NumSources() int { return self.adder.Len() }
+
+func (self *Bus) SetGain(gain float32) { self.gain.SetGain(gain) }
+func (self *Bus) GetGain() float32 { return self.gain.GetGain() }
+
+// Sets how much the bus must be attenuated, between 0 (no ducking)
+// and 1 (full silence). Changes are ramped in and out.
+func (self *Bus) setDucking(amount float32) {
+	if amount < 0 || amount > 1 { panic("ducking amount must be in [0, 1]") }
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.duckTarget = 1.0 - amount
+}
+
+func (self *Bus) WriteStereo(buffer []float32) (int, error) {
+	// do not allow odd-sized buffers
+	if len(buffer) & 0b01 != 0 { buffer = buffer[0 : len(buffer) - 1] }
+	_, err := self.gain.WriteStereo(buffer) // the adder fills the whole buffer
+
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if self.duckCurrent != 1.0 || self.duckTarget != 1.0 {
+		for i := 0; i < len(buffer); i += 2 {
+			if self.duckCurrent > self.duckTarget {
+				self.duckCurrent -= 1.0/duckAttackSamples
+				if self.duckCurrent < self.duckTarget { self.duckCurrent = self.duckTarget }
+			} else if self.duckCurrent < self.duckTarget {
+				self.duckCurrent += 1.0/duckReleaseSamples
+				if self.duckCurrent > self.duckTarget { self.duckCurrent = self.duckTarget }
+			}
+			buffer[i + 0] *= self.duckCurrent
+			buffer[i + 1] *= self.duckCurrent
+		}
+	}
+
+	return len(buffer) >> 1, err
+}
+
+// Buses are live streams, so seeking doesn't apply.
+func (self *Bus) SeekSample(n int) error { return nil }
+
+func (self *Bus) GetPosition() int64 { return 0 }
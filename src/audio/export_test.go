@@ -0,0 +1,7 @@
+package audio
+
+// Accessors for the external audio_test package.
+
+func NumFreeVoices(pool *VoicePool) int {
+	return len(pool.free)
+}
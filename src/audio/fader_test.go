@@ -0,0 +1,44 @@
+package audio_test
+
+import "time"
+import "testing"
+
+import "github.com/tinne26/transition/src/audio"
+import "github.com/tinne26/transition/src/audio/audiotest"
+
+const testWindow = time.Millisecond*10
+
+func TestFaderTransitions(t *testing.T) {
+	fader := audio.NewFader(audiotest.NewConstSource(1, 1))
+
+	// fade in after a wait
+	wait, fade := time.Millisecond*100, time.Millisecond*400
+	fader.Transition(1.0, audio.TimeDurationToSamples(wait), audio.TimeDurationToSamples(fade))
+	samples, err := audio.Render(fader, int(audio.TimeDurationToSamples(time.Second)))
+	if err != nil { t.Fatal(err) }
+	envelope := audiotest.NewEnvelope(samples, audiotest.Both, testWindow)
+	for at := time.Duration(0); at < wait - testWindow; at += testWindow {
+		audiotest.AssertLevelAt(t, envelope, at, 0, 0.001)
+	}
+	audiotest.AssertLinearFade(t, envelope, wait, wait + fade, 0, 1, 0.03)
+	audiotest.AssertSettledBy(t, envelope, 1, wait + fade, 0.001)
+
+	// partial fade out, without wait
+	fade = time.Millisecond*200
+	fader.Transition(0.25, 0, audio.TimeDurationToSamples(fade))
+	samples, err = audio.Render(fader, int(audio.TimeDurationToSamples(time.Millisecond*500)))
+	if err != nil { t.Fatal(err) }
+	envelope = audiotest.NewEnvelope(samples, audiotest.Both, testWindow)
+	audiotest.AssertLinearFade(t, envelope, 0, fade, 1, 0.25, 0.03)
+	audiotest.AssertSettledBy(t, envelope, 0.25, fade, 0.001)
+	if fader.FullyFadedOut() { t.Fatal("fader reported as fully faded out at 0.25") }
+
+	// full fade out
+	fader.Transition(0, 0, audio.TimeDurationToSamples(fade))
+	samples, err = audio.Render(fader, int(audio.TimeDurationToSamples(time.Millisecond*500)))
+	if err != nil { t.Fatal(err) }
+	envelope = audiotest.NewEnvelope(samples, audiotest.Both, testWindow)
+	audiotest.AssertLinearFade(t, envelope, 0, fade, 0.25, 0, 0.03)
+	audiotest.AssertSettledBy(t, envelope, 0, fade, 0.001)
+	if !fader.FullyFadedOut() { t.Fatal("fader not fully faded out") }
+}
@@ -0,0 +1,81 @@
+package audio
+
+import "sync"
+
+var _ StereoProcessor = (*Gain)(nil)
+
+// Number of samples used to smooth gain changes and avoid clicks.
+const gainRampSamples = 441 // 10ms at 44100Hz
+
+// Applies a gain to the source. Gain changes are ramped in
+// over a few milliseconds instead of applied instantly.
+type Gain struct {
+	mutex sync.Mutex
+	source StereoProcessor
+	current float32
+	target float32
+}
+
+func NewGain(source StereoProcessor, gain float32) *Gain {
+	if gain < 0 { panic("gain < 0") }
+	return &Gain{ source: source, current: gain, target: gain }
+}
+
+func (self *Gain) SetGain(gain float32) {
+	if gain < 0 { panic("gain < 0") }
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.target = gain
+}
+
+func (self *Gain) GetGain() float32 {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	return self.target
+}
+
+func (self *Gain) WriteStereo(buffer []float32) (int, error) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	n, err := self.source.WriteStereo(buffer)
+	self.current = applyGainRamp(buffer[ : n << 1], self.current, self.target)
+	return n, err
+}
+
+func (self *Gain) SeekSample(n int) error {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	return self.source.SeekSample(n)
+}
+
+func (self *Gain) GetPosition() int64 {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	return self.source.GetPosition()
+}
+
+// Multiplies the interleaved stereo buffer by a gain that goes
+// from current towards target, and returns the final gain.
+func applyGainRamp(buffer []float32, current, target float32) float32 {
+	const step = 1.0/gainRampSamples
+	if current == target {
+		if current == 1.0 { return current }
+		for i := 0; i < len(buffer); i++ {
+			buffer[i] *= current
+		}
+		return current
+	}
+
+	for i := 0; i < len(buffer); i += 2 {
+		if current < target {
+			current += step
+			if current > target { current = target }
+		} else if current > target {
+			current -= step
+			if current < target { current = target }
+		}
+		buffer[i + 0] *= current
+		buffer[i + 1] *= current
+	}
+	return current
+}
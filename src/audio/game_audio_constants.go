@@ -10,6 +10,7 @@ var (
 	SfxSwordTap SfxKey = 0
 	SfxFuss     SfxKey = 0
 	SfxObtain   SfxKey = 0
+	SfxBlip     SfxKey = 0 // dialogue text reveal
 )
 
 var (
@@ -17,6 +18,14 @@ var (
 	BgmChallenge  BgmKey = 0
 )
 
+// Effect parameters registered on the soundscape's automation panel.
 var (
-	ResKeyChallengeFader ResourceKey = 0
+	ParamKeyMuffle    ParamKey = 0 // 0 (clear) to 1 (heavily muffled)
+	ParamKeyReverb    ParamKey = 0 // 0 (dry) to 1 (very wet)
+	ParamKeyMusicRate ParamKey = 0 // music playback rate, 1 by default
+)
+
+// Music parameters for stem-based BGMs.
+const (
+	MusicParamPower = "power" // sword challenge power absorption, 0 to 1
 )
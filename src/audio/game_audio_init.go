@@ -1,5 +1,6 @@
 package audio
 
+import "math"
 import "time"
 import "io/fs"
 
@@ -19,7 +20,7 @@ func Initialize(soundscape *Soundscape, filesys fs.FS) error {
 	sfx, err = loadWavMultiSFX(filesys, "assets/audio/sfx/step*.wav", '1', '3')
 	if err != nil { return err }
 	sfx.SetVolumeCorrectorFactor(0.52)
-	sfx.SetBackoff(time.Millisecond*85)
+	sfx.SetBackoff(5) // ~85ms
 	SfxStep = soundscape.RegisterSFX(sfx)
 
 	sfx, err = loadWavSoundEffect(filesys, "assets/audio/sfx/wings.wav")
@@ -30,11 +31,13 @@ func Initialize(soundscape *Soundscape, filesys fs.FS) error {
 	sfx, err = loadWavSoundEffect(filesys, "assets/audio/sfx/death.wav")
 	if err != nil { return err }
 	sfx.SetVolumeCorrectorFactor(0.85)
+	sfx.SetDucking(0.6)
 	SfxDeath = soundscape.RegisterSFX(sfx)
 
 	sfx, err = loadWavSoundEffect(filesys, "assets/audio/sfx/interact.wav")
 	if err != nil { return err }
 	sfx.SetVolumeCorrectorFactor(0.54)
+	sfx.SetBus(BusUI)
 	SfxInteract = soundscape.RegisterSFX(sfx)
 
 	sfx, err = loadWavSoundEffect(filesys, "assets/audio/sfx/reverse.wav")
@@ -45,11 +48,13 @@ func Initialize(soundscape *Soundscape, filesys fs.FS) error {
 	sfx, err = loadWavSoundEffect(filesys, "assets/audio/sfx/sword_end.wav")
 	if err != nil { return err }
 	sfx.SetVolumeCorrectorFactor(0.64)
+	sfx.SetDucking(0.4)
 	SfxSwordEnd = soundscape.RegisterSFX(sfx)
 	
 	sfx, err = loadOggMultiSFX(filesys, "assets/audio/sfx/sword_tap*.ogg", '1', '4')
 	if err != nil { return err }
 	sfx.SetVolumeCorrectorFactor(0.37)
+	sfx.SetDucking(0.3)
 	SfxSwordTap = soundscape.RegisterSFX(sfx)
 
 	sfx, err = loadOggSoundEffect(filesys, "assets/audio/sfx/fuss.ogg")
@@ -62,25 +67,51 @@ func Initialize(soundscape *Soundscape, filesys fs.FS) error {
 	sfx.SetVolumeCorrectorFactor(0.4)
 	SfxObtain = soundscape.RegisterSFX(sfx)
 
+	sfx, err = loadWavSoundEffect(filesys, "assets/audio/sfx/blip.wav")
+	if err != nil { return err }
+	sfx.SetVolumeCorrectorFactor(0.3)
+	sfx.SetBackoff(3)
+	sfx.SetBus(BusUI)
+	SfxBlip = soundscape.RegisterSFX(sfx)
+
 	// load and set up bgms
 	var loop1, loop2 *edau.Looper
 	loop1, err = loadLooper(filesys, "assets/audio/bgm/background.ogg", 130, 5499197)
 	if err != nil { return err }
 	bgm := NewBgmFromLooper(loop1)
 	bgm.SetVolumeCorrectorFactor(0.5)
+	bgm.SetTempo(Tempo{ BPM: 128, BeatsPerBar: 4, Offset: time.Millisecond*15 })
 	BgmBackground = soundscape.RegisterBGM(bgm)
 
 	loop1, err = loadLooper(filesys, "assets/audio/bgm/challenge_base.ogg", 75938, 1942845)
 	if err != nil { return err }
 	loop2, err = loadLooper(filesys, "assets/audio/bgm/challenge_aux.ogg", 75938, 1942845)
 	if err != nil { return err }
-	auxFader := NewFader(NewProcessorInAdapterL16(loop2))
-	bgm = NewBgmFromFader(NewFader(NewAdder(NewProcessorInAdapterL16(loop1), auxFader)))
+	stems := NewStemMusic()
+	stems.AddBaseLayer(loop1)
+	stems.AddLayer(loop2, MusicParamPower, 0, 1)
+	bgm = NewBgmFromStems(stems)
 	bgm.SetVolumeCorrectorFactor(0.5)
+	bgm.SetTempo(Tempo{ BPM: 136, BeatsPerBar: 4, Offset: time.Millisecond*10 })
 	BgmChallenge = soundscape.RegisterBGM(bgm)
-	
-	// put aux fader on soundscape's automation panel
-	ResKeyChallengeFader = soundscape.AutomationPanel().StoreResource(auxFader)
 
-	return nil
+	// effect params
+	panel, mixer := soundscape.AutomationPanel(), soundscape.Mixer()
+	ParamKeyMuffle = panel.RegisterParam(0, func(amount float32) {
+		// exponential mapping so the amount feels linear
+		cutoff := FilterMaxCutoff*math.Pow(muffleMinCutoff/FilterMaxCutoff, float64(amount))
+		mixer.Muffle().SetCutoff(float32(cutoff))
+	})
+	ParamKeyReverb = panel.RegisterParam(0, func(amount float32) {
+		mixer.Reverb().SetRoomSize(0.6 + amount*0.3)
+		mixer.Reverb().SetWet(amount*0.5)
+	})
+	ParamKeyMusicRate = panel.RegisterParam(1, func(rate float32) {
+		mixer.MusicPitch().SetRate(rate)
+	})
+
+	return soundscape.start()
 }
+
+// Low-pass cutoff for a fully muffled soundscape.
+const muffleMinCutoff = 420.0
@@ -0,0 +1,64 @@
+package audio
+
+import "math"
+
+// Soft limiter meant for the master bus. Peaks above the threshold
+// are attenuated with an instant attack and a slow release, and
+// anything that still goes past the knee is softly saturated towards
+// 1.0 instead of hard clipped.
+type Limiter struct {
+	threshold float32
+	knee float32
+	gain float32
+	releaseStep float32
+}
+
+func NewLimiter(threshold float32) *Limiter {
+	if threshold <= 0 || threshold > 1 { panic("limiter threshold must be in (0, 1]") }
+	return &Limiter{
+		threshold: threshold,
+		knee: threshold*0.9,
+		gain: 1.0,
+		releaseStep: 1.0/(44100*0.25), // 250ms for a full recovery
+	}
+}
+
+// Returns the current gain reduction, as a multiplier.
+func (self *Limiter) CurrentGain() float32 {
+	return self.gain
+}
+
+// Processes the interleaved stereo buffer in place.
+func (self *Limiter) Process(buffer []float32) {
+	for i := 0; i + 1 < len(buffer); i += 2 {
+		left, right := buffer[i], buffer[i + 1]
+		peak := max32(abs32(left), abs32(right))
+		if peak*self.gain > self.threshold {
+			self.gain = self.threshold/peak
+		} else if self.gain < 1.0 {
+			self.gain += self.releaseStep
+			if self.gain > 1.0 { self.gain = 1.0 }
+		}
+		buffer[i + 0] = self.softClip(left*self.gain)
+		buffer[i + 1] = self.softClip(right*self.gain)
+	}
+}
+
+func (self *Limiter) softClip(value float32) float32 {
+	abs := abs32(value)
+	if abs <= self.knee { return value }
+	span := 1.0 - self.knee
+	clipped := self.knee + span*float32(math.Tanh(float64((abs - self.knee)/span)))
+	if value < 0 { return -clipped }
+	return clipped
+}
+
+func abs32(value float32) float32 {
+	if value < 0 { return -value }
+	return value
+}
+
+func max32(a, b float32) float32 {
+	if a >= b { return a }
+	return b
+}
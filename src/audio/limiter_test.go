@@ -0,0 +1,37 @@
+package audio_test
+
+import "time"
+import "testing"
+
+import "github.com/tinne26/transition/src/audio"
+import "github.com/tinne26/transition/src/audio/audiotest"
+
+func TestLimiterRelease(t *testing.T) {
+	limiter := audio.NewLimiter(0.8)
+	hot, err := audio.Render(audiotest.NewConstSource(1.5, -2.0), 4410)
+	if err != nil { t.Fatal(err) }
+	limiter.Process(hot)
+	audiotest.AssertPeakBelow(t, hot, 0.8)
+	if limiter.CurrentGain() >= 1.0 { t.Fatal("limiter didn't reduce the gain") }
+
+	// quiet signals recover the full level within the 250ms release
+	quiet, err := audio.Render(audiotest.NewConstSource(0.5, 0.5), int(audio.TimeDurationToSamples(time.Second)))
+	if err != nil { t.Fatal(err) }
+	limiter.Process(quiet)
+	envelope := audiotest.NewEnvelope(quiet, audiotest.Both, testWindow)
+	audiotest.AssertSettledBy(t, envelope, 0.5, time.Millisecond*250, 0.001)
+	if limiter.CurrentGain() != 1.0 { t.Fatalf("limiter gain at %f after release", limiter.CurrentGain()) }
+}
+
+func TestMixerLimiter(t *testing.T) {
+	// overlapping hot sources on different buses
+	mixer := audio.NewMixer()
+	mixer.Bus(audio.BusSFX).Add(audiotest.NewConstSource(0.9, -0.9))
+	mixer.Bus(audio.BusUI).Add(audiotest.NewConstSource(0.9, -0.9))
+	mixer.Bus(audio.BusMusic).Add(audiotest.NewConstSource(0.9, -0.9))
+	samples, err := audio.Render(mixer, int(audio.TimeDurationToSamples(time.Millisecond*500)))
+	if err != nil { t.Fatal(err) }
+	audiotest.AssertPeakBelow(t, samples, 1.0)
+	envelope := audiotest.NewEnvelope(samples, audiotest.Both, testWindow)
+	audiotest.AssertLevelAt(t, envelope, time.Millisecond*250, 0.9, 0.05)
+}
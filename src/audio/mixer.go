@@ -0,0 +1,115 @@
+package audio
+
+import "sync"
+
+var _ StereoProcessor = (*Mixer)(nil)
+
+// Threshold for the soft limiter on the master bus.
+const masterLimiterThreshold = 0.92
+
+// The mixer owns all the buses. The master bus adds the output of
+// all the other buses, and then a soft limiter is applied so many
+// overlapping sounds don't clip. Sound effects can also request the
+// music bus to be ducked while they play (see SfxPlayer.SetDucking()).
+//
+// The mixer also has a few effects for state-based audio: a pitch
+// shifter on the music bus, and a low-pass filter (muffle) and a
+// reverb on the master bus, before the limiter. All of them are
+// bypassed by default.
+type Mixer struct {
+	buses [NumBuses]*Bus
+	musicPitch *PitchShifter
+	muffle *BiquadFilter
+	reverb *Reverb
+	output StereoProcessor // master bus through the effects
+	limiter *Limiter
+
+	mutex sync.Mutex
+	duckers []mixerDucker
+	position int64
+}
+
+type mixerDucker struct {
+	voice *sfxVoice
+	generation uint32 // voices are reused, so we need to check this too
+	amount float32
+}
+
+func NewMixer() *Mixer {
+	mixer := &Mixer{ limiter: NewLimiter(masterLimiterThreshold) }
+	for i, _ := range mixer.buses {
+		mixer.buses[i] = NewBus(1.0)
+	}
+	master := mixer.buses[BusMaster]
+	mixer.musicPitch = NewPitchShifter(mixer.buses[BusMusic])
+	for key := BusKey(0); key < busEndSentinel; key++ {
+		switch key {
+		case BusMaster : // skip
+		case BusMusic  : master.Add(mixer.musicPitch)
+		default:
+			master.Add(mixer.buses[key])
+		}
+	}
+	mixer.muffle = NewBiquadFilter(master, FilterLowPass, FilterMaxCutoff)
+	mixer.reverb = NewReverb(mixer.muffle)
+	mixer.output = mixer.reverb
+	return mixer
+}
+
+func (self *Mixer) Bus(key BusKey) *Bus {
+	return self.buses[key]
+}
+
+func (self *Mixer) MusicPitch() *PitchShifter { return self.musicPitch }
+func (self *Mixer) Muffle() *BiquadFilter { return self.muffle }
+func (self *Mixer) Reverb() *Reverb { return self.reverb }
+
+// Ducks the music bus by the given amount (0 to 1) while
+// the voice is playing.
+func (self *Mixer) duckWhilePlaying(voice *sfxVoice, amount float32) {
+	if amount <= 0 { return }
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.duckers = append(self.duckers, mixerDucker{
+		voice: voice,
+		generation: voice.generation.Load(),
+		amount: amount,
+	})
+}
+
+func (self *Mixer) WriteStereo(buffer []float32) (int, error) {
+	// update ducking
+	self.mutex.Lock()
+	var ducking float32
+	for i := 0; i < len(self.duckers); {
+		ducker := self.duckers[i]
+		if ducker.voice.Done() || ducker.voice.generation.Load() != ducker.generation {
+			last := len(self.duckers) - 1
+			self.duckers[i] = self.duckers[last]
+			self.duckers = self.duckers[ : last]
+			continue
+		}
+		if ducker.amount > ducking { ducking = ducker.amount }
+		i += 1
+	}
+	self.mutex.Unlock()
+	self.buses[BusMusic].setDucking(ducking)
+
+	// mix everything and apply limiter
+	n, err := self.output.WriteStereo(buffer)
+	self.limiter.Process(buffer[ : n << 1])
+	self.mutex.Lock()
+	self.position += int64(n)
+	self.mutex.Unlock()
+	return n, err
+}
+
+// The mixer is a live stream, so seeking doesn't apply.
+func (self *Mixer) SeekSample(n int) error { return nil }
+
+// Returns the number of samples mixed so far.
+func (self *Mixer) GetPosition() int64 {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	return self.position
+}
@@ -0,0 +1,104 @@
+package audio
+
+import "math"
+import "time"
+
+// Tempo and time signature metadata for a BGM.
+type Tempo struct {
+	BPM float64
+	BeatsPerBar int
+	Offset time.Duration // position of the first beat in the track
+}
+
+func (self Tempo) validate() {
+	if self.BPM <= 0 { panic("BPM <= 0") }
+	if self.BeatsPerBar <= 0 { panic("BeatsPerBar <= 0") }
+	if self.Offset < 0 { panic("negative tempo offset") }
+}
+
+// The music clock reports the current beat and bar of the active BGM,
+// based on the actual playback position (the output buffer latency is
+// compensated for). The clock is refreshed on Soundscape.Update(), so
+// the values are stable during a tick.
+//
+// The clock only runs while the active BGM has a tempo. Looping BGMs
+// jump back in beats and bars when they loop.
+type MusicClock struct {
+	running bool
+	tempo Tempo
+	beats float64 // since the first beat, can be negative before it
+	beatTriggered bool
+	barTriggered bool
+}
+
+func (self *MusicClock) IsRunning() bool { return self.running }
+
+// Returns the position in beats since the first beat, with the
+// fractional part indicating the progress within the current beat,
+// and whether the clock is running. Implements shaders.BeatClock.
+func (self *MusicClock) BeatPosition() (float64, bool) {
+	return self.beats, self.running
+}
+
+// Returns the index of the current beat since the first beat.
+func (self *MusicClock) Beat() int {
+	return int(math.Floor(self.beats))
+}
+
+// Returns the index of the current bar since the first beat.
+func (self *MusicClock) Bar() int {
+	return int(math.Floor(self.beats/float64(self.tempo.BeatsPerBar)))
+}
+
+// Returns the index of the current beat within the current bar.
+func (self *MusicClock) BeatInBar() int {
+	beat := self.Beat() % self.tempo.BeatsPerBar
+	if beat < 0 { beat += self.tempo.BeatsPerBar }
+	return beat
+}
+
+// Returns the progress within the current beat, in [0, 1).
+func (self *MusicClock) BeatPhase() float64 {
+	return self.beats - math.Floor(self.beats)
+}
+
+// Returns the progress within the current bar, in [0, 1).
+func (self *MusicClock) BarPhase() float64 {
+	bars := self.beats/float64(self.tempo.BeatsPerBar)
+	return bars - math.Floor(bars)
+}
+
+// Returns whether a new beat started since the previous tick.
+func (self *MusicClock) BeatTriggered() bool { return self.beatTriggered }
+
+// Returns whether a new bar started since the previous tick.
+func (self *MusicClock) BarTriggered() bool { return self.barTriggered }
+
+// Returns the tempo of the BGM the clock is following.
+func (self *MusicClock) Tempo() Tempo { return self.tempo }
+
+func (self *MusicClock) stop() {
+	self.running = false
+	self.beatTriggered = false
+	self.barTriggered = false
+}
+
+// Updates the clock with the current playback position of the BGM.
+func (self *MusicClock) update(tempo Tempo, position int64) {
+	offset := TimeDurationToSamples(tempo.Offset)
+	samplesPerBeat := (60.0*44100.0)/tempo.BPM
+	beats := float64(position - offset)/samplesPerBeat
+
+	if self.running && self.tempo == tempo {
+		prevBeat, prevBar := self.Beat(), self.Bar()
+		self.beats = beats
+		self.beatTriggered = self.Beat() != prevBeat
+		self.barTriggered  = self.Bar() != prevBar
+	} else {
+		self.running = true
+		self.tempo = tempo
+		self.beats = beats
+		self.beatTriggered = false
+		self.barTriggered = false
+	}
+}
@@ -0,0 +1,102 @@
+package audio
+
+import "sync"
+import "math"
+
+var _ StereoProcessor = (*Panner)(nil)
+
+// Pan and gain ramps, in samples per full unit.
+const pannerRampSamples = 44100*20/1000 // 20ms
+
+// A stereo processing stage that applies balance panning and a gain
+// to its source. Changes are ramped to avoid clicks, which allows
+// updating positioned sounds while they play (see Soundscape.PlaySFXAt).
+//
+// Since sources are already stereo, panning is a balance control:
+// centered sounds are unchanged, and panning attenuates the channel
+// on the opposite side.
+type Panner struct {
+	source StereoProcessor
+
+	mutex sync.Mutex
+	pan, panTarget float32 // -1 (left) to 1 (right)
+	gain, gainTarget float32
+}
+
+func NewPanner(source StereoProcessor, pan, gain float32) *Panner {
+	if pan < -1 || pan > 1 { panic("pan must be in [-1, 1]") }
+	if gain < 0 { panic("gain < 0") }
+	return &Panner{
+		source: source,
+		pan: pan, panTarget: pan,
+		gain: gain, gainTarget: gain,
+	}
+}
+
+func (self *Panner) SetPan(pan float32) {
+	if pan < -1 || pan > 1 { panic("pan must be in [-1, 1]") }
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.panTarget = pan
+}
+
+func (self *Panner) SetGain(gain float32) {
+	if gain < 0 { panic("gain < 0") }
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.gainTarget = gain
+}
+
+func (self *Panner) WriteStereo(buffer []float32) (int, error) {
+	n, err := self.source.WriteStereo(buffer)
+
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	for i := 0; i < n << 1; i += 2 {
+		self.pan  = rampTowards(self.pan, self.panTarget, 1.0/pannerRampSamples)
+		self.gain = rampTowards(self.gain, self.gainTarget, 1.0/pannerRampSamples)
+		buffer[i + 0] *= self.gain*min(1.0, 1.0 - self.pan)
+		buffer[i + 1] *= self.gain*min(1.0, 1.0 + self.pan)
+	}
+	return n, err
+}
+
+func (self *Panner) SeekSample(n int) error {
+	return self.source.SeekSample(n)
+}
+
+func (self *Panner) GetPosition() int64 {
+	return self.source.GetPosition()
+}
+
+// Forwards the release to the source when the panner
+// is removed from an adder (see VoicePool).
+func (self *Panner) release() {
+	if source, ok := self.source.(releasable); ok { source.release() }
+}
+
+func rampTowards(current, target, step float32) float32 {
+	if current < target { return min(current + step, target) }
+	if current > target { return max32(current - step, target) }
+	return current
+}
+
+// Spatialization parameters, in logical pixels.
+const (
+	spatialNearDist  = 160.0 // no attenuation up to this distance
+	spatialFarDist   = 720.0 // silence from this distance onwards
+	spatialPanWidth  = 320.0 // horizontal distance for max panning
+	spatialMaxPan    = 0.7
+	spatialVertScale = 1.5 // vertical distances weight more, the screen is wide
+)
+
+// Returns the pan and gain for a sound at the given
+// offset from the listener.
+func spatialize(dx, dy float64) (float32, float32) {
+	pan := math.Max(-1, math.Min(dx/spatialPanWidth, 1))*spatialMaxPan
+	dist := math.Hypot(dx, dy*spatialVertScale)
+	if dist <= spatialNearDist { return float32(pan), 1.0 }
+	if dist >= spatialFarDist  { return float32(pan), 0.0 }
+	gain := 1.0 - (dist - spatialNearDist)/(spatialFarDist - spatialNearDist)
+	return float32(pan), float32(gain*gain)
+}
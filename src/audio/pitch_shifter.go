@@ -0,0 +1,123 @@
+package audio
+
+import "sync"
+
+var _ StereoProcessor = (*PitchShifter)(nil)
+
+// Rate limits and ramp speed for the PitchShifter.
+const (
+	PitchMinRate = 0.25
+	PitchMaxRate = 4.0
+)
+const pitchRateRampSamples = 44100*100/1000 // 100ms per unit of rate
+
+// Shifts the pitch by changing the playback rate, like a tape or
+// a turntable would, so the speed changes too. Resampling uses linear
+// interpolation. Rate changes are ramped.
+//
+// The source is read ahead in small chunks, so GetPosition() is only
+// approximate while the rate is not 1.
+type PitchShifter struct {
+	mutex sync.Mutex
+	source StereoProcessor
+	rate, rateTarget float64
+
+	chunk []float32 // source samples read ahead
+	chunkIndex int // next frame to use, in samples
+	chunkLen int // in samples
+	sourceErr error
+
+	prev, next [2]float32 // frames to interpolate between
+	phase float64 // position between prev and next, in [0, 1)
+	primed bool
+}
+
+func NewPitchShifter(source StereoProcessor) *PitchShifter {
+	return &PitchShifter{
+		source: source,
+		rate: 1.0,
+		rateTarget: 1.0,
+		chunk: make([]float32, 1024),
+	}
+}
+
+// Sets the playback rate. 1 is the original pitch, 2 an octave up,
+// 0.5 an octave down.
+func (self *PitchShifter) SetRate(rate float32) {
+	if rate < PitchMinRate || rate > PitchMaxRate { panic("rate out of range") }
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.rateTarget = float64(rate)
+}
+
+func (self *PitchShifter) GetRate() float32 {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	return float32(self.rateTarget)
+}
+
+func (self *PitchShifter) WriteStereo(buffer []float32) (int, error) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+
+	// do not allow odd-sized buffers
+	if len(buffer) & 0b01 != 0 { buffer = buffer[0 : len(buffer) - 1] }
+	if !self.primed {
+		if !self.advance() || !self.advance() { return 0, self.sourceErr }
+		self.primed = true
+	} else if self.sourceErr != nil && self.chunkIndex >= self.chunkLen {
+		return 0, self.sourceErr // source already exhausted
+	}
+
+	for i := 0; i < len(buffer); i += 2 {
+		phase := float32(self.phase)
+		buffer[i + 0] = self.prev[0] + (self.next[0] - self.prev[0])*phase
+		buffer[i + 1] = self.prev[1] + (self.next[1] - self.prev[1])*phase
+
+		if self.rate != self.rateTarget {
+			self.rate = float64(rampTowards(float32(self.rate), float32(self.rateTarget), 1.0/pitchRateRampSamples))
+		}
+		self.phase += self.rate
+		for self.phase >= 1.0 {
+			self.phase -= 1.0
+			if !self.advance() { return (i >> 1) + 1, self.sourceErr }
+		}
+	}
+	return len(buffer) >> 1, nil
+}
+
+func (self *PitchShifter) SeekSample(n int) error {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.chunkIndex, self.chunkLen = 0, 0
+	self.phase = 0
+	self.primed = false
+	self.sourceErr = nil
+	return self.source.SeekSample(n)
+}
+
+func (self *PitchShifter) GetPosition() int64 {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	return self.source.GetPosition()
+}
+
+// Moves to the next source frame. Returns false if the source
+// has no more frames, in which case sourceErr is set.
+func (self *PitchShifter) advance() bool {
+	if self.chunkIndex >= self.chunkLen {
+		if self.sourceErr != nil { return false }
+		n, err := self.source.WriteStereo(self.chunk)
+		self.chunkIndex, self.chunkLen = 0, n << 1
+		self.sourceErr = err
+		if n == 0 {
+			if err == nil { panic("source wrote no samples and returned no error") }
+			return false
+		}
+	}
+	self.prev = self.next
+	self.next[0] = self.chunk[self.chunkIndex + 0]
+	self.next[1] = self.chunk[self.chunkIndex + 1]
+	self.chunkIndex += 2
+	return true
+}
@@ -0,0 +1,51 @@
+package audio_test
+
+import "io"
+import "time"
+import "testing"
+
+import "github.com/tinne26/transition/src/audio"
+import "github.com/tinne26/transition/src/audio/audiotest"
+
+func TestPitchShifterRate(t *testing.T) {
+	source := audiotest.NewConstSource(0.5, -0.5)
+	shifter := audio.NewPitchShifter(source)
+	shifter.SetRate(2.0)
+
+	// let the rate ramp finish (100ms per unit of rate)
+	_, err := audio.Render(shifter, int(audio.TimeDurationToSamples(time.Millisecond*100)))
+	if err != nil { t.Fatal(err) }
+
+	start := source.GetPosition()
+	numSamples := int(audio.TimeDurationToSamples(time.Second))
+	samples, err := audio.Render(shifter, numSamples)
+	if err != nil { t.Fatal(err) }
+	consumed := int(source.GetPosition() - start)
+	if consumed < numSamples*2 - 512 || consumed > numSamples*2 + 512 { // source is read in chunks
+		t.Fatalf("source advanced %d samples while rendering %d, expected twice as many", consumed, numSamples)
+	}
+	envelope := audiotest.NewEnvelope(samples, audiotest.Left, testWindow)
+	audiotest.AssertSettledBy(t, envelope, 0.5, 0, 0.0001)
+}
+
+func TestPitchShifterEOF(t *testing.T) {
+	const SourceSamples = 44100
+	shifter := audio.NewPitchShifter(audiotest.NewLimitedSource(audiotest.NewConstSource(0.5, 0.5), SourceSamples))
+	shifter.SetRate(2.0)
+	samples, err := audio.Render(shifter, SourceSamples)
+	if err != nil { t.Fatal(err) }
+
+	// the ramp from rate 1 to 2 takes 4410 samples, consuming 6615
+	expected := 4410 + (SourceSamples - 6615)/2
+	rendered := len(samples) >> 1
+	if rendered < expected - 16 || rendered > expected + 16 {
+		t.Fatalf("rendered %d samples, expected around %d", rendered, expected)
+	}
+
+	// reading after the end must keep returning io.EOF
+	buffer := make([]float32, 512)
+	for i := 0; i < 3; i++ {
+		n, err := shifter.WriteStereo(buffer)
+		if n != 0 || err != io.EOF { t.Fatalf("expected (0, io.EOF) after the end, got (%d, %v)", n, err) }
+	}
+}
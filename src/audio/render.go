@@ -0,0 +1,99 @@
+package audio
+
+import "io"
+import "errors"
+import "encoding/binary"
+
+// Offline rendering of StereoProcessor chains. Unlike the live
+// output, this doesn't need an audio context nor an audio device,
+// so it can be used in tests and tools.
+
+const renderSampleRate = 44100
+const renderChunkSamples = 1024
+
+var errRenderStalled = errors.New("source wrote no samples and returned no error")
+
+// Renders the given number of samples from the source and returns
+// them as interleaved stereo values. If the source ends early (io.EOF),
+// the returned slice is shorter and no error is reported.
+func Render(source StereoProcessor, numSamples int) ([]float32, error) {
+	out := make([]float32, numSamples*2)
+	rendered, err := renderInto(source, out)
+	return out[ : rendered*2], err
+}
+
+// Renders the source in tick-sized chunks, calling update before each
+// tick, like the game does with Soundscape.Update(). The tick rate must
+// divide the sample rate evenly (e.g. 60).
+func RenderTicks(source StereoProcessor, numTicks int, ticksPerSecond int, update func() error) ([]float32, error) {
+	if renderSampleRate % ticksPerSecond != 0 { panic("ticksPerSecond must divide 44100") }
+	tickSamples := renderSampleRate/ticksPerSecond
+	out := make([]float32, numTicks*tickSamples*2)
+	offset := 0
+	for tick := 0; tick < numTicks; tick++ {
+		if update != nil {
+			err := update()
+			if err != nil { return out[ : offset], err }
+		}
+		rendered, err := renderInto(source, out[offset : offset + tickSamples*2])
+		offset += rendered*2
+		if err != nil || rendered < tickSamples { return out[ : offset], err }
+	}
+	return out, nil
+}
+
+// Returns the number of samples written to out.
+func renderInto(source StereoProcessor, out []float32) (int, error) {
+	offset := 0
+	for offset < len(out) {
+		end := min(offset + renderChunkSamples*2, len(out))
+		n, err := source.WriteStereo(out[offset : end])
+		offset += n*2
+		if errors.Is(err, io.EOF) { return offset >> 1, nil }
+		if err != nil { return offset >> 1, err }
+		if n == 0 { return offset >> 1, errRenderStalled }
+	}
+	return offset >> 1, nil
+}
+
+// Writes the given interleaved stereo samples as a 16-bit
+// 44100Hz WAV file. Values outside [-1, 1] are clipped.
+func WriteWAV(writer io.Writer, samples []float32) error {
+	const HeaderSize = 44
+	dataSize := uint32((len(samples) >> 1)*4)
+	header := make([]byte, HeaderSize)
+	copy(header[0 : 4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4 : 8], HeaderSize - 8 + dataSize)
+	copy(header[8 : 16], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(header[16 : 20], 16) // fmt chunk size
+	binary.LittleEndian.PutUint16(header[20 : 22], 1) // PCM
+	binary.LittleEndian.PutUint16(header[22 : 24], 2) // channels
+	binary.LittleEndian.PutUint32(header[24 : 28], renderSampleRate)
+	binary.LittleEndian.PutUint32(header[28 : 32], renderSampleRate*4) // byte rate
+	binary.LittleEndian.PutUint16(header[32 : 34], 4) // block align
+	binary.LittleEndian.PutUint16(header[34 : 36], 16) // bits per sample
+	copy(header[36 : 40], "data")
+	binary.LittleEndian.PutUint32(header[40 : 44], dataSize)
+	_, err := writer.Write(header)
+	if err != nil { return err }
+
+	buffer := make([]byte, renderChunkSamples*4)
+	for len(samples) >= 2 {
+		chunk := min(len(samples) >> 1, renderChunkSamples)
+		for i := 0; i < chunk; i++ {
+			StoreNormF32StereoSampleAsL16(buffer[i*4 : ], samples[i*2], samples[i*2 + 1])
+		}
+		_, err = writer.Write(buffer[ : chunk*4])
+		if err != nil { return err }
+		samples = samples[chunk*2 : ]
+	}
+	return nil
+}
+
+// Renders the given number of samples from the source and
+// writes them as a WAV file (see WriteWAV()).
+func RenderWAV(writer io.Writer, source StereoProcessor, numSamples int) error {
+	samples, err := Render(source, numSamples)
+	if err != nil { return err }
+	return WriteWAV(writer, samples)
+}
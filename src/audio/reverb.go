@@ -0,0 +1,162 @@
+package audio
+
+import "sync"
+
+import "github.com/tinne26/transition/src/utils"
+
+var _ StereoProcessor = (*Reverb)(nil)
+
+// Freeverb tunings, in samples at 44100Hz.
+var reverbCombTunings = [8]int{ 1116, 1188, 1277, 1356, 1422, 1491, 1557, 1617 }
+var reverbAllpassTunings = [4]int{ 556, 441, 341, 225 }
+const reverbStereoSpread = 23
+const reverbFixedGain = 0.015
+const reverbWetScale = 3.0
+const reverbWetRampSamples = 44100*50/1000 // 50ms
+
+// A simple Schroeder/Freeverb reverb: eight parallel lowpass-feedback
+// comb filters followed by four series allpass filters per channel.
+// While the wet level is zero, the reverb is bypassed entirely.
+type Reverb struct {
+	mutex sync.Mutex
+	source StereoProcessor
+	combs [2][8]reverbComb
+	allpasses [2][4]reverbAllpass
+	feedback float32
+	damping float32
+	wet, wetTarget float32
+	dry float32
+}
+
+type reverbComb struct {
+	buffer []float32
+	index int
+	filterStore float32
+}
+
+type reverbAllpass struct {
+	buffer []float32
+	index int
+}
+
+func NewReverb(source StereoProcessor) *Reverb {
+	reverb := &Reverb{ source: source, dry: 1.0 }
+	for channel := 0; channel < 2; channel++ {
+		for i, tuning := range reverbCombTunings {
+			reverb.combs[channel][i].buffer = make([]float32, tuning + channel*reverbStereoSpread)
+		}
+		for i, tuning := range reverbAllpassTunings {
+			reverb.allpasses[channel][i].buffer = make([]float32, tuning + channel*reverbStereoSpread)
+		}
+	}
+	reverb.SetRoomSize(0.5)
+	reverb.SetDamping(0.5)
+	return reverb
+}
+
+// Sets the room size, between 0 and 1.
+func (self *Reverb) SetRoomSize(size float32) {
+	if size < 0 || size > 1 { panic("room size must be in [0, 1]") }
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.feedback = size*0.28 + 0.7
+}
+
+// Sets how much high frequencies are damped, between 0 and 1.
+func (self *Reverb) SetDamping(damping float32) {
+	if damping < 0 || damping > 1 { panic("damping must be in [0, 1]") }
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.damping = damping*0.4
+}
+
+// Sets the level of the reverberated signal, between 0 and 1.
+// Changes are ramped.
+func (self *Reverb) SetWet(wet float32) {
+	if wet < 0 || wet > 1 { panic("wet must be in [0, 1]") }
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.wetTarget = wet
+}
+
+func (self *Reverb) GetWet() float32 {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	return self.wetTarget
+}
+
+// Sets the level of the original signal, between 0 and 1.
+func (self *Reverb) SetDry(dry float32) {
+	if dry < 0 || dry > 1 { panic("dry must be in [0, 1]") }
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.dry = dry
+}
+
+func (self *Reverb) WriteStereo(buffer []float32) (int, error) {
+	n, err := self.source.WriteStereo(buffer)
+
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if self.wet == 0 && self.wetTarget == 0 {
+		if self.dry != 1.0 {
+			for i := 0; i < n << 1; i++ { buffer[i] *= self.dry }
+		}
+		return n, err
+	}
+	if self.wet == 0 { self.clear() } // coming out of bypass
+
+	for i := 0; i < n << 1; i += 2 {
+		self.wet = rampTowards(self.wet, self.wetTarget, 1.0/reverbWetRampSamples)
+		input := (buffer[i + 0] + buffer[i + 1])*reverbFixedGain
+		for channel := 0; channel < 2; channel++ {
+			var out float32
+			for j, _ := range self.combs[channel] {
+				out += self.combs[channel][j].process(input, self.feedback, self.damping)
+			}
+			for j, _ := range self.allpasses[channel] {
+				out = self.allpasses[channel][j].process(out)
+			}
+			buffer[i + channel] = buffer[i + channel]*self.dry + out*self.wet*reverbWetScale
+		}
+	}
+	return n, err
+}
+
+func (self *Reverb) SeekSample(n int) error {
+	return self.source.SeekSample(n)
+}
+
+func (self *Reverb) GetPosition() int64 {
+	return self.source.GetPosition()
+}
+
+func (self *Reverb) clear() {
+	for channel := 0; channel < 2; channel++ {
+		for i, _ := range self.combs[channel] {
+			comb := &self.combs[channel][i]
+			utils.FastFill(comb.buffer, 0)
+			comb.filterStore = 0
+		}
+		for i, _ := range self.allpasses[channel] {
+			utils.FastFill(self.allpasses[channel][i].buffer, 0)
+		}
+	}
+}
+
+func (self *reverbComb) process(input, feedback, damping float32) float32 {
+	output := self.buffer[self.index]
+	self.filterStore = output*(1.0 - damping) + self.filterStore*damping
+	self.buffer[self.index] = input + self.filterStore*feedback
+	self.index += 1
+	if self.index >= len(self.buffer) { self.index = 0 }
+	return output
+}
+
+func (self *reverbAllpass) process(input float32) float32 {
+	buffered := self.buffer[self.index]
+	self.buffer[self.index] = input + buffered*0.5
+	self.index += 1
+	if self.index >= len(self.buffer) { self.index = 0 }
+	return buffered - input
+}
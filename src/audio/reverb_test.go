@@ -0,0 +1,39 @@
+package audio_test
+
+import "time"
+import "testing"
+
+import "github.com/tinne26/transition/src/audio"
+import "github.com/tinne26/transition/src/audio/audiotest"
+
+func TestReverbBypass(t *testing.T) {
+	numSamples := int(audio.TimeDurationToSamples(time.Millisecond*100))
+	expected, err := audio.Render(audiotest.NewSineSource(440, 0.8), numSamples)
+	if err != nil { t.Fatal(err) }
+	reverb := audio.NewReverb(audiotest.NewSineSource(440, 0.8))
+	samples, err := audio.Render(reverb, numSamples)
+	if err != nil { t.Fatal(err) }
+	assertSameSamples(t, samples, expected)
+}
+
+func TestReverbTail(t *testing.T) {
+	reverb := audio.NewReverb(audiotest.NewImpulseSource(1.0))
+	reverb.SetWet(1.0)
+	reverb.SetDry(0.0)
+	samples, err := audio.Render(reverb, int(audio.TimeDurationToSamples(time.Second*3)))
+	if err != nil { t.Fatal(err) }
+	envelope := audiotest.NewEnvelope(samples, audiotest.Both, testWindow)
+
+	// nothing comes out before the shortest comb delay, then the
+	// tail builds up and decays
+	audiotest.AssertLevelAt(t, envelope, 0, 0, 0)
+	if envelope.At(time.Millisecond*100) < 0.05 {
+		t.Fatalf("tail level at 100ms is only %f", envelope.At(time.Millisecond*100))
+	}
+	for at := time.Millisecond*100; at < time.Millisecond*1500; at += time.Millisecond*250 {
+		if envelope.At(at + time.Millisecond*250) >= envelope.At(at) {
+			t.Fatalf("tail not decaying at %s", at.String())
+		}
+	}
+	audiotest.AssertSettledBy(t, envelope, 0, time.Millisecond*1500, 0.0001)
+}
@@ -1,47 +1,91 @@
 package audio
 
-import "time"
-import "math/rand"
-import "github.com/hajimehoshi/ebiten/v2/audio"
+import "github.com/tinne26/transition/src/rng"
 
+// Default limit of simultaneous voices for a single sound effect.
+const DefaultSfxMaxVoices = 4
+
+// A sound effect, possibly with multiple variations. Each play gets
+// a voice from the soundscape's VoicePool that goes through the sound
+// effect's bus.
 type SfxPlayer struct {
-	sources [][]byte
-	userVolume float64
-	volumeCorrectorFactor float64
-	minBackoff time.Duration
-	lastPlayed time.Time
+	sources [][]float32
+	volumeCorrectorFactor float32
+	bus BusKey
+	ducking float32
+
+	maxVoices int
+	policy StealPolicy
+	backoffTicks uint64
+	lastPlayedTick uint64
+	hasPlayed bool
 }
 
 func NewSfxPlayer(bytes ...[]byte) *SfxPlayer {
-	return &SfxPlayer{ sources: bytes, userVolume: 0.5 }
+	sources := make([][]float32, len(bytes))
+	for i, _ := range bytes {
+		sources[i] = l16BytesToF32s(bytes[i])
+	}
+	return &SfxPlayer{
+		sources: sources,
+		volumeCorrectorFactor: 1.0,
+		bus: BusSFX,
+		maxVoices: DefaultSfxMaxVoices,
+		policy: StealOldest,
+	}
 }
 
-func (self *SfxPlayer) SetBackoff(duration time.Duration) {
-	self.minBackoff = duration
+// Sets the minimum number of ticks between two plays of the sound
+// effect. Plays within the backoff are ignored. Ticks are used instead
+// of wall-clock time so replays remain deterministic.
+func (self *SfxPlayer) SetBackoff(ticks uint64) {
+	self.backoffTicks = ticks
 }
 
-func (self *SfxPlayer) SetUserVolume(volume float64) {
-	if volume < 0 { panic("volume < 0") }
-	if volume > 1 { panic("volume > 1") }
-	self.userVolume = volume
+// Sets the maximum number of simultaneous voices for the sound
+// effect, and what to do when the limit is reached. Zero means
+// no limit other than the global one.
+func (self *SfxPlayer) SetPolyphony(maxVoices int, policy StealPolicy) {
+	if maxVoices < 0 { panic("maxVoices < 0") }
+	self.maxVoices = maxVoices
+	self.policy = policy
 }
 
 func (self *SfxPlayer) SetVolumeCorrectorFactor(factor float64) {
 	if factor < 0 { panic("factor < 0") }
 	if factor > 1 { panic("factor > 1") }
-	self.volumeCorrectorFactor = factor
+	self.volumeCorrectorFactor = float32(factor)
+}
+
+// Sets the bus the sound effect is played through. BusSFX by default.
+func (self *SfxPlayer) SetBus(bus BusKey) {
+	if bus == BusMaster || bus >= busEndSentinel { panic(bus) }
+	self.bus = bus
+}
+
+func (self *SfxPlayer) Bus() BusKey {
+	return self.bus
+}
+
+// Sets how much the music must be ducked while the sound effect
+// plays, between 0 (no ducking, the default) and 1 (full silence).
+func (self *SfxPlayer) SetDucking(amount float32) {
+	if amount < 0 || amount > 1 { panic("ducking amount must be in [0, 1]") }
+	self.ducking = amount
 }
 
-func (self *SfxPlayer) Play() {
+// Returns a new voice for the sound effect, or nil if the effect
+// is still within its backoff or the voice limits don't allow it.
+func (self *SfxPlayer) newVoice(pool *VoicePool, tick uint64) *sfxVoice {
 	// backoff logic
-	now := time.Now()
-	if self.minBackoff > now.Sub(self.lastPlayed) { return }
-	self.lastPlayed = now
+	if self.hasPlayed && tick - self.lastPlayedTick < self.backoffTicks { return nil }
 
 	// play from pool of sfxs
 	index := 0
-	if len(self.sources) > 1 { index = rand.Intn(len(self.sources)) }
-	player := audio.CurrentContext().NewPlayerFromBytes(self.sources[index])
-	player.SetVolume(self.userVolume*self.volumeCorrectorFactor)
-	player.Play()
+	if len(self.sources) > 1 { index = rng.Audio.Intn(len(self.sources)) }
+	voice := pool.acquire(self, self.sources[index], tick)
+	if voice == nil { return nil }
+	self.lastPlayedTick = tick
+	self.hasPlayed = true
+	return voice
 }
@@ -0,0 +1,126 @@
+package audio
+
+import "io"
+import "math"
+import "sync/atomic"
+
+var _ StereoProcessor = (*sfxVoice)(nil)
+
+// Fade out applied to stolen voices, in samples.
+const voiceStealFadeSamples = 44100*5/1000 // 5ms
+
+// A single playback of a sound effect. Voices are added to a bus and
+// removed automatically once they finish, and then recycled by the
+// VoicePool. Only the audio thread reads from the voice while it's
+// playing, so only the flags shared with the game thread are atomic.
+type sfxVoice struct {
+	samples []float32 // interleaved stereo
+	position int // index in samples
+	volume float32
+	fadeLeft int // samples left in the steal fade, audio thread only
+
+	owner *SfxPlayer // game thread only
+	startTick uint64 // game thread only
+
+	generation atomic.Uint32 // increased on each reuse
+	stopping atomic.Bool
+	done atomic.Bool
+	released atomic.Bool
+	level atomic.Uint32 // float32 bits, peak of the last written chunk
+}
+
+func newSfxVoice(samples []float32, volume float32) *sfxVoice {
+	voice := &sfxVoice{}
+	voice.reset(samples, volume)
+	return voice
+}
+
+// Prepares the voice for a new playback. Must only be called on new
+// voices or voices already released by the bus they were playing on.
+func (self *sfxVoice) reset(samples []float32, volume float32) {
+	self.samples = samples
+	self.position = 0
+	self.volume = volume
+	self.fadeLeft = voiceStealFadeSamples
+	self.generation.Add(1)
+	self.stopping.Store(false)
+	self.done.Store(false)
+	self.released.Store(false)
+	self.level.Store(math.Float32bits(volume))
+}
+
+func (self *sfxVoice) Done() bool {
+	return self.done.Load()
+}
+
+// Makes the voice fade out quickly and end.
+func (self *sfxVoice) stop() {
+	self.stopping.Store(true)
+}
+
+func (self *sfxVoice) isStopping() bool {
+	return self.stopping.Load()
+}
+
+// Called by the Adder once the voice has been removed.
+func (self *sfxVoice) release() {
+	self.released.Store(true)
+}
+
+func (self *sfxVoice) isReleased() bool {
+	return self.released.Load()
+}
+
+// Returns the peak level of the most recently played chunk, or
+// the voice volume if the voice hasn't started playing yet.
+func (self *sfxVoice) Level() float32 {
+	return math.Float32frombits(self.level.Load())
+}
+
+func (self *sfxVoice) WriteStereo(buffer []float32) (int, error) {
+	if len(buffer) & 0b01 != 0 { buffer = buffer[0 : len(buffer) - 1] }
+	stopping := self.stopping.Load()
+	if stopping && len(buffer) > self.fadeLeft*2 {
+		buffer = buffer[ : self.fadeLeft*2]
+	}
+
+	n := copy(buffer, self.samples[self.position : ])
+	var peak float32
+	for i := 0; i < n; i++ {
+		buffer[i] *= self.volume
+		if stopping && i & 0b01 == 0 {
+			self.fadeLeft -= 1
+		}
+		if stopping {
+			buffer[i] *= float32(self.fadeLeft)/voiceStealFadeSamples
+		}
+		peak = max32(peak, abs32(buffer[i]))
+	}
+	self.level.Store(math.Float32bits(peak))
+	self.position += n
+
+	if self.position >= len(self.samples) || (stopping && self.fadeLeft <= 0) {
+		self.done.Store(true)
+		return n >> 1, io.EOF
+	}
+	return n >> 1, nil
+}
+
+func (self *sfxVoice) SeekSample(n int) error {
+	self.position = min(n << 1, len(self.samples))
+	return nil
+}
+
+func (self *sfxVoice) GetPosition() int64 {
+	return int64(self.position >> 1)
+}
+
+// Converts L16 stereo bytes to interleaved float32 samples.
+func l16BytesToF32s(bytes []byte) []float32 {
+	samples := make([]float32, 0, len(bytes) >> 1)
+	for i := 0; i + 3 < len(bytes); i += 4 {
+		left, right := GetStereoSampleAsF32s(bytes[i : i + 4])
+		samples = append(samples, left, right)
+	}
+	return samples
+}
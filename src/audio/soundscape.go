@@ -2,10 +2,16 @@ package audio
 
 import "time"
 
+import "github.com/hajimehoshi/ebiten/v2/audio"
+
 import "github.com/tinne26/transition/src/utils"
 
 type Soundscape struct {
 	automationPanel *AutomationPanel
+	mixer *Mixer
+	voices *VoicePool
+	tick uint64
+	output *audio.Player // nil until started
 
 	userVolumeSFX float32
 	userVolumeBGM float32
@@ -14,38 +20,96 @@ type Soundscape struct {
 	bgms []*BGM
 	activeBGM *BGM
 	fadingOutBGMs []*BGM
+	muted bool
+
+	listener Listener
+	positioned []positionedVoice
+	clock MusicClock
+}
+
+// The listener for positioned sound effects. The camera
+// is the listener in practice (see PlaySFXAt()).
+type Listener interface {
+	PointInFocus() (float64, float64)
+}
+
+type positionedVoice struct {
+	voice *sfxVoice
+	generation uint32
+	panner *Panner
+	x, y uint16
 }
 
 func NewSoundscape() *Soundscape {
-	return &Soundscape{
-		userVolumeBGM: 0.5,
-		userVolumeSFX: 0.5,
+	soundscape := &Soundscape{
 		sfxs: make([]*SfxPlayer, 0, 16),
 		bgms: make([]*BGM, 0, 8),
 		automationPanel: NewAutomationPanel(),
+		mixer: NewMixer(),
+		voices: NewVoicePool(DefaultMaxVoices, StealQuietest),
 	}
+	soundscape.SetUserBGMVolume(0.5)
+	soundscape.SetUserSFXVolume(0.5)
+	return soundscape
 }
 
-func (self *Soundscape) SetUserSFXVolume(volume float32) {
+// Creates a soundscape that ignores all playback requests. Meant
+// for headless simulations, where no sounds are loaded at all.
+func NewMutedSoundscape() *Soundscape {
+	soundscape := NewSoundscape()
+	soundscape.muted = true
+	return soundscape
+}
+
+// Starts playing the mixer output. The audio context must exist.
+func (self *Soundscape) start() error {
+	if self.output != nil { panic("soundscape already started") }
+	source := NewProcessorOutAdapterL16[*Mixer](self.mixer)
+	source.SetUserVolume(1.0) // volumes are handled by the buses
+	var err error
+	self.output, err = audio.CurrentContext().NewPlayer(source)
+	if err != nil { return err }
+	self.output.SetBufferSize(time.Millisecond*66)
+	self.output.Play()
+	return nil
+}
+
+func (self *Soundscape) Mixer() *Mixer {
+	return self.mixer
+}
+
+func (self *Soundscape) VoicePool() *VoicePool {
+	return self.voices
+}
+
+// Sets the gain of the given bus. Volumes must be in [0, 1].
+func (self *Soundscape) SetBusVolume(bus BusKey, volume float32) {
 	if volume < 0 { panic("volume < 0") }
 	if volume > 1 { panic("volume > 1") }
+	self.mixer.Bus(bus).SetGain(volume)
+}
+
+func (self *Soundscape) GetBusVolume(bus BusKey) float32 {
+	return self.mixer.Bus(bus).GetGain()
+}
+
+// Sets the user volume for sound effects, which applies
+// to the sfx, ui and ambience buses.
+func (self *Soundscape) SetUserSFXVolume(volume float32) {
+	self.SetBusVolume(BusSFX, volume)
+	self.SetBusVolume(BusUI, volume)
+	self.SetBusVolume(BusAmbience, volume)
 	self.userVolumeSFX = volume
-	for i, _ := range self.sfxs {
-		self.sfxs[i].SetUserVolume(float64(volume)) // one day ebitengine will use float32 for volume
-	}
 }
 
 func (self *Soundscape) GetUserSFXVolume() float32 {
 	return self.userVolumeSFX
 }
 
+// Sets the user volume for music (the music bus).
 func (self *Soundscape) SetUserBGMVolume(volume float32) {
-	if volume < 0 { panic("volume < 0") }
-	if volume > 1 { panic("volume > 1") }
+	self.SetBusVolume(BusMusic, volume)
 	self.userVolumeBGM = volume
-	for i, _ := range self.bgms {
-		self.bgms[i].SetUserVolume(self.userVolumeBGM)
-	}
 }
 
 func (self *Soundscape) GetUserBGMVolume() float32 {
@@ -59,10 +123,54 @@ func (self *Soundscape) RegisterSFX(sfx *SfxPlayer) SfxKey {
 }
 
 func (self *Soundscape) PlaySFX(key SfxKey) {
-	self.sfxs[key].Play()
+	if self.muted { return }
+	sfx := self.sfxs[key]
+	voice := sfx.newVoice(self.voices, self.tick)
+	if voice == nil { return } // backoff or voice limits
+	self.mixer.duckWhilePlaying(voice, sfx.ducking)
+	self.mixer.Bus(sfx.bus).Add(voice)
+}
+
+// Sets the listener for positioned sound effects.
+func (self *Soundscape) SetListener(listener Listener) {
+	self.listener = listener
+}
+
+// Plays a sound effect positioned in the world, panned and attenuated
+// relative to the listener. The panning and attenuation keep being
+// updated while the sound plays. Without a listener, it's the same
+// as PlaySFX().
+func (self *Soundscape) PlaySFXAt(key SfxKey, x, y uint16) {
+	if self.muted { return }
+	if self.listener == nil {
+		self.PlaySFX(key)
+		return
+	}
+
+	pan, gain := self.spatialize(x, y)
+	if gain == 0 { return } // too far away to be heard
+	sfx := self.sfxs[key]
+	voice := sfx.newVoice(self.voices, self.tick)
+	if voice == nil { return } // backoff or voice limits
+	panner := NewPanner(voice, pan, gain)
+	self.positioned = append(self.positioned, positionedVoice{
+		voice: voice,
+		generation: voice.generation.Load(),
+		panner: panner,
+		x: x, y: y,
+	})
+	self.mixer.duckWhilePlaying(voice, sfx.ducking)
+	self.mixer.Bus(sfx.bus).Add(panner)
+}
+
+func (self *Soundscape) spatialize(x, y uint16) (float32, float32) {
+	lx, ly := self.listener.PointInFocus()
+	return spatialize(float64(x) - lx, float64(y) - ly)
 }
 
 func (self *Soundscape) RegisterBGM(bgm *BGM) BgmKey {
+	if bgm.bus != nil { panic("BGM already registered") }
+	bgm.bus = self.mixer.Bus(BusMusic)
 	key := BgmKey(len(self.bgms))
 	self.bgms = append(self.bgms, bgm)
 	return key
@@ -72,7 +180,7 @@ func (self *Soundscape) addToFadingOutBGMs(fadingBgm *BGM) {
 	for _, bgm := range self.fadingOutBGMs {
 		if bgm == fadingBgm { return }
 	}
-	self.fadingOutBGMs = append(self.fadingOutBGMs)
+	self.fadingOutBGMs = append(self.fadingOutBGMs, fadingBgm)
 }
 
 func (self *Soundscape) removeFromFadingOutBGM(fadingBgm *BGM) {
@@ -95,6 +203,7 @@ func (self *Soundscape) FadeOut(fadeOut time.Duration) {
 }
 
 func (self *Soundscape) FadeIn(key BgmKey, fadeOut, wait, fadeIn time.Duration) {
+	if self.muted { return }
 	if self.activeBGM != nil {
 		self.activeBGM.FadeOut(fadeOut)
 		self.addToFadingOutBGMs(self.activeBGM)
@@ -105,23 +214,86 @@ func (self *Soundscape) FadeIn(key BgmKey, fadeOut, wait, fadeIn time.Duration)
 }
 
 func (self *Soundscape) Crossfade(key BgmKey, fadeOut, inWait, fadeIn time.Duration) {
+	if self.muted { return }
 	if self.activeBGM != nil {
 		self.activeBGM.FadeOut(fadeOut)
+		self.addToFadingOutBGMs(self.activeBGM)
 	}
 	self.activeBGM = self.bgms[key]
 	self.removeFromFadingOutBGM(self.activeBGM)
 	self.activeBGM.FadeIn(inWait, fadeIn)
 }
 
+// Returns the clock for the active BGM. See MusicClock.
+func (self *Soundscape) MusicClock() *MusicClock {
+	return &self.clock
+}
+
+func (self *Soundscape) updateMusicClock() {
+	bgm := self.activeBGM
+	if bgm == nil || !bgm.hasTempo || !bgm.playing {
+		self.clock.stop()
+		return
+	}
+
+	// the position right after looping may point slightly
+	// before the loop start, but that's not a big deal
+	position := bgm.source.GetPosition() - self.outputLatency()
+	self.clock.update(bgm.tempo, max(position, 0))
+}
+
+// Returns the number of samples mixed but not played yet.
+func (self *Soundscape) outputLatency() int64 {
+	if self.output == nil { return 0 }
+	played := TimeDurationToSamples(self.output.Current())
+	return max(self.mixer.GetPosition() - played, 0)
+}
+
+// Sets a parameter for all stem-based BGMs (see StemMusic).
+// Meant to be called each tick from gameplay code.
+func (self *Soundscape) SetMusicParam(name string, value float32) {
+	for _, bgm := range self.bgms {
+		if bgm.stems != nil { bgm.stems.SetParam(name, value) }
+	}
+}
+
+// Sets an effect parameter on the automation panel. Ignored on
+// muted soundscapes, where the game params are not registered.
+func (self *Soundscape) SetParam(key ParamKey, value float32) {
+	if self.muted { return }
+	self.automationPanel.SetParam(key, value)
+}
+
 func (self *Soundscape) AutomationPanel() *AutomationPanel {
 	return self.automationPanel
 }
 
 func (self *Soundscape) Update() error {
-	// clean up faded out bgms
+	self.tick += 1
+	self.voices.Collect()
+
+	// update positioned sounds
+	self.positioned = utils.IterDelete(
+		self.positioned,
+		func(pv positionedVoice) bool {
+			if pv.voice.Done() || pv.voice.generation.Load() != pv.generation { return true }
+			pan, gain := self.spatialize(pv.x, pv.y)
+			pv.panner.SetPan(pan)
+			pv.panner.SetGain(gain)
+			return false
+		},
+	)
+
+	self.updateMusicClock()
+
+	// stop and clean up faded out bgms
 	self.fadingOutBGMs = utils.IterDelete(
 		self.fadingOutBGMs,
-		func(bgm *BGM) bool { return bgm.FullyFadedOut() },
+		func(bgm *BGM) bool {
+			if !bgm.FullyFadedOut() { return false }
+			bgm.Pause()
+			return true
+		},
 	)
 
 	// ...
@@ -0,0 +1,46 @@
+package audio_test
+
+import "time"
+import "testing"
+
+import "github.com/tinne26/transition/src/audio"
+import "github.com/tinne26/transition/src/audio/audiotest"
+
+func TestSoundscapeCrossfade(t *testing.T) {
+	const Level = 0.5 // below the limiter knee
+	soundscape := audio.NewSoundscape()
+	soundscape.SetUserBGMVolume(1.0)
+	outgoing := audio.NewBgmFromFader(audio.NewFader(audiotest.NewConstSource(Level, 0)))
+	incoming := audio.NewBgmFromFader(audio.NewFader(audiotest.NewConstSource(0, Level)))
+	outKey := soundscape.RegisterBGM(outgoing)
+	inKey := soundscape.RegisterBGM(incoming)
+	tempo := audio.Tempo{ BPM: 120, BeatsPerBar: 4 }
+	incoming.SetTempo(tempo)
+
+	// start the outgoing BGM and let the bus gain settle
+	soundscape.FadeIn(outKey, 0, 0, 0)
+	_, err := audio.RenderTicks(soundscape.Mixer(), 6, 60, soundscape.Update)
+	if err != nil { t.Fatal(err) }
+	if soundscape.MusicClock().IsRunning() { t.Fatal("music clock running for a BGM without tempo") }
+
+	fadeOut, inWait, fadeIn := time.Millisecond*500, time.Millisecond*250, time.Millisecond*500
+	soundscape.Crossfade(inKey, fadeOut, inWait, fadeIn)
+	samples, err := audio.RenderTicks(soundscape.Mixer(), 90, 60, soundscape.Update)
+	if err != nil { t.Fatal(err) }
+	audiotest.AssertCrossfade(t, samples, fadeOut, inWait, inWait + fadeIn, Level, 0.01)
+
+	incomingEnvelope := audiotest.NewEnvelope(samples, audiotest.Right, testWindow)
+	audiotest.AssertLinearFade(t, incomingEnvelope, inWait, inWait + fadeIn, 0, Level, 0.02)
+	outgoingEnvelope := audiotest.NewEnvelope(samples, audiotest.Left, testWindow)
+	audiotest.AssertLinearFade(t, outgoingEnvelope, 0, fadeOut, Level, 0, 0.02)
+
+	// the faded out BGM must be paused by Update()
+	if outgoing.IsPlaying() { t.Fatal("outgoing BGM still playing after fading out") }
+	if !incoming.IsPlaying() { t.Fatal("incoming BGM not playing") }
+
+	// the clock follows the incoming BGM since the crossfade started,
+	// but the source only advances after the initial wait
+	clock := soundscape.MusicClock()
+	if !clock.IsRunning() || clock.Tempo() != tempo { t.Fatal("music clock not following the incoming BGM") }
+	if clock.Beat() != 2 { t.Fatalf("music clock at beat %d, expected 2", clock.Beat()) }
+}
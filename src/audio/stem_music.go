@@ -0,0 +1,142 @@
+package audio
+
+import "sync"
+import "time"
+
+import "github.com/tinne26/edau"
+
+var _ StereoProcessor = (*StemMusic)(nil)
+
+// Default duration of layer gain transitions.
+const DefaultStemFadeDuration = time.Millisecond*200
+
+// Adaptive music made of multiple synchronized loop layers (stems).
+// Each layer has its own fader, and the layer gains are driven by
+// named parameters that gameplay code can update each tick (see
+// Soundscape.SetMusicParam()).
+//
+// All layers are always read together, even when silent, so they
+// never drift apart. Parameter changes are only applied at the start
+// of a WriteStereo() call, and to all the layers at once, so layer
+// transitions are also sample-aligned.
+type StemMusic struct {
+	mutex sync.Mutex
+	adder *Adder
+	layers []stemLayer
+	params map[string]float32
+	fadeSamples int64
+	dirty bool
+}
+
+type stemLayer struct {
+	fader *Fader
+	param string // empty for layers that are always on
+	low, high float32
+	gain float32 // last target gain given to the fader
+}
+
+func NewStemMusic() *StemMusic {
+	return &StemMusic{
+		adder: NewAdder(),
+		params: make(map[string]float32, 2),
+		fadeSamples: TimeDurationToSamples(DefaultStemFadeDuration),
+	}
+}
+
+// Adds a layer that's always on. Typically used for the base stem.
+// Layers must be added before the music starts playing.
+func (self *StemMusic) AddBaseLayer(loop *edau.Looper) {
+	self.addLayer(loop, "", 0, 0)
+}
+
+// Adds a layer whose gain is controlled by the given parameter. The
+// gain is 0 when the parameter is at or below low, 1 at or above high,
+// and linearly interpolated in between. Parameters start at 0.
+func (self *StemMusic) AddLayer(loop *edau.Looper, param string, low, high float32) {
+	if param == "" { panic("empty stem param name") }
+	if high <= low { panic("high <= low") }
+	self.addLayer(loop, param, low, high)
+}
+
+func (self *StemMusic) addLayer(loop *edau.Looper, param string, low, high float32) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+
+	// keep new layers aligned with the existing ones
+	source := NewProcessorInAdapterL16(loop)
+	if len(self.layers) > 0 {
+		err := source.SeekSample(int(self.layers[0].fader.GetPosition()))
+		if err != nil { panic(err) }
+	}
+
+	layer := stemLayer{ fader: NewFader(source), param: param, low: low, high: high }
+	layer.gain = layer.targetGain(self.params[param])
+	layer.fader.ForceVolume(layer.gain)
+	self.layers = append(self.layers, layer)
+	self.adder.Add(layer.fader)
+}
+
+func (self *StemMusic) NumLayers() int {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	return len(self.layers)
+}
+
+// Sets the duration of the layer transitions when a parameter changes.
+func (self *StemMusic) SetFadeDuration(duration time.Duration) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.fadeSamples = TimeDurationToSamples(duration)
+}
+
+// Sets the value of the given parameter. Parameters that no layer
+// uses are ignored. Setting the same value repeatedly is cheap, so
+// this can be called every tick.
+func (self *StemMusic) SetParam(name string, value float32) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if current, found := self.params[name]; found && current == value { return }
+	self.params[name] = value
+	self.dirty = true
+}
+
+func (self *StemMusic) GetParam(name string) float32 {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	return self.params[name]
+}
+
+func (self *StemMusic) WriteStereo(buffer []float32) (int, error) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if self.dirty {
+		for i, _ := range self.layers {
+			layer := &self.layers[i]
+			gain := layer.targetGain(self.params[layer.param])
+			if gain == layer.gain { continue }
+			layer.gain = gain
+			layer.fader.Transition(gain, 0, self.fadeSamples)
+		}
+		self.dirty = false
+	}
+	return self.adder.WriteStereo(buffer)
+}
+
+func (self *StemMusic) SeekSample(n int) error {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	return self.adder.SeekSample(n)
+}
+
+func (self *StemMusic) GetPosition() int64 {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	return self.adder.GetPosition()
+}
+
+func (self *stemLayer) targetGain(value float32) float32 {
+	if self.param == "" { return 1.0 }
+	if value <= self.low  { return 0.0 }
+	if value >= self.high { return 1.0 }
+	return (value - self.low)/(self.high - self.low)
+}
@@ -0,0 +1,160 @@
+package audio
+
+import "strconv"
+
+// What to do when a sound effect is played but the voice limits
+// (per sound effect or global) have already been reached.
+type StealPolicy uint8
+const (
+	StealNone StealPolicy = iota // drop the new sound
+	StealOldest // stop the voice that started first
+	StealQuietest // stop the voice with the lowest current level
+)
+
+func (self StealPolicy) String() string {
+	switch self {
+	case StealNone: return "none"
+	case StealOldest: return "oldest"
+	case StealQuietest: return "quietest"
+	default:
+		return "StealPolicy#" + strconv.Itoa(int(self))
+	}
+}
+
+// Default global limit of simultaneous sound effect voices.
+const DefaultMaxVoices = 24
+
+// The voice pool keeps track of all the sound effect voices currently
+// playing, enforces the polyphony limits and recycles finished voices.
+// Stolen voices are faded out very quickly instead of being cut, and
+// don't count towards the limits while they fade.
+//
+// The pool is only used from the game thread. Voices are recycled
+// once the audio thread releases them (see Adder), so Collect() must
+// be called periodically (Soundscape.Update() does that).
+type VoicePool struct {
+	maxVoices int
+	policy StealPolicy
+	active []*sfxVoice
+	free []*sfxVoice
+}
+
+func NewVoicePool(maxVoices int, policy StealPolicy) *VoicePool {
+	if maxVoices <= 0 { panic("maxVoices <= 0") }
+	return &VoicePool{
+		maxVoices: maxVoices,
+		policy: policy,
+		active: make([]*sfxVoice, 0, maxVoices),
+	}
+}
+
+func (self *VoicePool) SetMaxVoices(maxVoices int) {
+	if maxVoices <= 0 { panic("maxVoices <= 0") }
+	self.maxVoices = maxVoices
+}
+
+func (self *VoicePool) MaxVoices() int {
+	return self.maxVoices
+}
+
+// Sets the policy used when the global voice limit is reached.
+// Per sound effect limits use the sound effect's own policy.
+func (self *VoicePool) SetStealPolicy(policy StealPolicy) {
+	self.policy = policy
+}
+
+func (self *VoicePool) StealPolicy() StealPolicy {
+	return self.policy
+}
+
+// Returns the number of voices playing, excluding stolen
+// voices that are still fading out.
+func (self *VoicePool) NumPlaying() int {
+	return self.countPlaying(nil)
+}
+
+// Moves released voices to the free list.
+func (self *VoicePool) Collect() {
+	for i := 0; i < len(self.active); {
+		voice := self.active[i]
+		if voice.isReleased() {
+			voice.owner = nil
+			self.active = append(self.active[ : i], self.active[i + 1 : ]...)
+			self.free = append(self.free, voice)
+			continue
+		}
+		i += 1
+	}
+}
+
+// Returns a voice for the given sound effect, stealing other voices
+// if necessary. Returns nil if the limits don't allow the sound to play.
+func (self *VoicePool) acquire(sfx *SfxPlayer, samples []float32, tick uint64) *sfxVoice {
+	// per sound effect limit
+	if sfx.maxVoices > 0 && self.countPlaying(sfx) >= sfx.maxVoices {
+		victim := self.selectVictim(sfx, sfx.policy)
+		if victim == nil { return nil }
+		victim.stop()
+	}
+
+	// global limit
+	if self.countPlaying(nil) >= self.maxVoices {
+		victim := self.selectVictim(nil, self.policy)
+		if victim == nil { return nil }
+		victim.stop()
+	}
+
+	// get a recycled voice or create a new one
+	var voice *sfxVoice
+	if len(self.free) > 0 {
+		last := len(self.free) - 1
+		voice = self.free[last]
+		self.free = self.free[ : last]
+		voice.reset(samples, sfx.volumeCorrectorFactor)
+	} else {
+		voice = newSfxVoice(samples, sfx.volumeCorrectorFactor)
+	}
+	voice.owner = sfx
+	voice.startTick = tick
+	self.active = append(self.active, voice)
+	return voice
+}
+
+// Counts the playing voices owned by the given sound effect,
+// or all playing voices if the sound effect is nil.
+func (self *VoicePool) countPlaying(owner *SfxPlayer) int {
+	var count int
+	for _, voice := range self.active {
+		if voice.isStopping() || voice.Done() { continue }
+		if owner != nil && voice.owner != owner { continue }
+		count += 1
+	}
+	return count
+}
+
+// Returns the voice to steal among the playing voices owned by the
+// given sound effect (or all of them if nil), or nil if the policy
+// doesn't allow stealing.
+func (self *VoicePool) selectVictim(owner *SfxPlayer, policy StealPolicy) *sfxVoice {
+	var victim *sfxVoice
+	for _, voice := range self.active {
+		if voice.isStopping() || voice.Done() { continue }
+		if owner != nil && voice.owner != owner { continue }
+		if victim == nil {
+			victim = voice
+			continue
+		}
+		switch policy {
+		case StealNone:
+			return nil
+		case StealOldest:
+			if voice.startTick < victim.startTick { victim = voice }
+		case StealQuietest:
+			if voice.Level() < victim.Level() { victim = voice }
+		default:
+			panic(policy)
+		}
+	}
+	if policy == StealNone { return nil }
+	return victim
+}
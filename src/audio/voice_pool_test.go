@@ -0,0 +1,124 @@
+package audio_test
+
+import "time"
+import "testing"
+
+import "github.com/tinne26/transition/src/audio"
+import "github.com/tinne26/transition/src/audio/audiotest"
+
+// Creates a sound effect with consecutive constant segments
+// of the given levels, on both channels.
+func newSegmentsSfx(t *testing.T, segment time.Duration, levels ...float32) *audio.SfxPlayer {
+	t.Helper()
+	var bytes []byte
+	for _, level := range levels {
+		samples, err := audio.Render(audiotest.NewConstSource(level, level), int(audio.TimeDurationToSamples(segment)))
+		if err != nil { t.Fatal(err) }
+		for i := 0; i < len(samples); i += 2 {
+			var sample [4]byte
+			audio.StoreNormF32StereoSampleAsL16(sample[:], samples[i], samples[i + 1])
+			bytes = append(bytes, sample[:]...)
+		}
+	}
+	return audio.NewSfxPlayer(bytes)
+}
+
+// Renders the soundscape tick by tick, playing the sound
+// effect at the given ticks.
+func renderPlays(t *testing.T, soundscape *audio.Soundscape, key audio.SfxKey, numTicks int, playTicks ...int) []float32 {
+	t.Helper()
+	var tick int
+	samples, err := audio.RenderTicks(soundscape.Mixer(), numTicks, 60, func() error {
+		err := soundscape.Update()
+		if err != nil { return err }
+		for _, playTick := range playTicks {
+			if playTick == tick { soundscape.PlaySFX(key) }
+		}
+		tick += 1
+		return nil
+	})
+	if err != nil { t.Fatal(err) }
+	return samples
+}
+
+func TestVoicePoolStealing(t *testing.T) {
+	// Three plays 100ms apart with a limit of two voices. The sound
+	// gets louder on its second segment, so when the third play comes
+	// the first voice is the oldest and the second voice the quietest.
+	// At 250ms the voices are on the segments 3, 2 and 1, so the mix
+	// level tells which of them is missing.
+	const Segment = time.Millisecond*100
+	tests := []struct{
+		name string
+		perSfx bool
+		policy audio.StealPolicy
+		expected float32
+	}{
+		{ "sfx none", true, audio.StealNone, 0.10 + 0.15 },
+		{ "sfx oldest", true, audio.StealOldest, 0.15 + 0.05 },
+		{ "sfx quietest", true, audio.StealQuietest, 0.10 + 0.05 },
+		{ "global none", false, audio.StealNone, 0.10 + 0.15 },
+		{ "global oldest", false, audio.StealOldest, 0.15 + 0.05 },
+		{ "global quietest", false, audio.StealQuietest, 0.10 + 0.05 },
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			soundscape := audio.NewSoundscape()
+			soundscape.SetUserSFXVolume(1.0)
+			sfx := newSegmentsSfx(t, Segment, 0.05, 0.15, 0.10, 0.10)
+			if test.perSfx {
+				sfx.SetPolyphony(2, test.policy)
+			} else {
+				sfx.SetPolyphony(0, audio.StealNone)
+				soundscape.VoicePool().SetMaxVoices(2)
+				soundscape.VoicePool().SetStealPolicy(test.policy)
+			}
+			key := soundscape.RegisterSFX(sfx)
+			_ = renderPlays(t, soundscape, key, 6) // let the bus gain settle
+
+			samples := renderPlays(t, soundscape, key, 18, 0, 6, 12)
+			if soundscape.VoicePool().NumPlaying() != 2 {
+				t.Fatalf("%d voices playing, expected 2", soundscape.VoicePool().NumPlaying())
+			}
+			envelope := audiotest.NewEnvelope(samples, audiotest.Both, testWindow)
+			audiotest.AssertLevelAt(t, envelope, Segment*2 + Segment/2, test.expected, 0.005)
+		})
+	}
+}
+
+func TestVoicePoolLimitsPerSfx(t *testing.T) {
+	// the per sound effect limit only counts the effect's own voices
+	soundscape := audio.NewSoundscape()
+	first := newSegmentsSfx(t, time.Millisecond*100, 0.1)
+	second := newSegmentsSfx(t, time.Millisecond*100, 0.1)
+	first.SetPolyphony(1, audio.StealNone)
+	second.SetPolyphony(1, audio.StealNone)
+	firstKey, secondKey := soundscape.RegisterSFX(first), soundscape.RegisterSFX(second)
+	soundscape.PlaySFX(firstKey)
+	soundscape.PlaySFX(firstKey)
+	soundscape.PlaySFX(secondKey)
+	if soundscape.VoicePool().NumPlaying() != 2 {
+		t.Fatalf("%d voices playing, expected 2", soundscape.VoicePool().NumPlaying())
+	}
+}
+
+func TestVoicePoolCollect(t *testing.T) {
+	soundscape := audio.NewSoundscape()
+	pool := soundscape.VoicePool()
+	key := soundscape.RegisterSFX(newSegmentsSfx(t, time.Millisecond*100, 0.1))
+	_ = renderPlays(t, soundscape, key, 3, 0, 1, 2)
+	if pool.NumPlaying() != 3 { t.Fatalf("%d voices playing, expected 3", pool.NumPlaying()) }
+	if audio.NumFreeVoices(pool) != 0 { t.Fatal("voices freed while playing") }
+
+	// finished voices are released by the bus and then collected
+	_ = renderPlays(t, soundscape, key, 8)
+	if pool.NumPlaying() != 0 { t.Fatalf("%d voices playing, expected 0", pool.NumPlaying()) }
+	if audio.NumFreeVoices(pool) != 3 { t.Fatalf("%d free voices, expected 3", audio.NumFreeVoices(pool)) }
+
+	// and then reused
+	soundscape.PlaySFX(key)
+	if pool.NumPlaying() != 1 || audio.NumFreeVoices(pool) != 2 {
+		t.Fatalf("%d voices playing and %d free, expected 1 and 2", pool.NumPlaying(), audio.NumFreeVoices(pool))
+	}
+}
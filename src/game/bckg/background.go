@@ -1,11 +1,11 @@
 package bckg
 
-import "math/rand"
 import "image/color"
 
 import "github.com/hajimehoshi/ebiten/v2"
 
 import "github.com/tinne26/transition/src/utils"
+import "github.com/tinne26/transition/src/rng"
 
 type Background struct {
 	canvas *ebiten.Image
@@ -68,7 +68,7 @@ func (self *Background) Update() error {
 		if !self.activeCells[i].IsAlive() {
 			self.activeCells[i].ReRoll(
 				self.masks.Roll(),
-				self.maskColors[rand.Intn(len(self.maskColors))],
+				self.maskColors[rng.Main.Intn(len(self.maskColors))],
 			)
 		}
 	}
@@ -174,8 +174,8 @@ func (self *Cell) ReRoll(mask *ebiten.Image, clr color.RGBA) {
 	}
 
 	// determine x and y
-	self.x = rand.Float64()*(640 + size) - size/2
-	self.y = probFunc(rand.Float64())*(360 + size) - size/2
+	self.x = rng.Main.Float64()*(640 + size) - size/2
+	self.y = probFunc(rng.Main.Float64())*(360 + size) - size/2
 
 	// snap x and y to pixel grid
 	self.x = utils.FastFloor(self.x)
@@ -184,7 +184,7 @@ func (self *Cell) ReRoll(mask *ebiten.Image, clr color.RGBA) {
 	// determine alpha rate change
 	const MinAlphaChange = MaxAlpha/(4.0*60.0)
 	const MaxAlphaChange = MaxAlpha/(1.0*60.0)
-	self.alphaChange = MinAlphaChange + rand.Float32()*(MaxAlphaChange - MinAlphaChange)
+	self.alphaChange = MinAlphaChange + rng.Main.Float32()*(MaxAlphaChange - MinAlphaChange)
 
 	// make a random color with the desired parameters
 	self.r, self.g, self.b, _ = utils.RGBA8ToRGBAf32(clr)
@@ -1,11 +1,11 @@
 package bckg
 
-import "math/rand"
 import "image"
 
 import "github.com/hajimehoshi/ebiten/v2"
 
 import "github.com/tinne26/transition/src/utils"
+import "github.com/tinne26/transition/src/rng"
 
 type Darkener struct {
 	currentY float64
@@ -22,7 +22,7 @@ func NewDarkener(minY float64, maxY float64, alpha float32, speed float64) Darke
 		currentY: minY,
 		minY: minY,
 		maxY: maxY,
-		targetY: minY + rand.Float64()*(maxY - minY),
+		targetY: minY + rng.Main.Float64()*(maxY - minY),
 		alpha: alpha,
 		speed: speed,
 	}
@@ -47,7 +47,7 @@ func (self *Darkener) Update() {
 	}
 
 	if rerollY {
-		self.targetY = self.minY + rand.Float64()*(self.maxY - self.minY)
+		self.targetY = self.minY + rng.Main.Float64()*(self.maxY - self.minY)
 	}
 }
 
@@ -1,10 +1,9 @@
 package bckg
 
-import "math/rand"
-
 import "github.com/hajimehoshi/ebiten/v2"
 
 import "github.com/tinne26/transition/src/utils"
+import "github.com/tinne26/transition/src/rng"
 
 type WeightedMaskList struct {
 	list []WeightedMask
@@ -26,8 +25,18 @@ func (self *WeightedMaskList) Add(mask *ebiten.Image, prob float64) {
 	self.list = append(self.list, WeightedMask{ Mask: mask, Probability: prob })
 }
 
+func (self *WeightedMaskList) Len() int {
+	return len(self.list)
+}
+
+func (self *WeightedMaskList) Each(fn func(mask *ebiten.Image, prob float64)) {
+	for _, entry := range self.list {
+		fn(entry.Mask, entry.Probability)
+	}
+}
+
 func (self *WeightedMaskList) Roll() *ebiten.Image {
-	cutoff := rand.Float64()*self.totalProb
+	cutoff := rng.Main.Float64()*self.totalProb
 	for i := 0; i < len(self.list); i++ {
 		cutoff -= self.list[i].Probability
 		if cutoff <= 0 { return self.list[i].Mask }
@@ -7,3 +7,28 @@ var WingsDark = color.RGBA{ 51, 126,  96, 255}
 var HornsText = color.RGBA{255, 120,  71, 255}
 var Dark = color.RGBA{20, 20, 20, 255}
 var Permanence = color.RGBA{244, 124, 200, 255}
+
+// Palette colors by name, as used by text markup color tags
+// (e.g. "{color:horns}"), dialogue speakers and level files.
+var palette = map[string]*color.RGBA{
+	"wings": &WingsText,
+	"wings_dark": &WingsDark,
+	"horns": &HornsText,
+	"dark": &Dark,
+	"permanence": &Permanence,
+}
+
+// Returns the palette color with the given name.
+func ByName(name string) (color.RGBA, bool) {
+	rgba, found := palette[name]
+	if !found { return color.RGBA{}, false }
+	return *rgba, true
+}
+
+// Returns the palette name of the given color.
+func NameOf(rgba color.RGBA) (string, bool) {
+	for name, candidate := range palette {
+		if *candidate == rgba { return name, true }
+	}
+	return "", false
+}
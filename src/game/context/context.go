@@ -12,18 +12,28 @@ type Context struct {
 	Audio *audio.Soundscape
 }
 
-func NewContext(filesys fs.FS) (*Context, error) {
+func NewContext(filesys fs.FS, bindings *input.Bindings) (*Context, error) {
 	soundscape := audio.NewSoundscape()
 	err := audio.Initialize(soundscape, filesys)
 	if err != nil { return nil, err }
 
 	return &Context{
 		State: state.New(),
-		Input: input.NewInput(input.StdKeyboardMappingWASD, input.StdGamepadMapping),
+		Input: input.NewInputFromSource(input.NewDeviceSourceFromBindings(bindings)),
 		Audio: soundscape,
 	}, nil
 }
 
+// Creates a context without audio nor real input devices, driven
+// by the given input source instead. Used for headless simulations.
+func NewHeadlessContext(source input.Source) *Context {
+	return &Context{
+		State: state.New(),
+		Input: input.NewInputFromSource(source),
+		Audio: audio.NewMutedSoundscape(),
+	}
+}
+
 func (self *Context) Update() error {
 	var err error
 
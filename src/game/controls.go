@@ -0,0 +1,110 @@
+package game
+
+import "os"
+import "bytes"
+import "errors"
+import "io/fs"
+import "path/filepath"
+
+import "github.com/tinne26/transition/src/debug"
+import "github.com/tinne26/transition/src/input"
+import "github.com/tinne26/transition/src/utils"
+import "github.com/tinne26/transition/src/text"
+import "github.com/tinne26/transition/src/game/savegame"
+
+const controlsFileName = "controls.cfg"
+
+// Key glyphs and the actions whose keys they must show.
+var keyGlyphActions = map[rune]input.Action{
+	text.KeyA: input.ActionMoveLeft,
+	text.KeyD: input.ActionMoveRight,
+	text.KeyK: input.ActionJump,
+	text.KeyI: input.ActionInteract,
+	text.KeyMsgI: input.ActionInteract,
+	text.KeyO: input.ActionOutReverse,
+}
+
+// Loads the controls from the config file, falling back to the
+// default presets (which can be changed with --keyboard <preset>
+// and --gamepad <preset>). If the config file doesn't exist yet,
+// it's created so players can edit it. If it can't be read or has
+// errors, the defaults are used instead.
+func loadBindings() (*input.Bindings, error) {
+	keyboardPreset, hasKeyboardArg := utils.OsArgValue("--keyboard")
+	if !hasKeyboardArg { keyboardPreset = input.DefaultKeyboardPreset }
+	gamepadPreset, hasGamepadArg := utils.OsArgValue("--gamepad")
+	if !hasGamepadArg { gamepadPreset = input.DefaultGamepadPreset }
+	defaults, err := input.NewBindingsFromPresets(keyboardPreset, gamepadPreset)
+	if err != nil { return nil, err }
+	if hasKeyboardArg || hasGamepadArg {
+		return defaults, nil // explicit presets take priority over the config
+	}
+
+	path, err := controlsFilePath()
+	if err != nil {
+		debug.Tracef("Controls config unavailable: %s\n", err.Error())
+		return defaults, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = saveBindings(defaults)
+		if err != nil { debug.Printf("Failed to write controls config: %s\n", err.Error()) }
+		return defaults, nil
+	}
+	var bindings *input.Bindings
+	if err == nil {
+		bindings, err = input.ReadBindings(bytes.NewReader(data), defaults)
+		if err == nil { return bindings, nil }
+	}
+
+	// a broken config shouldn't prevent the game from starting
+	debug.Printf("Failed to read controls config, using defaults: %s\n", err.Error())
+	return defaults, nil
+}
+
+func saveBindings(bindings *input.Bindings) error {
+	path, err := controlsFilePath()
+	if err != nil { return err }
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil { return err }
+	var buffer bytes.Buffer
+	_, err = bindings.WriteTo(&buffer)
+	if err != nil { return err }
+	return os.WriteFile(path, buffer.Bytes(), 0o644)
+}
+
+func controlsFilePath() (string, error) {
+	appDir, err := savegame.AppDir()
+	if err != nil { return "", err }
+	return filepath.Join(appDir, controlsFileName), nil
+}
+
+// Updates the key glyphs so they show the primary key of each action.
+func refreshKeyGlyphs(bindings *input.Bindings) {
+	for glyph, action := range keyGlyphActions {
+		text.SetKeyGlyphLabel(glyph, primaryKeyLabel(bindings, action))
+	}
+	for action := input.Action(0); int(action) < input.NumActions; action++ {
+		text.SetActionKeyLabel(action, primaryKeyLabel(bindings, action))
+	}
+}
+
+func primaryKeyLabel(bindings *input.Bindings, action input.Action) string {
+	keys := bindings.Keys(action)
+	if len(keys) == 0 { return "?" }
+	return input.KeyLabel(keys[0])
+}
+
+// Replaces the current controls, updates the key glyphs and
+// saves the bindings to the controls config file.
+func (self *Game) SetBindings(bindings *input.Bindings) error {
+	self.devices.SetBindings(bindings)
+	refreshKeyGlyphs(bindings)
+	return saveBindings(bindings)
+}
+
+// Returns a copy of the current controls. Use SetBindings()
+// to apply any changes.
+func (self *Game) Bindings() *input.Bindings {
+	return self.devices.Bindings().Clone()
+}
@@ -0,0 +1,88 @@
+package game
+
+import "image"
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+import "github.com/tinne26/transition/src/lang"
+import "github.com/tinne26/transition/src/debug"
+import "github.com/tinne26/transition/src/input"
+import "github.com/tinne26/transition/src/text"
+import "github.com/tinne26/transition/src/game/clr"
+
+const deviceNoticeTicks = 150
+const deviceNoticeMaxLines = 2
+
+// Small on-screen notice shown at the top right corner when gamepads
+// are connected or disconnected, or when the active device changes.
+type DeviceNotice struct {
+	lines []string
+	ticksLeft int
+}
+
+func NewDeviceNotice() *DeviceNotice {
+	return &DeviceNotice{}
+}
+
+func (self *DeviceNotice) Show(line string) {
+	if self.ticksLeft == 0 { self.lines = self.lines[ : 0] }
+	self.lines = append(self.lines, line)
+	if len(self.lines) > deviceNoticeMaxLines {
+		self.lines = self.lines[len(self.lines) - deviceNoticeMaxLines : ]
+	}
+	self.ticksLeft = deviceNoticeTicks
+}
+
+func (self *DeviceNotice) Update() {
+	if self.ticksLeft > 0 { self.ticksLeft -= 1 }
+}
+
+func (self *DeviceNotice) IsVisible() bool {
+	return self.ticksLeft > 0
+}
+
+// Must be drawn on the logical canvas.
+func (self *DeviceNotice) Draw(canvas *ebiten.Image) {
+	if !self.IsVisible() { return }
+
+	width := 0
+	for _, line := range self.lines {
+		lineWidth := text.MeasureLineWidth(line)
+		if lineWidth > width { width = lineWidth }
+	}
+	height := len(self.lines)*text.LineHeight + (len(self.lines) - 1)*text.LineInterspace
+	
+	maxX, minY := canvas.Bounds().Dx() - 8, 8
+	minX := maxX - width - 12
+	box := image.Rect(minX, minY, maxX, minY + height + 12)
+	canvas.SubImage(box).(*ebiten.Image).Fill(text.FrontColor)
+	canvas.SubImage(box.Inset(1)).(*ebiten.Image).Fill(text.BackColor)
+	y := minY + 6
+	for _, line := range self.lines {
+		text.DrawLine(canvas, line, minX + 6, y, clr.WingsText)
+		y += text.LineHeight + text.LineInterspace
+	}
+}
+
+func (self *Game) onDeviceEvent(event input.DeviceEvent) {
+	switch event.Type {
+	case input.EventGamepadConnected:
+		debug.Tracef("Gamepad connected: %s (GUID %s)\n", event.GamepadName, event.GamepadGUID)
+		if event.Supported {
+			self.deviceNotice.Show(lang.Plural("device.gamepads_connected", self.devices.NumGamepads()))
+		} else {
+			self.deviceNotice.Show("@device.unsupported_gamepad")
+		}
+	case input.EventGamepadDisconnected:
+		debug.Tracef("Gamepad disconnected\n")
+		self.deviceNotice.Show("@device.gamepad_disconnected")
+	case input.EventActiveDeviceChanged:
+		if event.Device == input.DeviceGamepad {
+			self.deviceNotice.Show("@device.using_gamepad")
+		} else {
+			self.deviceNotice.Show("@device.using_keyboard")
+		}
+	default:
+		panic(event.Type)
+	}
+}
@@ -0,0 +1,191 @@
+package dialogue
+
+import "errors"
+import "strings"
+
+import "github.com/tinne26/transition/src/text"
+import "github.com/tinne26/transition/src/input"
+import "github.com/tinne26/transition/src/audio"
+import "github.com/tinne26/transition/src/game/context"
+import "github.com/tinne26/transition/src/game/clr"
+
+// Typewriter reveal speed, and how often blips are played
+// for speakers that have them.
+const RevealTicksPerGlyph = 2
+const blipGlyphInterval = 3
+
+// Max number of statements run without showing anything. Scripts
+// that jump around in a loop without pausing hit this limit.
+const maxStepsWithoutPause = 256
+
+// Text color for choice menus (the choices are the player's).
+var ChoiceColor = clr.WingsText
+
+// A running dialogue. Created with Script.Start(), and then updated
+// each tick until IsOver(). The dialogue doesn't draw anything on
+// its own, CurrentText() must be drawn instead.
+type Dialogue struct {
+	script *Script
+	node *scriptNode
+	stepIndex int
+	isOver bool
+
+	// say statements
+	msg *text.Message
+	speaker *Speaker
+	revealed int
+	revealTicks int
+
+	// choice menus
+	choices []*scriptStep // only those available
+	selected int
+}
+
+// Starts a dialogue for the script. Statements are run until the
+// first message or choice menu, so switches may be modified right
+// away. The dialogue may also be over already if nothing is shown.
+func (self *Script) Start(ctx *context.Context) (*Dialogue, error) {
+	dialogue := &Dialogue{ script: self, node: self.start }
+	err := dialogue.run(ctx)
+	if err != nil { return nil, err }
+	return dialogue, nil
+}
+
+func (self *Dialogue) IsOver() bool {
+	return self.isOver
+}
+
+// Returns the message to show, or nil if the dialogue is over.
+func (self *Dialogue) CurrentText() *text.Message {
+	if self.isOver { return nil }
+	return self.msg
+}
+
+func (self *Dialogue) Update(ctx *context.Context) error {
+	if self.isOver { return nil }
+	if self.choices != nil { return self.updateChoices(ctx) }
+
+	// typewriter reveal, can be skipped with interact
+	if !self.msg.IsFullyRevealed() {
+		if ctx.Input.Trigger(input.ActionInteract) {
+			self.msg.RevealAll()
+			return nil
+		}
+		self.revealTicks += 1
+		if self.revealTicks >= RevealTicksPerGlyph {
+			self.revealTicks = 0
+			self.revealed += 1
+			self.msg.SetRevealedGlyphs(self.revealed)
+			if self.speaker.Blip && self.revealed % blipGlyphInterval == 1 {
+				ctx.Audio.PlaySFX(audio.SfxBlip)
+			}
+		}
+		return nil
+	}
+
+	// next page or statement
+	if !ctx.Input.Trigger(input.ActionInteract) { return nil }
+	ctx.Audio.PlaySFX(audio.SfxInteract)
+	if self.msg.NextPage() {
+		self.startReveal()
+		return nil
+	}
+	return self.run(ctx)
+}
+
+func (self *Dialogue) updateChoices(ctx *context.Context) error {
+	prevSelected := self.selected
+	if ctx.Input.Trigger(input.ActionUp) { self.selected -= 1 }
+	if ctx.Input.Trigger(input.ActionDown) { self.selected += 1 }
+	if self.selected < 0 { self.selected = len(self.choices) - 1 }
+	if self.selected >= len(self.choices) { self.selected = 0 }
+	if self.selected != prevSelected {
+		ctx.Audio.PlaySFX(audio.SfxBlip)
+		self.msg = self.newChoicesMsg()
+	}
+
+	if !ctx.Input.Trigger(input.ActionInteract) { return nil }
+	ctx.Audio.PlaySFX(audio.SfxInteract)
+	self.jump(self.choices[self.selected].target)
+	return self.run(ctx)
+}
+
+// Runs statements until something has to be shown or the
+// dialogue is over.
+func (self *Dialogue) run(ctx *context.Context) error {
+	self.msg, self.speaker, self.choices = nil, nil, nil
+	for numSteps := 0; numSteps < maxStepsWithoutPause; numSteps++ {
+		if self.stepIndex >= len(self.node.steps) {
+			self.isOver = true
+			return nil
+		}
+		step := &self.node.steps[self.stepIndex]
+		self.stepIndex += 1
+
+		switch step.kind {
+		case stepSay:
+			self.speaker = step.speaker
+			self.msg = text.NewDialogueWrappedMsg(step.text, step.speaker.Color)
+			self.msg.Speaker = step.speaker.Name
+			self.startReveal()
+			return nil
+		case stepChoice:
+			self.openChoices(ctx)
+			if self.choices == nil { // none available
+				self.isOver = true
+			}
+			return nil
+		case stepSet:
+			ctx.State.Switches[step.switchKey] = step.switchValue
+		case stepGoto:
+			if step.cond.isMet(ctx.State) { self.jump(step.target) }
+		case stepEnd:
+			self.isOver = true
+			return nil
+		default:
+			panic(step.kind)
+		}
+	}
+	return errors.New("dialogue: script '" + self.script.name + "' loops without showing anything")
+}
+
+func (self *Dialogue) jump(node *scriptNode) {
+	self.node = node
+	self.stepIndex = 0
+}
+
+func (self *Dialogue) startReveal() {
+	self.revealed, self.revealTicks = 0, 0
+	self.msg.SetRevealedGlyphs(0)
+}
+
+// Collects the available choices of the menu starting at the
+// step that was just run.
+func (self *Dialogue) openChoices(ctx *context.Context) {
+	self.stepIndex -= 1
+	for self.stepIndex < len(self.node.steps) {
+		step := &self.node.steps[self.stepIndex]
+		if step.kind != stepChoice { break }
+		if step.cond.isMet(ctx.State) { self.choices = append(self.choices, step) }
+		self.stepIndex += 1
+	}
+	if self.choices == nil { return }
+	self.selected = 0
+	self.msg = self.newChoicesMsg()
+}
+
+func (self *Dialogue) newChoicesMsg() *text.Message {
+	var builder strings.Builder
+	for i, choice := range self.choices {
+		if i > 0 { builder.WriteByte('\n') }
+		if i == self.selected {
+			builder.WriteString("> ")
+		} else {
+			builder.WriteString("- ")
+		}
+		builder.WriteString(text.Localize(choice.text))
+	}
+	msg := text.NewDialogueWrappedMsg(builder.String(), ChoiceColor)
+	msg.DisablePaging()
+	return msg
+}
@@ -0,0 +1,104 @@
+package dialogue
+
+import "strings"
+import "testing"
+
+import "github.com/tinne26/transition/src/input"
+import "github.com/tinne26/transition/src/game/context"
+import "github.com/tinne26/transition/src/game/state"
+
+const testDialogueScript = `
+speaker a wings "A"
+node start
+	set tip_move
+	unset tip_jump
+	if tip_move goto menu
+	say a "NOT REACHED"
+node menu
+	say a "HELLO"
+	choice "ONE" done
+	choice "TWO" done if tip_jump
+	choice "THREE" three if !tip_jump
+node three
+	set sword_challenge_1
+	if sword_challenge_1 goto done
+	say a "NOT REACHED EITHER"
+node done
+	end
+`
+
+// Presses the action for one tick and releases it on the next
+// one, updating the dialogue on both ticks.
+func pressAndRelease(t *testing.T, ctx *context.Context, source *input.ScriptedSource, dialogue *Dialogue, action input.Action) {
+	t.Helper()
+	source.Hold(input.NewActionSet(action), 1)
+	source.Wait(1)
+	for !source.Done() {
+		err := ctx.Update()
+		if err != nil { t.Fatal(err) }
+		err = dialogue.Update(ctx)
+		if err != nil { t.Fatal(err) }
+	}
+}
+
+func assertMsgLines(t *testing.T, dialogue *Dialogue, expected ...string) {
+	t.Helper()
+	msg := dialogue.CurrentText()
+	if msg == nil { t.Fatalf("expected message %q, dialogue over", expected) }
+	lines := msg.Lines()
+	if strings.Join(lines, "|") != strings.Join(expected, "|") {
+		t.Fatalf("got message lines %q, expected %q", lines, expected)
+	}
+}
+
+func TestDialogueRun(t *testing.T) {
+	script, err := parseScript("test", strings.NewReader(testDialogueScript))
+	if err != nil { t.Fatal(err) }
+	source := input.NewScriptedSource()
+	ctx := context.NewHeadlessContext(source)
+	ctx.State.Switches[state.SwitchTipJump] = true
+
+	// set, unset and conditional goto run right away
+	dialogue, err := script.Start(ctx)
+	if err != nil { t.Fatal(err) }
+	if !ctx.State.Switches[state.SwitchTipMove] { t.Fatal("tip_move not set") }
+	if ctx.State.Switches[state.SwitchTipJump] { t.Fatal("tip_jump not unset") }
+	assertMsgLines(t, dialogue, "HELLO")
+	if dialogue.CurrentText().IsFullyRevealed() { t.Fatal("message revealed from the start") }
+
+	// the first interact reveals the message, the second one moves on
+	pressAndRelease(t, ctx, source, dialogue, input.ActionInteract)
+	assertMsgLines(t, dialogue, "HELLO")
+	if !dialogue.CurrentText().IsFullyRevealed() { t.Fatal("interact didn't reveal the message") }
+	pressAndRelease(t, ctx, source, dialogue, input.ActionInteract)
+
+	// "TWO" is filtered out, and the selection wraps around
+	assertMsgLines(t, dialogue, "> ONE", "- THREE")
+	pressAndRelease(t, ctx, source, dialogue, input.ActionUp)
+	assertMsgLines(t, dialogue, "- ONE", "> THREE")
+	pressAndRelease(t, ctx, source, dialogue, input.ActionDown)
+	assertMsgLines(t, dialogue, "> ONE", "- THREE")
+	pressAndRelease(t, ctx, source, dialogue, input.ActionDown)
+	assertMsgLines(t, dialogue, "- ONE", "> THREE")
+
+	pressAndRelease(t, ctx, source, dialogue, input.ActionInteract)
+	if !dialogue.IsOver() || dialogue.CurrentText() != nil { t.Fatal("dialogue not over after the last choice") }
+	if !ctx.State.Switches[state.SwitchSwordChallenge1] { t.Fatal("sword_challenge_1 not set") }
+}
+
+func TestDialogueNoChoicesAvailable(t *testing.T) {
+	script, err := parseScript("test", strings.NewReader("node start\nchoice \"ONE\" start if tip_move\n"))
+	if err != nil { t.Fatal(err) }
+	dialogue, err := script.Start(context.NewHeadlessContext(input.NewScriptedSource()))
+	if err != nil { t.Fatal(err) }
+	if !dialogue.IsOver() { t.Fatal("dialogue without available choices not over") }
+}
+
+func TestDialogueLoop(t *testing.T) {
+	script, err := parseScript("test", strings.NewReader("node a\nset tip_move\ngoto b\nnode b\ngoto a\n"))
+	if err != nil { t.Fatal(err) }
+	_, err = script.Start(context.NewHeadlessContext(input.NewScriptedSource()))
+	if err == nil || !strings.Contains(err.Error(), "loops without showing anything") {
+		t.Fatalf("expected loop error, got %v", err)
+	}
+}
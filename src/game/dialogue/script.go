@@ -0,0 +1,157 @@
+package dialogue
+
+import "io/fs"
+import "path"
+import "strings"
+import "image/color"
+
+import "github.com/tinne26/transition/src/game/state"
+
+// Dialogue scripts are loaded from text files in Dir, one script per
+// file, and referred to by their file name without extension (e.g.
+// "skeleton" for assets/dialogue/skeleton.txt).
+//
+// Script format, one statement per line:
+//   speaker <id> <color> "<name>" [blip]
+//   node <name>
+//     say <speaker_id> "<text>"
+//     choice "<text>" <node> [if <condition>]
+//     set <switch>
+//     unset <switch>
+//     goto <node>
+//     if <condition> goto <node>
+//     end
+// Colors are clr palette names (see clr.ByName()), and switches use
+// the names from state.Switch.String(). Conditions are switch names,
+// optionally negated with '!'. Texts can be localized references
+// ("@id", see the text package) and use markup. Quoted strings may
+// contain escaped \", \\ and \n. Comments start with '#'.
+//
+// Dialogues start at the first node. The statements of a node are run
+// in order: "say" shows a message and waits for the player to read it,
+// consecutive "choice" statements show a menu and jump to the chosen
+// node, and reaching the end of a node (or "end") finishes the dialogue.
+
+// Directory of the dialogue scripts in the assets filesystem.
+const Dir = "assets/dialogue"
+
+var pkgScripts = make(map[string]*Script)
+
+type Script struct {
+	name string
+	speakers map[string]*Speaker
+	nodes map[string]*scriptNode
+	start *scriptNode
+}
+
+type Speaker struct {
+	Name string // shown in the message box tab
+	Color color.RGBA
+	Blip bool // whether to play blips while revealing text
+}
+
+type scriptNode struct {
+	name string
+	steps []scriptStep
+}
+
+type stepKind uint8
+const (
+	stepSay stepKind = iota
+	stepChoice
+	stepSet
+	stepGoto
+	stepEnd
+)
+
+type scriptStep struct {
+	kind stepKind
+	speaker *Speaker // stepSay
+	text string // stepSay, stepChoice
+	targetName string // stepChoice, stepGoto
+	target *scriptNode // resolved from targetName after parsing
+	cond condition // stepChoice, stepGoto
+	switchKey state.Switch // stepSet
+	switchValue bool // stepSet
+}
+
+// A condition on a game state switch. The zero value is always met.
+type condition struct {
+	switchKey state.Switch
+	negated bool
+}
+
+func (self condition) isMet(gameState *state.State) bool {
+	if self.switchKey == state.SwitchNone { return true }
+	return gameState.Switches[self.switchKey] != self.negated
+}
+
+// Loads all the scripts in Dir. Scripts can then be retrieved
+// with Get() or Lookup().
+func LoadAll(filesys fs.FS) error {
+	entries, err := fs.ReadDir(filesys, Dir)
+	if err != nil { return err }
+	scripts := make(map[string]*Script, len(entries))
+	for _, entry := range entries {
+		filename := entry.Name()
+		if entry.IsDir() || path.Ext(filename) != ".txt" { continue }
+		script, err := LoadScript(filesys, path.Join(Dir, filename))
+		if err != nil { return err }
+		scripts[script.name] = script
+	}
+	pkgScripts = scripts
+	return nil
+}
+
+func LoadScript(filesys fs.FS, filename string) (*Script, error) {
+	file, err := filesys.Open(filename)
+	if err != nil { return nil, err }
+	defer file.Close()
+	name := strings.TrimSuffix(path.Base(filename), path.Ext(filename))
+	script, err := parseScript(name, file)
+	if err != nil { return nil, err }
+	return script, nil
+}
+
+// Returns the script with the given name. Panics if missing.
+func Get(name string) *Script {
+	script, found := pkgScripts[name]
+	if !found { panic("dialogue: missing script '" + name + "'") }
+	return script
+}
+
+func Lookup(name string) (*Script, bool) {
+	script, found := pkgScripts[name]
+	return script, found
+}
+
+// Returns the names of all the loaded scripts.
+func Names() []string {
+	names := make([]string, 0, len(pkgScripts))
+	for name, _ := range pkgScripts {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (self *Script) Name() string {
+	return self.name
+}
+
+// Returns all the texts shown by the script (speaker names, lines
+// and choices), as written in the script. Used to check localized
+// references.
+func (self *Script) Texts() []string {
+	var texts []string
+	for _, speaker := range self.speakers {
+		texts = append(texts, speaker.Name)
+	}
+	for _, node := range self.nodes {
+		for _, step := range node.steps {
+			if step.kind == stepSay || step.kind == stepChoice {
+				texts = append(texts, step.text)
+			}
+		}
+	}
+	return texts
+}
@@ -0,0 +1,213 @@
+package dialogue
+
+import "io"
+import "bufio"
+import "errors"
+import "strconv"
+import "strings"
+
+import "github.com/tinne26/transition/src/text"
+import "github.com/tinne26/transition/src/game/clr"
+import "github.com/tinne26/transition/src/game/state"
+
+type scriptToken struct {
+	Text string
+	Quoted bool
+}
+
+func parseScript(name string, reader io.Reader) (*Script, error) {
+	script := &Script{
+		name: name,
+		speakers: make(map[string]*Speaker),
+		nodes: make(map[string]*scriptNode),
+	}
+
+	var node *scriptNode
+	scanner := bufio.NewScanner(reader)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum += 1
+		tokens, err := tokenizeScriptLine(scanner.Text())
+		if err != nil { return nil, scriptLineErr(name, lineNum, err.Error()) }
+		if len(tokens) == 0 { continue }
+		node, err = script.parseStatement(node, tokens)
+		if err != nil { return nil, scriptLineErr(name, lineNum, err.Error()) }
+	}
+	err := scanner.Err()
+	if err != nil { return nil, err }
+
+	err = script.resolve()
+	if err != nil { return nil, errors.New("dialogue: script '" + name + "': " + err.Error()) }
+	return script, nil
+}
+
+func scriptLineErr(name string, lineNum int, msg string) error {
+	return errors.New("dialogue: script '" + name + "' line " + strconv.Itoa(lineNum) + ": " + msg)
+}
+
+// Parses a statement. Returns the node that following statements
+// belong to.
+func (self *Script) parseStatement(node *scriptNode, tokens []scriptToken) (*scriptNode, error) {
+	if tokens[0].Quoted { return node, errors.New("expected statement, found string") }
+	args := tokens[1 : ]
+	switch tokens[0].Text {
+	case "speaker":
+		if len(args) != 3 && len(args) != 4 { return node, errors.New("'speaker' expects 3 or 4 arguments") }
+		id := args[0].Text
+		if _, found := self.speakers[id]; found { return node, errors.New("duplicated speaker '" + id + "'") }
+		rgba, found := clr.ByName(args[1].Text)
+		if !found { return node, errors.New("unknown color '" + args[1].Text + "'") }
+		name, err := textArg(args[2])
+		if err != nil { return node, err }
+		speaker := &Speaker{ Name: name, Color: rgba }
+		if len(args) == 4 {
+			if args[3].Text != "blip" || args[3].Quoted { return node, errors.New("expected 'blip'") }
+			speaker.Blip = true
+		}
+		self.speakers[id] = speaker
+		return node, nil
+	case "node":
+		if len(args) != 1 { return node, errors.New("'node' expects 1 argument") }
+		name := args[0].Text
+		if _, found := self.nodes[name]; found { return node, errors.New("duplicated node '" + name + "'") }
+		node = &scriptNode{ name: name }
+		self.nodes[name] = node
+		if self.start == nil { self.start = node }
+		return node, nil
+	}
+
+	// node statements
+	if node == nil { return node, errors.New("'" + tokens[0].Text + "' outside of node") }
+	var step scriptStep
+	var err error
+	switch tokens[0].Text {
+	case "say":
+		if len(args) != 2 { return node, errors.New("'say' expects 2 arguments") }
+		speaker, found := self.speakers[args[0].Text]
+		if !found { return node, errors.New("unknown speaker '" + args[0].Text + "'") }
+		step = scriptStep{ kind: stepSay, speaker: speaker }
+		step.text, err = textArg(args[1])
+	case "choice":
+		if len(args) != 2 && len(args) != 4 { return node, errors.New("'choice' expects 2 or 4 arguments") }
+		step = scriptStep{ kind: stepChoice, targetName: args[1].Text }
+		step.text, err = textArg(args[0])
+		if err == nil && len(args) == 4 {
+			if args[2].Text != "if" { return node, errors.New("expected 'if', found '" + args[2].Text + "'") }
+			step.cond, err = conditionArg(args[3])
+		}
+	case "set", "unset":
+		if len(args) != 1 { return node, errors.New("'" + tokens[0].Text + "' expects 1 argument") }
+		step = scriptStep{ kind: stepSet, switchValue: tokens[0].Text == "set" }
+		step.switchKey, err = switchArg(args[0])
+	case "goto":
+		if len(args) != 1 { return node, errors.New("'goto' expects 1 argument") }
+		step = scriptStep{ kind: stepGoto, targetName: args[0].Text }
+	case "if":
+		if len(args) != 3 || args[1].Text != "goto" { return node, errors.New("expected 'if <condition> goto <node>'") }
+		step = scriptStep{ kind: stepGoto, targetName: args[2].Text }
+		step.cond, err = conditionArg(args[0])
+	case "end":
+		if len(args) != 0 { return node, errors.New("'end' expects no arguments") }
+		step = scriptStep{ kind: stepEnd }
+	default:
+		return node, errors.New("unknown statement '" + tokens[0].Text + "'")
+	}
+	if err != nil { return node, err }
+
+	// statements after unconditional jumps, ends or choices can't run
+	if len(node.steps) > 0 {
+		prev := node.steps[len(node.steps) - 1]
+		isUnconditionalJump := (prev.kind == stepGoto && prev.cond.switchKey == state.SwitchNone)
+		if prev.kind == stepEnd || isUnconditionalJump || (prev.kind == stepChoice && step.kind != stepChoice) {
+			return node, errors.New("unreachable '" + tokens[0].Text + "' statement")
+		}
+	}
+	node.steps = append(node.steps, step)
+	return node, nil
+}
+
+// Resolves the jump targets and checks that the script has nodes.
+func (self *Script) resolve() error {
+	if self.start == nil { return errors.New("no nodes defined") }
+	for _, node := range self.nodes {
+		for i, _ := range node.steps {
+			step := &node.steps[i]
+			if step.kind != stepGoto && step.kind != stepChoice { continue }
+			target, found := self.nodes[step.targetName]
+			if !found { return errors.New("node '" + node.name + "' refers to undefined node '" + step.targetName + "'") }
+			step.target = target
+		}
+	}
+	return nil
+}
+
+func textArg(token scriptToken) (string, error) {
+	if !token.Quoted { return "", errors.New("expected quoted text, found '" + token.Text + "'") }
+	if strings.HasPrefix(token.Text, "@") && !strings.HasPrefix(token.Text, "@@") {
+		return token.Text, nil // localized, checked by TestScriptReferences
+	}
+	for _, line := range strings.Split(token.Text, "\n") {
+		err := text.ValidateMarkup(line)
+		if err != nil { return "", err }
+	}
+	return token.Text, nil
+}
+
+func switchArg(token scriptToken) (state.Switch, error) {
+	key, found := state.SwitchFromName(token.Text)
+	if !found || key == state.SwitchNone { return key, errors.New("unknown switch '" + token.Text + "'") }
+	return key, nil
+}
+
+func conditionArg(token scriptToken) (condition, error) {
+	name, negated := strings.CutPrefix(token.Text, "!")
+	key, err := switchArg(scriptToken{ Text: name })
+	if err != nil { return condition{}, err }
+	return condition{ switchKey: key, negated: negated }, nil
+}
+
+// Splits a script line into tokens separated by whitespace. Quoted
+// strings are kept together. Comments start with '#' and extend to
+// the end of the line.
+func tokenizeScriptLine(line string) ([]scriptToken, error) {
+	var tokens []scriptToken
+	i := 0
+	for i < len(line) {
+		switch line[i] {
+		case ' ', '\t', '\r':
+			i += 1
+		case '#':
+			return tokens, nil
+		case '"':
+			var builder strings.Builder
+			i += 1
+			for {
+				if i >= len(line) { return nil, errors.New("unterminated string") }
+				if line[i] == '"' { break }
+				if line[i] == '\\' {
+					if i + 1 >= len(line) { return nil, errors.New("unterminated string") }
+					i += 1
+					if line[i] == 'n' {
+						builder.WriteByte('\n')
+						i += 1
+						continue
+					}
+					if line[i] != '"' && line[i] != '\\' {
+						return nil, errors.New("invalid escape sequence '\\" + string(line[i]) + "'")
+					}
+				}
+				builder.WriteByte(line[i])
+				i += 1
+			}
+			tokens = append(tokens, scriptToken{ Text: builder.String(), Quoted: true })
+			i += 1
+		default:
+			start := i
+			for i < len(line) && strings.IndexByte(" \t\r#\"", line[i]) == -1 {
+				i += 1
+			}
+			tokens = append(tokens, scriptToken{ Text: line[start : i] })
+		}
+	}
+	return tokens, nil
+}
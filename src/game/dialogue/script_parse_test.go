@@ -0,0 +1,54 @@
+package dialogue
+
+import "strings"
+import "testing"
+
+const testScriptHeader = "speaker a wings \"A\"\nnode start\n"
+
+func TestParseScriptErrors(t *testing.T) {
+	tests := []struct{ body, errMsg string }{
+		{ "say b \"HI\"", "line 3: unknown speaker 'b'" },
+		{ "goto nowhere", "refers to undefined node 'nowhere'" },
+		{ "choice \"GO\" nowhere", "refers to undefined node 'nowhere'" },
+		{ "goto start\nsay a \"HI\"", "line 4: unreachable 'say' statement" },
+		{ "end\nset tip_move", "line 4: unreachable 'set' statement" },
+		{ "choice \"GO\" start\nend", "line 4: unreachable 'end' statement" },
+		{ "set unknown_switch", "unknown switch 'unknown_switch'" },
+		{ "set none", "unknown switch 'none'" },
+		{ "if !unknown_switch goto start", "unknown switch 'unknown_switch'" },
+		{ "choice \"GO\" start when tip_move", "expected 'if', found 'when'" },
+		{ "say a HI", "expected quoted text, found 'HI'" },
+		{ "say a \"{color:pink}HI\"", "unknown markup color 'pink'" },
+		{ "say a \"HI", "unterminated string" },
+		{ "node start", "duplicated node 'start'" },
+		{ "speaker a dark \"B\"", "duplicated speaker 'a'" },
+		{ "speaker b pink \"B\"", "unknown color 'pink'" },
+		{ "jump start", "unknown statement 'jump'" },
+	}
+	for _, test := range tests {
+		_, err := parseScript("test", strings.NewReader(testScriptHeader + test.body + "\n"))
+		if err == nil || !strings.Contains(err.Error(), test.errMsg) {
+			t.Errorf("%q: expected error containing \"%s\", got %v", test.body, test.errMsg, err)
+		}
+	}
+
+	_, err := parseScript("test", strings.NewReader("speaker a wings \"A\"\n"))
+	if err == nil || !strings.Contains(err.Error(), "no nodes defined") { t.Errorf("expected no nodes error, got %v", err) }
+	_, err = parseScript("test", strings.NewReader("say a \"HI\"\n"))
+	if err == nil || !strings.Contains(err.Error(), "'say' outside of node") { t.Errorf("expected outside of node error, got %v", err) }
+}
+
+func TestParseScriptReachability(t *testing.T) {
+	// conditional jumps and consecutive choices don't make
+	// the following statements unreachable
+	bodies := []string{
+		"if tip_move goto start\nsay a \"HI\"",
+		"if !tip_move goto start\nend",
+		"choice \"ONE\" start\nchoice \"TWO\" start if tip_move",
+		"say a \"HI\"\nchoice \"ONE\" start",
+	}
+	for _, body := range bodies {
+		_, err := parseScript("test", strings.NewReader(testScriptHeader + body + "\n"))
+		if err != nil { t.Errorf("%q: %s", body, err) }
+	}
+}
@@ -0,0 +1,28 @@
+package dialogue
+
+import "os"
+import "strings"
+import "testing"
+
+import "github.com/tinne26/transition/src/lang"
+
+// The repository root, where the assets are.
+var testFS = os.DirFS("../../..")
+
+// Localized references in the scripts must exist in the fallback
+// language (the string tables themselves are checked in src/text).
+func TestScriptReferences(t *testing.T) {
+	err := LoadAll(testFS)
+	if err != nil { t.Fatal(err) }
+	fallback, err := lang.LoadTable(testFS, lang.Fallback)
+	if err != nil { t.Fatal(err) }
+	if len(Names()) == 0 { t.Fatal("no dialogue scripts found") }
+
+	for _, name := range Names() {
+		for _, str := range Get(name).Texts() {
+			if !strings.HasPrefix(str, "@") || strings.HasPrefix(str, "@@") { continue }
+			if _, found := fallback.Lookup(str[1 : ]); found { continue }
+			t.Errorf("dialogue '%s': missing string '%s'", name, str[1 : ])
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package game
 
 import "time"
+import "bytes"
+import "errors"
 import "io/fs"
 import "math"
 import "strings"
@@ -8,6 +10,7 @@ import "strings"
 import "github.com/hajimehoshi/ebiten/v2"
 
 import "github.com/tinne26/transition/src/debug"
+import "github.com/tinne26/transition/src/rng"
 import "github.com/tinne26/transition/src/input"
 import "github.com/tinne26/transition/src/audio"
 import "github.com/tinne26/transition/src/utils"
@@ -19,6 +22,7 @@ import "github.com/tinne26/transition/src/game/player"
 import "github.com/tinne26/transition/src/game/player/motion"
 import "github.com/tinne26/transition/src/game/player/miniscene"
 import "github.com/tinne26/transition/src/game/level"
+import "github.com/tinne26/transition/src/game/level/lvlkey"
 import "github.com/tinne26/transition/src/game/level/block"
 import "github.com/tinne26/transition/src/game/bckg"
 import "github.com/tinne26/transition/src/game/u16"
@@ -27,14 +31,16 @@ import "github.com/tinne26/transition/src/game/context"
 import "github.com/tinne26/transition/src/game/hint"
 import "github.com/tinne26/transition/src/game/clr"
 import "github.com/tinne26/transition/src/game/sword"
+import "github.com/tinne26/transition/src/game/dialogue"
 import "github.com/tinne26/transition/src/game/title"
 import "github.com/tinne26/transition/src/game/flash"
-
-// TODO: while on main menu, return ebiten.Termination if going to "save and quit"
-//       (or maybe stay always saved? autosave on progress / change?)
+import "github.com/tinne26/transition/src/game/savegame"
 
 var _ ebiten.Game = (*Game)(nil)
 
+// Ticks to press the quit key again to confirm during gameplay.
+const quitConfirmTicks = 150
+
 type Game struct {
 	tick uint64
 	scale float64
@@ -55,23 +61,48 @@ type Game struct {
 	activeHint *hint.Hint
 	levelTriggers []trigger.Trigger
 	ctx *context.Context
+	devices *input.DeviceSource
+	deviceNotice *DeviceNotice
+	saves *savegame.Manager // nil if saving is not available
 	swordChallenge *sword.Challenge
+	dialogue *dialogue.Dialogue
+	quitConfirmTicks int // > 0 while waiting for the quit key to be pressed again
+	quitMessage *text.Message
 	titleScreen *title.Title
 	mini miniscene.Scene
 	flash *flash.Flash
+
+	// input recording and replays
+	replaying *input.ScriptedSource // nil if not replaying
+	recorder *input.RecordingSource // nil if not recording
+	recording *input.Replay
+	recordPath string
 	
 	// experimental graphical effects and shaders
 	selfModGfxPipe *shaders.SelfModGfxPipe
 	gfxAnim *shaders.Animation
+	dyingAudio bool // whether death audio effects are active
 }
 
 func New(filesys fs.FS) (*Game, error) {
-	err := motion.LoadAnimations(filesys)
+	// seed randomness (replays must use the recorded seed)
+	replay, err := loadReplayArg()
+	if err != nil { return nil, err }
+	seed := rng.NewSeed()
+	if replay != nil { seed = replay.Seed }
+	rng.Seed(seed)
+
+	err = motion.LoadAnimations(filesys)
 	if err != nil { return nil, err }
 	err = player.LoadUIGraphics(filesys)
 	if err != nil { return nil, err }
 	
-	ctx, err := context.NewContext(filesys)
+	bindings, err := loadBindings()
+	if err != nil { return nil, err }
+	refreshKeyGlyphs(bindings)
+	ctx, err := context.NewContext(filesys, bindings)
+	if err != nil { return nil, err }
+	gamepadDB, err := input.LoadGamepadDBFile(filesys, "assets/input/gamepads.txt")
 	if err != nil { return nil, err }
 
 	// Edit this to change the entry point in a hardcoded manner
@@ -83,6 +114,31 @@ func New(filesys fs.FS) (*Game, error) {
 	// own save (local disk or web)
 	entryKey := level.EntryStartSaveLeft // level.EntrySwordSaveCenter
 
+	// load previous progress, if any (use --nosave to ignore).
+	// replays never touch saves, the state is in the replay
+	var saves *savegame.Manager
+	if replay != nil {
+		_, err = ctx.State.ReadFrom(bytes.NewReader(replay.State))
+		if err != nil { return nil, err }
+		entryKey = replay.EntryKey
+	} else if !utils.OsArgReceived("--nosave") {
+		saves, err = savegame.NewManager()
+		if err != nil {
+			debug.Tracef("Saving unavailable: %s\n", err.Error())
+			saves = nil
+		} else {
+			err = saves.Load(ctx.State)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				debug.Printf("Failed to load save: %s\n", err.Error())
+			}
+			if level.IsValidEntryKey(ctx.State.LastSaveEntryKey) {
+				entryKey = ctx.State.LastSaveEntryKey
+			} else {
+				ctx.State.LastSaveEntryKey = lvlkey.Undefined
+			}
+		}
+	}
+
 	lvl, entry := level.GetEntryPoint(entryKey)
 	lvl.EnableSavepoint(entryKey)
 	game := Game{
@@ -93,7 +149,11 @@ func New(filesys fs.FS) (*Game, error) {
 		background: bckg.New(),
 		projector: project.NewProjector(640, 360),
 		ctx: ctx,
+		devices: ctx.Input.Source().(*input.DeviceSource),
+		deviceNotice: NewDeviceNotice(),
+		saves: saves,
 		titleScreen: title.New(),
+		quitMessage: text.NewWrappedMsg("@msg.quit_confirm", clr.WingsText),
 		optsFancyCamera: true, // I keep it here mostly for testing
 		
 		// experimental graphical effects
@@ -110,12 +170,20 @@ func New(filesys fs.FS) (*Game, error) {
 	game.player.SetIdleAt(entry.X, entry.Y, game.ctx)
 	game.camera.SetTarget(game.player)
 	game.camera.Center()
+	game.ctx.Audio.SetListener(game.camera)
 	game.camera.SetFancy(game.optsFancyCamera)
 	game.background.SetColor(game.level.GetBackColor())
 	game.background.SetMaskColors(game.level.GetBackMaskColors())
 	game.background.SetMasks(game.level.GetBackMasks())
 	game.levelTriggers = game.level.GetTriggers()
 	game.ctx.Audio.FadeIn(audio.BgmBackground, 0, time.Millisecond*850, 0)
+	game.devices.SetGamepadDB(gamepadDB)
+	game.devices.OnEvent(game.onDeviceEvent)
+
+	// replays and recordings (--replay file, --record file)
+	if replay != nil { game.startReplay(replay) }
+	err = game.startRecordingIfRequested(seed, entryKey)
+	if err != nil { return nil, err }
 	
 	return &game, nil
 }
@@ -150,12 +218,27 @@ func (self *Game) Update() error {
 	var err error
 	err = self.ctx.Update()
 	if err != nil { return err }
+	self.updateReplay()
+	self.deviceNotice.Update()
 
 	// some common fullscreen shortcuts
 	if self.ctx.Input.Trigger(input.ActionFullscreen) || self.ctx.Input.Trigger(input.ActionFullscreen2) {
 		ebiten.SetFullscreen(!ebiten.IsFullscreen())
 	}
 
+	// save and quit. on the title screen this happens right away, but
+	// during gameplay the quit key has to be pressed twice, and it's
+	// ignored while challenges, dialogues, scenes or texts are active
+	if self.quitConfirmTicks > 0 { self.quitConfirmTicks -= 1 }
+	if !self.canQuit() { self.quitConfirmTicks = 0 }
+	if self.ctx.Input.Trigger(input.ActionQuit) {
+		if self.titleScreen != nil || self.quitConfirmTicks > 0 {
+			self.autosave()
+			return ebiten.Termination
+		}
+		if self.canQuit() { self.quitConfirmTicks = quitConfirmTicks }
+	}
+
 	// update game elements
 	err = self.background.Update()
 	if err != nil { return err }
@@ -176,7 +259,7 @@ func (self *Game) Update() error {
 			
 			// TODO: may remove this little hack for the non-jam versions
 			ebitengineRef := false
-			for _, line := range self.longText {
+			for _, line := range text.LocalizeLines(self.longText) {
 				ebitengineRef = ebitengineRef || strings.Contains(line, "HOSHI")
 			}
 			if ebitengineRef {
@@ -212,6 +295,7 @@ func (self *Game) Update() error {
 				postType := block.TypeDecorLargeSwordAbsorbed
 				self.level.ReplaceNearestBehindDecor(x, y, preType, postType)
 				self.ctx.State.TransitionStage += 1
+				self.autosave()
 			}
 		}
 	}
@@ -228,6 +312,16 @@ func (self *Game) Update() error {
 		response, err := self.mini.Update(self.ctx, self.camera, self.player.GetQuickStatus())
 		if err != nil { return err }
 		self.HandleMiniResponse(response)
+	} else if self.dialogue != nil {
+		// (triggers are not updated during dialogues, so the key
+		// that ends a dialogue can't start another one)
+		err = self.dialogue.Update(self.ctx)
+		if err != nil { return err }
+		self.textMessage = self.dialogue.CurrentText()
+		if self.dialogue.IsOver() {
+			self.dialogue = nil
+			self.player.UnblockInteractionAfter(8)
+		}
 	} else {
 		for _, trigger := range self.levelTriggers {
 			response, err := trigger.Update(playerShot, self.ctx)
@@ -256,10 +350,33 @@ func (self *Game) Update() error {
 		self.camera.Center()
 		self.gfxAnim = shaders.AnimRespawn.Restart()
 	}
+	self.updateDeathAudio()
 
 	return nil
 }
 
+// Returns whether the game can be saved and quit during gameplay.
+func (self *Game) canQuit() bool {
+	if self.swordChallenge != nil || self.dialogue != nil { return false }
+	if self.mini != nil || self.longText != nil { return false }
+	return true
+}
+
+// Muffles the audio and slows down the music while the
+// player is dead or the respawn animation is playing.
+func (self *Game) updateDeathAudio() {
+	dying := self.player.HasDied() || self.gfxAnim == shaders.AnimRespawn
+	if dying == self.dyingAudio { return }
+	self.dyingAudio = dying
+	if dying {
+		self.ctx.Audio.SetParam(audio.ParamKeyMuffle, 0.85)
+		self.ctx.Audio.SetParam(audio.ParamKeyMusicRate, 0.9)
+	} else {
+		self.ctx.Audio.SetParam(audio.ParamKeyMuffle, 0)
+		self.ctx.Audio.SetParam(audio.ParamKeyMusicRate, 1)
+	}
+}
+
 func (self *Game) respawnPlayer() {
 	lvl, pt := level.GetEntryPoint(self.ctx.State.LastSaveEntryKey)
 	self.transferPlayer(lvl, pt)
@@ -288,6 +405,16 @@ func (self *Game) transferPlayer(lvl *level.Level, position u16.Point) {
 	}
 }
 
+// Saves the current state on the active slot, if saving is available.
+// Failing to save is reported, but it's not a reason to stop the game.
+func (self *Game) autosave() {
+	if self.saves == nil { return }
+	err := self.saves.Save(self.ctx.State)
+	if err != nil {
+		debug.Printf("Failed to save: %s\n", err.Error())
+	}
+}
+
 func (self *Game) Draw(canvas *ebiten.Image) {
 	if !self.needsRedraw { return }
 	self.needsRedraw = false
@@ -323,6 +450,7 @@ func (self *Game) Draw(canvas *ebiten.Image) {
 	if self.titleScreen != nil {
 		self.titleScreen.DrawShader(self.projector.ActiveCanvas)
 		self.titleScreen.Draw(self.projector.LogicalCanvas)
+		self.deviceNotice.Draw(self.projector.LogicalCanvas)
 		self.projector.ProjectLogical(0, 0)
 		return
 	}
@@ -378,6 +506,7 @@ func (self *Game) Draw(canvas *ebiten.Image) {
 	// draw UI, text, etc
 	self.projector.LogicalCanvas.Clear()
 	self.player.DrawUI(self.projector, self.ctx)
+	if self.quitConfirmTicks > 0 { self.textMessage = self.quitMessage }
 	if self.textMessage != nil {
 		text.Draw(self.projector.LogicalCanvas, 320, 324, self.textMessage)
 		self.textMessage = nil // dismiss, we use stuff only once cause we are wasteful
@@ -397,4 +526,11 @@ func (self *Game) Draw(canvas *ebiten.Image) {
 		text.CenterRawDraw(self.projector.LogicalCanvas, self.longText, clr.WingsText)
 		self.projector.ProjectLogical(0, 0)
 	}
+
+	// device notices go on top of everything
+	if self.deviceNotice.IsVisible() {
+		self.projector.LogicalCanvas.Clear()
+		self.deviceNotice.Draw(self.projector.LogicalCanvas)
+		self.projector.ProjectLogical(0, 0)
+	}
 }
@@ -31,6 +31,7 @@ func (self *Game) HandleMiniResponse(response any) {
 		lvl, _ := level.GetEntryPoint(key)
 		lvl.DisableSavepoints()
 		lvl.EnableSavepoint(key)
+		self.autosave()
 	case *shaders.Animation:
 		self.gfxAnim = typedResponse
 	case *flash.Flash:
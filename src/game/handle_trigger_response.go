@@ -4,6 +4,7 @@ import "github.com/tinne26/transition/src/game/level"
 import "github.com/tinne26/transition/src/game/hint"
 import "github.com/tinne26/transition/src/game/trigger"
 import "github.com/tinne26/transition/src/game/sword"
+import "github.com/tinne26/transition/src/game/dialogue"
 import "github.com/tinne26/transition/src/game/player/miniscene"
 import "github.com/tinne26/transition/src/text"
 
@@ -24,6 +25,10 @@ func (self *Game) HandleTriggerResponse(response any) {
 		} else {
 			self.fader.SetBlackness(typedResponse)
 		}
+	case *dialogue.Dialogue:
+		self.dialogue = typedResponse
+		self.textMessage = typedResponse.CurrentText()
+		self.player.SetBlockedForInteraction()
 	case *sword.Challenge:
 		challenge := typedResponse
 		self.player.SetBlockedForInteraction()
@@ -49,6 +49,9 @@ func NewHint(hintType HintType, x, y uint16) Hint {
 	return Hint{ htype: hintType, x: x, y: y }
 }
 
+func (self Hint) Type() HintType { return self.htype }
+func (self Hint) Position() (uint16, uint16) { return self.x, self.y }
+
 var hintOpts ebiten.DrawImageOptions
 func (self Hint) Draw(projector *project.Projector, playerX, playerY uint16) {
 	x, y := self.x, self.y
@@ -65,6 +65,7 @@ func (self *Block) Rect() u16.Rect {
 }
 
 func (self *Block) Type() *BlockType { return pkgBlockTypes[self.typeID] }
+func (self *Block) TypeID() ID { return self.typeID }
 func (self *Block) TopLeft() (uint16, uint16) { return self.X, self.Y }
 func (self *Block) TopRight() (uint16, uint16) { return self.X + self.Type().Width, self.Y }
 func (self *Block) BottomLeft() (uint16, uint16) { return self.X, self.Y + self.Type().Height }
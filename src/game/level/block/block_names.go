@@ -0,0 +1,55 @@
+package block
+
+import "errors"
+import "strconv"
+
+// Block type names are derived from their asset file names (e.g.
+// "step_small_A"), with variants that share the same image getting
+// a distinctive name (e.g. "step_float_small_A", "dark_floor_wide").
+// Unlike IDs, which depend on the registration order, names are
+// stable and can be used on persisted formats like level files.
+
+var pkgBlockTypesByName map[string]ID
+
+// Returns the ID of the block type with the given name. Only
+// valid after CreateAll().
+func LookupByName(name string) (ID, bool) {
+	id, found := pkgBlockTypesByName[name]
+	return id, found
+}
+
+// Returns the number of registered block types. IDs go from
+// 0 to NumTypes() - 1.
+func NumTypes() int {
+	return len(pkgBlockTypes)
+}
+
+// Returns the block type for the given ID.
+func GetType(id ID) *BlockType {
+	return pkgBlockTypes[id]
+}
+
+// Calls the given function for each registered block type,
+// in ID order.
+func EachType(fn func(*BlockType)) {
+	for _, blockType := range pkgBlockTypes {
+		fn(blockType)
+	}
+}
+
+// Called at the end of CreateAll(). Fails if any block type
+// has an empty or duplicate name.
+func indexBlockTypeNames() error {
+	pkgBlockTypesByName = make(map[string]ID, len(pkgBlockTypes))
+	for _, blockType := range pkgBlockTypes {
+		if blockType.Name == "" {
+			return errors.New("block type #" + strconv.Itoa(int(blockType.InternalIndex)) + " has no name")
+		}
+		_, duplicate := pkgBlockTypesByName[blockType.Name]
+		if duplicate {
+			return errors.New("duplicate block type name '" + blockType.Name + "'")
+		}
+		pkgBlockTypesByName[blockType.Name] = blockType.InternalIndex
+	}
+	return nil
+}
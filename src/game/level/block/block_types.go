@@ -7,6 +7,7 @@ import "github.com/hajimehoshi/ebiten/v2"
 import "github.com/tinne26/transition/src/game/clr"
 
 type BlockType struct {
+	Name string // stable, used for persistence. See LookupByName()
 	Image *ebiten.Image
 	Width uint16
 	Height uint16
@@ -60,12 +61,13 @@ func registerBlockType(blockType *BlockType) ID {
 	return blockType.InternalIndex
 }
 
-func newBlockFromImg(img *ebiten.Image, subtype Subtype) *BlockType {
+func newBlockFromImg(name string, img *ebiten.Image, subtype Subtype) *BlockType {
 	bounds := img.Bounds()
 	if bounds.Min.X != 0 || bounds.Min.Y != 0 {
 		panic("unexpected non-zero image origin")
 	}
 	return &BlockType{
+		Name: name,
 		Image: img,
 		Width: uint16(bounds.Dx()),
 		Height: uint16(bounds.Dy()),
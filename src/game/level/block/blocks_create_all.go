@@ -1,6 +1,8 @@
 package block
 
+import "path"
 import "io/fs"
+import "strings"
 
 import "github.com/hajimehoshi/ebiten/v2"
 
@@ -88,293 +90,258 @@ func CreateAll(filesys fs.FS) error {
 	var img *ebiten.Image
 	var err error
 	var block *BlockType
-	
+
+	// allow recreating the blocks (IDs are assigned in order)
+	pkgBlockTypes = pkgBlockTypes[ : 0]
+
 	// --- load images and set up stuff manually ---
 	// TypePlatFlatHorzLong_A
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/blocks/platform_flat_horz_long_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/blocks/platform_flat_horz_long_A.png", SubtypeBlock)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeBlock)
 	TypePlatFlatHorzLong_A = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/blocks/platform_flat_horz_long_B.png")
+	block, err = loadBlockType(filesys, "assets/graphics/blocks/platform_flat_horz_long_B.png", SubtypeBlock)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeBlock)
 	TypePlatFlatHorzLong_B = registerBlockType(block)
 
 	// small horz
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/blocks/platform_flat_horz_small_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/blocks/platform_flat_horz_small_A.png", SubtypeBlock)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeBlock)
 	TypePlatFlatHorzSmall_A = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/blocks/platform_flat_horz_small_B.png")
+	block, err = loadBlockType(filesys, "assets/graphics/blocks/platform_flat_horz_small_B.png", SubtypeBlock)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeBlock)
 	TypePlatFlatHorzSmall_B = registerBlockType(block)
 
 	// TypePlatFlatVertLong_A
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/blocks/platform_flat_vert_long_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/blocks/platform_flat_vert_long_A.png", SubtypeBlock)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeBlock)
 	TypePlatFlatVertLong_A = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/blocks/platform_flat_vert_short_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/blocks/platform_flat_vert_short_A.png", SubtypeBlock)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeBlock)
 	TypePlatFlatVertShort_A = registerBlockType(block)
 
 	// TypePlatGroundBig_A
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/blocks/platform_ground_big_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/blocks/platform_ground_big_A.png", SubtypeBlock)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeBlock)
 	TypePlatGroundBig_A = registerBlockType(block)
 
 	// TypePlatGroundMedium_A
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/blocks/platform_ground_medium_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/blocks/platform_ground_medium_A.png", SubtypeBlock)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeBlock)
 	TypePlatGroundMedium_A = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/blocks/platform_ground_medium_B.png")
+	block, err = loadBlockType(filesys, "assets/graphics/blocks/platform_ground_medium_B.png", SubtypeBlock)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeBlock)
 	TypePlatGroundMedium_B = registerBlockType(block)
 
 	// TypePlatGroundSquareSmall_A, TypePlatGroundSquareSmall_B
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/blocks/platform_ground_square_small_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/blocks/platform_ground_square_small_A.png", SubtypeBlock)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeBlock)
 	TypePlatGroundSquareSmall_A = registerBlockType(block)
 	
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/blocks/platform_ground_square_small_B.png")
+	block, err = loadBlockType(filesys, "assets/graphics/blocks/platform_ground_square_small_B.png", SubtypeBlock)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeBlock)
 	TypePlatGroundSquareSmall_B = registerBlockType(block)
 
 	// TypeStepSmall_A
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/blocks/step_small_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/blocks/step_small_A.png", SubtypeThinStep)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeThinStep)
 	TypeStepSmall_A = registerBlockType(block)
-	block = newBlockFromImg(img, SubtypeThinBlock)
+	block = newBlockFromImg("step_float_small_A", block.Image, SubtypeThinBlock)
 	TypeStepFloatSmall_A = registerBlockType(block)
 
 	// TypeStepSmall_B
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/blocks/step_small_B.png")
+	block, err = loadBlockType(filesys, "assets/graphics/blocks/step_small_B.png", SubtypeThinStep)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeThinStep)
 	TypeStepSmall_B = registerBlockType(block)
-	block = newBlockFromImg(img, SubtypeThinBlock)
+	block = newBlockFromImg("step_float_small_B", block.Image, SubtypeThinBlock)
 	TypeStepFloatSmall_B = registerBlockType(block)
 
 	// TypeStepSmall_C
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/blocks/step_small_C.png")
+	block, err = loadBlockType(filesys, "assets/graphics/blocks/step_small_C.png", SubtypeThinStep)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeThinStep)
 	TypeStepSmall_C = registerBlockType(block)
-	block = newBlockFromImg(img, SubtypeThinBlock)
+	block = newBlockFromImg("step_float_small_C", block.Image, SubtypeThinBlock)
 	TypeStepFloatSmall_C = registerBlockType(block)
 
 	// TypeStepSmall_D
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/blocks/step_small_D.png")
+	block, err = loadBlockType(filesys, "assets/graphics/blocks/step_small_D.png", SubtypeThinStep)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeThinStep)
 	TypeStepSmall_D = registerBlockType(block)
-	block = newBlockFromImg(img, SubtypeThinBlock)
+	block = newBlockFromImg("step_float_small_D", block.Image, SubtypeThinBlock)
 	TypeStepFloatSmall_D = registerBlockType(block)
 
 	// TypeStepLong_A, TypeStepFloatLong_A, TypeStepLeftLong_A, TypeStepRightLong_A
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/blocks/step_long_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/blocks/step_long_A.png", SubtypeThinStep)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeThinStep)
 	TypeStepLong_A = registerBlockType(block)
-	block = newBlockFromImg(img, SubtypeThinBlock)
+	block = newBlockFromImg("step_float_long_A", block.Image, SubtypeThinBlock)
 	TypeStepFloatLong_A = registerBlockType(block)
-	block = newBlockFromImg(img, SubtypeThinStepOnLeft)
+	block = newBlockFromImg("step_left_long_A", block.Image, SubtypeThinStepOnLeft)
 	TypeStepLeftLong_A = registerBlockType(block)
-	block = newBlockFromImg(img, SubtypeThinStepOnRight)
+	block = newBlockFromImg("step_right_long_A", block.Image, SubtypeThinStepOnRight)
 	TypeStepRightLong_A = registerBlockType(block)
 
 	// TypeStepLong_B and co.
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/blocks/step_long_B.png")
+	block, err = loadBlockType(filesys, "assets/graphics/blocks/step_long_B.png", SubtypeThinStep)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeThinStep)
 	TypeStepLong_B = registerBlockType(block)
-	block = newBlockFromImg(img, SubtypeThinBlock)
+	block = newBlockFromImg("step_float_long_B", block.Image, SubtypeThinBlock)
 	TypeStepFloatLong_B = registerBlockType(block)
-	block = newBlockFromImg(img, SubtypeThinStepOnLeft)
+	block = newBlockFromImg("step_left_long_B", block.Image, SubtypeThinStepOnLeft)
 	TypeStepLeftLong_B = registerBlockType(block)
-	block = newBlockFromImg(img, SubtypeThinStepOnRight)
+	block = newBlockFromImg("step_right_long_B", block.Image, SubtypeThinStepOnRight)
 	TypeStepRightLong_B = registerBlockType(block)
 
 	// dark floors
 	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/blocks/dark_floor.png")
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeBlock)
+	block = newBlockFromImg("dark_floor_normal", img, SubtypeBlock)
 	block.Width, block.Height = 330, 100
 	TypeDarkFloorNormal = registerBlockType(block)
 	typeDarkFloorIniMarker = TypeDarkFloorNormal
-	block = newBlockFromImg(img, SubtypeBlock)
+	block = newBlockFromImg("dark_floor_big", img, SubtypeBlock)
 	block.Width, block.Height = 540, 140
 	TypeDarkFloorBig = registerBlockType(block)
-	block = newBlockFromImg(img, SubtypeBlock)
+	block = newBlockFromImg("dark_floor_wide", img, SubtypeBlock)
 	block.Width, block.Height = 480, 76
 	TypeDarkFloorWide = registerBlockType(block)
 	typeDarkFloorEndMarker = TypeDarkFloorWide
 
 	// ---- decorations ----
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/stone_inscription.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/stone_inscription.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorStoneInscr = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/right_sign.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/right_sign.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorSignGoRight = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/left_sign.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/left_sign.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorSignGoLeft = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/down_sign.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/down_sign.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorSignGoDown = registerBlockType(block)
 
 	// skeletooons
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/skeleton_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/skeleton_A.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorSkeleton_A = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/skull_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/skull_A.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorSkull_A = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/skull_B.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/skull_B.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorSkull_B = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/back_skeleton_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/back_skeleton_A.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorBackSkeleton_A = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/back_skull_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/back_skull_A.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorBackSkull_A = registerBlockType(block)
 
 	// weaaaaapoons
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/large_sword_absorbed.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/large_sword_absorbed.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorLargeSwordAbsorbed = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/large_sword_active.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/large_sword_active.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorLargeSwordActive = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/sword_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/sword_A.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorSword_A = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/sword_B.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/sword_B.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorSword_B = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/sword_C.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/sword_C.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorSword_C = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/sword_D.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/sword_D.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorSword_D = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/axe_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/axe_A.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorAxe_A = registerBlockType(block)
 	
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/axe_B.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/axe_B.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorAxe_B = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/spear_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/spear_A.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorSpear_A = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/spear_B.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/spear_B.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorSpear_B = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/basketball_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/basketball_A.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorBasketball_A = registerBlockType(block)
 	
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/back_axe_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/back_axe_A.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorBackAxe_A = registerBlockType(block)
 	
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/back_axe_B.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/back_axe_B.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorBackAxe_B = registerBlockType(block)
 		
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/back_spear_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/back_spear_A.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorBackSpear_A = registerBlockType(block)
 	
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/back_spear_B.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/back_spear_B.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorBackSpear_B = registerBlockType(block)
 	
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/back_sword_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/back_sword_A.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorBackSword_A = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/back_sword_B.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/back_sword_B.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeDecorBackSword_B = registerBlockType(block)
 
 	// ---- savepoints ----
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/savepoint_active_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/savepoint_active_A.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeSaveActive_A = registerBlockType(block)
 	
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/savepoint_active_B.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/savepoint_active_B.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeSaveActive_B = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/savepoint_inactive_A.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/savepoint_inactive_A.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeSaveInactive_A = registerBlockType(block)
 
-	img, err = utils.LoadFsEbiImage(filesys, "assets/graphics/decorations/savepoint_inactive_B.png")
+	block, err = loadBlockType(filesys, "assets/graphics/decorations/savepoint_inactive_B.png", SubtypeNone)
 	if err != nil { return err }
-	block = newBlockFromImg(img, SubtypeNone)
 	TypeSaveInactive_B = registerBlockType(block)
 
-	return nil
+	return indexBlockTypeNames()
+}
+
+// Loads the image at the given path and creates a block type named
+// after the file (e.g. ".../step_small_A.png" gives "step_small_A").
+func loadBlockType(filesys fs.FS, filename string, subtype Subtype) (*BlockType, error) {
+	img, err := utils.LoadFsEbiImage(filesys, filename)
+	if err != nil { return nil, err }
+	name := strings.TrimSuffix(path.Base(filename), path.Ext(filename))
+	return newBlockFromImg(name, img, subtype), nil
 }
@@ -1,6 +1,7 @@
 package collision
 
 import "github.com/tinne26/transition/src/game/level/block"
+import "github.com/tinne26/transition/src/game/u16"
 
 type SearchControl bool
 const (
@@ -146,30 +147,46 @@ func (self *AugmentedTree) extractInorderNode(parent, node *augTreeNode) (*augTr
 	return parent.Rebalance(), node
 }
 
+// Rebuilds the tree so it's perfectly balanced. Add() and Remove()
+// already keep the tree balanced (AVL), but heights can still differ
+// by one level between branches.
 func (self *AugmentedTree) Stabilize() {
 	if self.root == nil { return }
-	left, right := self.root.Left, self.root.Right
-	self.root.Left, self.root.Right = nil, nil
-	self.root.Height = 0
-	self.root.RefreshMaxX()
-	if left != nil {
-		self.root = augmentedTreeRecursiveDfsAdd(self.root, left)
-	}
-	if right != nil {
-		self.root = augmentedTreeRecursiveDfsAdd(self.root, right)
-	}
+	blocks := make([]block.Block, 0, 64)
+	augmentedTreeRecursiveInorder(self.root, &blocks)
+	self.root = augmentedTreeBuildSorted(blocks)
 }
 
-func augmentedTreeRecursiveDfsAdd(root, node *augTreeNode) *augTreeNode {
-	root = augmentedTreeRecursiveAdd(root, node.Block)
-	left, right := node.Left, node.Right
-	if left != nil {
-		root = augmentedTreeRecursiveDfsAdd(root, left)
-	}
-	if right != nil {
-		root = augmentedTreeRecursiveDfsAdd(root, right)
+func augmentedTreeRecursiveInorder(node *augTreeNode, blocks *[]block.Block) {
+	if node == nil { return }
+	augmentedTreeRecursiveInorder(node.Left, blocks)
+	*blocks = append(*blocks, node.Block)
+	augmentedTreeRecursiveInorder(node.Right, blocks)
+}
+
+// Creates a perfectly balanced tree from blocks sorted by X, which is
+// much faster than adding them one by one. Panics if the blocks are
+// not sorted. The slice is not retained.
+func BuildFromSorted(blocks []block.Block) *AugmentedTree {
+	for i := 1; i < len(blocks); i++ {
+		if blocks[i].X < blocks[i - 1].X { panic("blocks not sorted by X") }
 	}
-	return root
+	return &AugmentedTree{ root: augmentedTreeBuildSorted(blocks) }
+}
+
+func augmentedTreeBuildSorted(blocks []block.Block) *augTreeNode {
+	if len(blocks) == 0 { return nil }
+	mid := len(blocks) >> 1
+	node := newAugTreeNode(blocks[mid])
+	node.Left  = augmentedTreeBuildSorted(blocks[ : mid])
+	node.Right = augmentedTreeBuildSorted(blocks[mid + 1 : ])
+	node.Refresh()
+	return node
+}
+
+// Returns the number of blocks in the tree.
+func (self *AugmentedTree) Len() int {
+	return self.root.count()
 }
 
 // A more complete version of Collision() that calls the given
@@ -207,13 +224,16 @@ func (self *AugmentedTree) recursiveEach(node *augTreeNode, fn func(block.Block)
 	return self.recursiveEach(node.Right, fn)
 }
 
+// Calls the given function for each block that overlaps the
+// horizontal range [minX, maxX). Blocks entirely to the left of
+// minX are not included.
 func (self *AugmentedTree) EachInXRange(minX, maxX uint16, fn func(block.Block) SearchControl) {
 	_ = self.recursiveEachInXRange(self.root, minX, maxX, fn)
 }
 
 func (self *AugmentedTree) recursiveEachInXRange(node *augTreeNode, minX, maxX uint16, fn func(block.Block) SearchControl) SearchControl {
 	if minX >= node.GetMaxX() { return SearchContinue } // no possible collision in this sub-branch
-	if maxX > node.Block.X {
+	if maxX > node.Block.X && minX < node.Block.Right() {
 		if fn(node.Block) == SearchStop { return SearchStop }
 	}
 
@@ -228,3 +248,30 @@ func (self *AugmentedTree) recursiveEachInXRange(node *augTreeNode, minX, maxX u
 	}
 	return SearchContinue
 }
+
+// Like EachInXRange(), but also filtering vertically. Only blocks that
+// overlap the given rect are passed to the function. The vertical
+// bounds of each subtree are tracked too, so whole branches can be
+// skipped when they are above or below the rect.
+func (self *AugmentedTree) EachInRect(rect u16.Rect, fn func(block.Block) SearchControl) {
+	if rect.Empty() { return }
+	_ = self.recursiveEachInRect(self.root, rect, fn)
+}
+
+func (self *AugmentedTree) recursiveEachInRect(node *augTreeNode, rect u16.Rect, fn func(block.Block) SearchControl) SearchControl {
+	if node == nil { return SearchContinue }
+	if rect.Min.X >= node.MaxX { return SearchContinue } // no possible overlap in this sub-branch
+	if rect.Min.Y >= node.MaxY || rect.Max.Y <= node.MinY { return SearchContinue }
+	if rect.Max.X > node.Block.X && rect.Min.X < node.Block.Right() {
+		if rect.Min.Y < node.Block.Bottom() && rect.Max.Y > node.Block.Y {
+			if fn(node.Block) == SearchStop { return SearchStop }
+		}
+	}
+
+	// check recursively on left and right branches
+	if self.recursiveEachInRect(node.Left, rect, fn) == SearchStop { return SearchStop }
+	if rect.Max.X > node.Block.X {
+		return self.recursiveEachInRect(node.Right, rect, fn)
+	}
+	return SearchContinue
+}
@@ -4,12 +4,15 @@ import "github.com/tinne26/transition/src/game/level/block"
 
 func maxu16(a, b uint16) uint16 { if a >= b { return a } ; return b }
 func maxi16(a, b  int16)  int16 { if a >= b { return a } ; return b }
+func minu16(a, b uint16) uint16 { if a <= b { return a } ; return b }
 
 type augTreeNode struct {
 	Left *augTreeNode
 	Right *augTreeNode
 	Block block.Block
 	MaxX uint16
+	MinY uint16 // min top of the subtree, for 2D queries
+	MaxY uint16 // max bottom of the subtree, for 2D queries
 	Height int16
 }
 
@@ -17,6 +20,8 @@ func newAugTreeNode(lvlBlock block.Block) *augTreeNode {
 	return &augTreeNode{
 		Block: lvlBlock,
 		MaxX: lvlBlock.Right(),
+		MinY: lvlBlock.Y,
+		MaxY: lvlBlock.Bottom(),
 	}
 }
 
@@ -30,6 +35,11 @@ func (self *augTreeNode) GetMaxX() uint16 {
 	return self.MaxX
 }
 
+func (self *augTreeNode) count() int {
+	if self == nil { return 0 }
+	return 1 + self.Left.count() + self.Right.count()
+}
+
 func (self *augTreeNode) GetBalance() int16 {
 	return self.Right.GetHeight() - self.Left.GetHeight()
 }
@@ -43,17 +53,24 @@ func (self *augTreeNode) RefreshMaxX() {
 	self.MaxX = maxu16(self.MaxX, self.Left.GetMaxX())
 }
 
-// Both RefreshHeight() and RefreshMaxX() at once.
+// Both RefreshHeight() and RefreshMaxX() at once, plus
+// the vertical bounds.
 func (self *augTreeNode) Refresh() {	
 	self.MaxX   = self.Block.Right()
+	self.MinY   = self.Block.Y
+	self.MaxY   = self.Block.Bottom()
 	self.Height = 0
 	if self.Left != nil {
 		self.Height = self.Left.Height + 1
 		self.MaxX   = maxu16(self.MaxX, self.Left.MaxX)
+		self.MinY   = minu16(self.MinY, self.Left.MinY)
+		self.MaxY   = maxu16(self.MaxY, self.Left.MaxY)
 	}
 	if self.Right != nil {
-		self.Height = self.Right.Height + 1
+		self.Height = maxi16(self.Height, self.Right.Height + 1)
 		self.MaxX   = maxu16(self.MaxX, self.Right.MaxX)
+		self.MinY   = minu16(self.MinY, self.Right.MinY)
+		self.MaxY   = maxu16(self.MaxY, self.Right.MaxY)
 	}
 }
 
@@ -64,6 +81,8 @@ func (self *augTreeNode) NewLeftChild(lvlBlock block.Block) {
 
 	if self.Right == nil { self.Height += 1 }
 	self.MaxX = maxu16(lvlBlock.Right(), self.MaxX)
+	self.MinY = minu16(lvlBlock.Y, self.MinY)
+	self.MaxY = maxu16(lvlBlock.Bottom(), self.MaxY)
 }
 
 func (self *augTreeNode) NewRightChild(lvlBlock block.Block) {
@@ -71,6 +90,8 @@ func (self *augTreeNode) NewRightChild(lvlBlock block.Block) {
 
 	if self.Left == nil { self.Height += 1 }
 	self.MaxX = maxu16(lvlBlock.Right(), self.MaxX)
+	self.MinY = minu16(lvlBlock.Y, self.MinY)
+	self.MaxY = maxu16(lvlBlock.Bottom(), self.MaxY)
 }
 
 // ---- rebalancing ----
@@ -84,12 +105,12 @@ func (self *augTreeNode) NewRightChild(lvlBlock block.Block) {
 func (self *augTreeNode) Rebalance() *augTreeNode {
 	balance := self.GetBalance()
 	if balance < -1 { // tree leaning left
-		if self.Left.GetBalance() >= 0 {
+		if self.Left.GetBalance() > 0 { // left-right case
 			self.Left = self.Left.rotateLeft()
 		}
 		return self.rotateRight()
 	} else if balance > 1 { // tree leaning right
-		if self.Right.GetBalance() <= 0 {
+		if self.Right.GetBalance() < 0 { // right-left case
 			self.Right = self.Right.rotateRight()
 		}	
 		return self.rotateLeft()
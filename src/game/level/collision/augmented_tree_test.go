@@ -0,0 +1,309 @@
+package collision
+
+import "os"
+import "sort"
+import "sync"
+import "testing"
+import "math/rand"
+
+import "github.com/tinne26/transition/src/game/level/block"
+import "github.com/tinne26/transition/src/game/u16"
+
+var loadBlockTypesOnce sync.Once
+var loadBlockTypesErr error
+
+// Blocks need their types to know their sizes, so the block
+// types have to be created from the assets before testing.
+func loadBlockTypes(tb testing.TB) {
+	loadBlockTypesOnce.Do(func() {
+		loadBlockTypesErr = block.CreateAll(os.DirFS("../../../.."))
+	})
+	if loadBlockTypesErr != nil { tb.Fatal(loadBlockTypesErr) }
+}
+
+// Returns n random blocks within the given area.
+func randomBlocks(rng *rand.Rand, n int, area u16.Rect) []block.Block {
+	blocks := make([]block.Block, n)
+	for i, _ := range blocks {
+		blocks[i] = block.NewBlock(block.ID(rng.Intn(block.NumTypes())))
+		x := area.Min.X + uint16(rng.Intn(int(area.Width())))
+		y := area.Min.Y + uint16(rng.Intn(int(area.Height())))
+		blocks[i].At(x, y)
+	}
+	return blocks
+}
+
+// Checks the AVL balance, the heights, the ordering and the
+// augmented bounds of every node in the tree.
+func checkTreeInvariants(t *testing.T, tree *AugmentedTree) {
+	t.Helper()
+	var blocks []block.Block
+	augmentedTreeRecursiveInorder(tree.root, &blocks)
+	for i := 1; i < len(blocks); i++ {
+		if blocks[i].X < blocks[i - 1].X {
+			t.Fatalf("blocks not sorted by X: %d after %d", blocks[i].X, blocks[i - 1].X)
+		}
+	}
+	checkNodeInvariants(t, tree.root)
+}
+
+func checkNodeInvariants(t *testing.T, node *augTreeNode) {
+	t.Helper()
+	if node == nil { return }
+	checkNodeInvariants(t, node.Left)
+	checkNodeInvariants(t, node.Right)
+
+	height := maxi16(node.Left.GetHeight(), node.Right.GetHeight()) + 1
+	if node.Height != height { t.Fatalf("node at x = %d has height %d, expected %d", node.Block.X, node.Height, height) }
+	balance := node.GetBalance()
+	if balance < -1 || balance > 1 { t.Fatalf("node at x = %d unbalanced (%d)", node.Block.X, balance) }
+
+	maxX, minY, maxY := node.Block.Right(), node.Block.Y, node.Block.Bottom()
+	for _, child := range []*augTreeNode{ node.Left, node.Right } {
+		if child == nil { continue }
+		maxX = maxu16(maxX, child.MaxX)
+		minY = minu16(minY, child.MinY)
+		maxY = maxu16(maxY, child.MaxY)
+	}
+	if node.MaxX != maxX || node.MinY != minY || node.MaxY != maxY {
+		t.Fatalf("node at x = %d has MaxX/MinY/MaxY %d/%d/%d, expected %d/%d/%d",
+			node.Block.X, node.MaxX, node.MinY, node.MaxY, maxX, minY, maxY)
+	}
+}
+
+// Compares the blocks found by the tree with the expected ones,
+// ignoring the order.
+func checkSameBlocks(t *testing.T, context string, got, expected []block.Block) {
+	t.Helper()
+	counts := make(map[block.Block]int, len(expected))
+	for _, blck := range expected { counts[blck] += 1 }
+	for _, blck := range got { counts[blck] -= 1 }
+	for blck, count := range counts {
+		if count == 0 { continue }
+		t.Fatalf("%s: got %d blocks, expected %d (mismatch at %d, %d)", context, len(got), len(expected), blck.X, blck.Y)
+	}
+}
+
+func collectInXRange(tree *AugmentedTree, minX, maxX uint16) []block.Block {
+	var blocks []block.Block
+	tree.EachInXRange(minX, maxX, func(blck block.Block) SearchControl {
+		blocks = append(blocks, blck)
+		return SearchContinue
+	})
+	return blocks
+}
+
+func collectInRect(tree *AugmentedTree, rect u16.Rect) []block.Block {
+	var blocks []block.Block
+	tree.EachInRect(rect, func(blck block.Block) SearchControl {
+		blocks = append(blocks, blck)
+		return SearchContinue
+	})
+	return blocks
+}
+
+// Brute force versions of the queries. Note that EachInXRange()
+// doesn't include blocks entirely to the left of minX.
+func bruteInXRange(blocks []block.Block, minX, maxX uint16) []block.Block {
+	var found []block.Block
+	for _, blck := range blocks {
+		if maxX > blck.X && minX < blck.Right() { found = append(found, blck) }
+	}
+	return found
+}
+
+func bruteInRect(blocks []block.Block, rect u16.Rect) []block.Block {
+	if rect.Empty() { return nil }
+	var found []block.Block
+	for _, blck := range blocks {
+		if rect.Max.X <= blck.X || rect.Min.X >= blck.Right() { continue }
+		if rect.Max.Y <= blck.Y || rect.Min.Y >= blck.Bottom() { continue }
+		found = append(found, blck)
+	}
+	return found
+}
+
+func TestAugmentedTreeInvariants(t *testing.T) {
+	loadBlockTypes(t)
+	rng := rand.New(rand.NewSource(7))
+	area := u16.NewRect(1000, 1000, 3000, 2000)
+	blocks := randomBlocks(rng, 300, area)
+
+	// add one by one, including sorted and reverse sorted runs,
+	// which are the worst cases for unbalanced trees
+	tree := NewAugmentedTree()
+	for i, blck := range blocks {
+		tree.Add(blck)
+		checkTreeInvariants(t, tree)
+		if tree.Len() != i + 1 { t.Fatalf("tree has %d blocks, expected %d", tree.Len(), i + 1) }
+	}
+	for i := 0; i < 64; i++ {
+		blck := block.NewBlock(0)
+		blck.At(4000 + uint16(i)*3, 1000)
+		tree.Add(blck)
+		blocks = append(blocks, blck)
+		checkTreeInvariants(t, tree)
+		blck.At(900 - uint16(i)*3, 1500)
+		tree.Add(blck)
+		blocks = append(blocks, blck)
+		checkTreeInvariants(t, tree)
+	}
+
+	// remove in random order
+	rng.Shuffle(len(blocks), func(i, j int) { blocks[i], blocks[j] = blocks[j], blocks[i] })
+	for len(blocks) > 0 {
+		if !tree.Remove(blocks[0]) { t.Fatalf("failed to remove block at (%d, %d)", blocks[0].X, blocks[0].Y) }
+		blocks = blocks[1 : ]
+		checkTreeInvariants(t, tree)
+		if tree.Len() != len(blocks) { t.Fatalf("tree has %d blocks, expected %d", tree.Len(), len(blocks)) }
+		if len(blocks) % 37 == 0 {
+			checkSameBlocks(t, "Each()", collectInXRange(tree, 0, 65535), blocks)
+		}
+	}
+	if tree.Remove(block.NewBlock(0)) { t.Fatal("removed block from an empty tree") }
+
+	// bulk construction and stabilization
+	blocks = randomBlocks(rng, 200, area)
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].X < blocks[j].X })
+	tree = BuildFromSorted(blocks)
+	checkTreeInvariants(t, tree)
+	checkSameBlocks(t, "BuildFromSorted()", collectInXRange(tree, 0, 65535), blocks)
+	tree = NewAugmentedTree()
+	for _, blck := range blocks { tree.Add(blck) }
+	tree.Stabilize()
+	checkTreeInvariants(t, tree)
+	checkSameBlocks(t, "Stabilize()", collectInXRange(tree, 0, 65535), blocks)
+}
+
+func TestAugmentedTreeQueries(t *testing.T) {
+	loadBlockTypes(t)
+	rng := rand.New(rand.NewSource(8))
+	area := u16.NewRect(1000, 1000, 3000, 2000)
+	blocks := randomBlocks(rng, 400, area)
+	tree := NewAugmentedTree()
+	for _, blck := range blocks { tree.Add(blck) }
+
+	for i := 0; i < 500; i++ {
+		minX := uint16(900 + rng.Intn(2200))
+		maxX := minX + uint16(rng.Intn(200))
+		checkSameBlocks(t, "EachInXRange()", collectInXRange(tree, minX, maxX), bruteInXRange(blocks, minX, maxX))
+		minY := uint16(900 + rng.Intn(1200))
+		rect := u16.NewRect(minX, minY, maxX, minY + uint16(rng.Intn(200)))
+		checkSameBlocks(t, "EachInRect()", collectInRect(tree, rect), bruteInRect(blocks, rect))
+	}
+
+	// blocks entirely to the left of minX are not included
+	blck := block.NewBlock(0)
+	blck.At(100, 100)
+	tree = NewAugmentedTree()
+	tree.Add(blck)
+	if len(collectInXRange(tree, blck.Right(), blck.Right() + 10)) != 0 {
+		t.Fatal("EachInXRange() included a block to the left of the range")
+	}
+	if len(collectInXRange(tree, blck.Right() - 1, blck.Right() + 10)) != 1 {
+		t.Fatal("EachInXRange() missed a block overlapping the range")
+	}
+}
+
+// Each operation takes 4 bytes: the operation, and 3 arguments.
+// Coordinates are kept in a small area so blocks overlap and
+// share positions often.
+func FuzzAugmentedTree(f *testing.F) {
+	f.Add([]byte{ 0, 1, 2, 3, 0, 4, 2, 3, 2, 0, 4, 1, 1, 0, 0, 0, 2, 0, 0, 255 })
+	f.Add([]byte{ 0, 0, 10, 10, 0, 0, 10, 10, 0, 0, 10, 10, 1, 1, 0, 0, 2, 9, 2, 2, 3, 0, 0, 0 })
+	f.Add([]byte{ 0, 5, 0, 0, 0, 5, 1, 0, 0, 5, 2, 0, 0, 5, 3, 0, 0, 5, 4, 0, 0, 5, 5, 0, 1, 2, 0, 0, 2, 0, 0, 0 })
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		loadBlockTypes(t)
+		tree := NewAugmentedTree()
+		var blocks []block.Block
+		for i := 0; i + 4 <= len(ops); i += 4 {
+			a, b, c := ops[i + 1], ops[i + 2], ops[i + 3]
+			switch ops[i] % 4 {
+			case 0: // add
+				blck := block.NewBlock(block.ID(int(a) % block.NumTypes()))
+				blck.At(1000 + uint16(b)*8, 1000 + uint16(c)*8)
+				tree.Add(blck)
+				blocks = append(blocks, blck)
+			case 1: // remove (existing if possible)
+				blck := block.NewBlock(0)
+				blck.At(1000 + uint16(b)*8, 1000 + uint16(c)*8)
+				if len(blocks) > 0 && a & 1 == 0 {
+					blck = blocks[int(b) % len(blocks)]
+				}
+				index := -1
+				for j, _ := range blocks {
+					if blocks[j] == blck { index = j ; break }
+				}
+				removed := tree.Remove(blck)
+				if removed != (index != -1) {
+					t.Fatalf("op #%d: Remove() returned %t for block at (%d, %d)", i/4, removed, blck.X, blck.Y)
+				}
+				if removed { blocks = append(blocks[ : index], blocks[index + 1 : ]...) }
+			case 2: // queries
+				minX := 1000 + uint16(a)*8
+				maxX := minX + uint16(b)*4
+				checkSameBlocks(t, "EachInXRange()", collectInXRange(tree, minX, maxX), bruteInXRange(blocks, minX, maxX))
+				minY := 1000 + uint16(c)*8
+				rect := u16.NewRect(minX, minY, maxX, minY + uint16(b)*4)
+				checkSameBlocks(t, "EachInRect()", collectInRect(tree, rect), bruteInRect(blocks, rect))
+			case 3:
+				tree.Stabilize()
+			}
+			checkTreeInvariants(t, tree)
+			if tree.Len() != len(blocks) { t.Fatalf("op #%d: tree has %d blocks, expected %d", i/4, tree.Len(), len(blocks)) }
+		}
+	})
+}
+
+// --- benchmarks ---
+
+// Player sized query rects over ~10k blocks. EachInXRange() is how
+// player collisions were queried before EachInRect() was added,
+// with the vertical filtering left to the callback.
+
+const benchNumBlocks = 10000
+const benchNumQueries = 1024
+
+func benchTreeAndQueries(b *testing.B) (*AugmentedTree, []u16.Rect) {
+	loadBlockTypes(b)
+	rng := rand.New(rand.NewSource(6))
+	area := u16.NewRect(0, 0, 60000, 20000)
+	tree := NewAugmentedTree()
+	for _, blck := range randomBlocks(rng, benchNumBlocks, area) {
+		tree.Add(blck)
+	}
+	queries := make([]u16.Rect, benchNumQueries)
+	for i, _ := range queries {
+		x := uint16(rng.Intn(int(area.Max.X)))
+		y := uint16(rng.Intn(int(area.Max.Y)))
+		queries[i] = u16.NewRect(x, y, x + 20, y + 60)
+	}
+	return tree, queries
+}
+
+func BenchmarkEachInXRange(b *testing.B) {
+	tree, queries := benchTreeAndQueries(b)
+	count := 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rect := queries[i % len(queries)]
+		tree.EachInXRange(rect.Min.X, rect.Max.X, func(blck block.Block) SearchControl {
+			if rect.Min.Y < blck.Bottom() && rect.Max.Y > blck.Y { count += 1 }
+			return SearchContinue
+		})
+	}
+	_ = count
+}
+
+func BenchmarkQueryRect(b *testing.B) {
+	tree, queries := benchTreeAndQueries(b)
+	count := 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.EachInRect(queries[i % len(queries)], func(blck block.Block) SearchControl {
+			count += 1
+			return SearchContinue
+		})
+	}
+	_ = count
+}
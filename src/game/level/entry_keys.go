@@ -36,7 +36,21 @@ func GetEntryPoint(key lvlkey.EntryKey) (*Level, u16.Point) {
 	return lvl, pt
 }
 
+// Returns whether the key corresponds to an entry point that has been
+// set. Useful to validate keys coming from external sources like saves.
+func IsValidEntryKey(key lvlkey.EntryKey) bool {
+	if key == lvlkey.Undefined || key >= entryKeyEndSentinel { return false }
+	return allEntryLevels[key] != nil
+}
+
 func SetEntryPoint(key lvlkey.EntryKey, level *Level, x, y uint16) {
 	allEntries[key] = u16.Point{X: x, Y: y}
 	allEntryLevels[key] = level
 }
+
+// Returns the entry key with the given name, as used in level files
+// (e.g. "start_save_left").
+func LookupEntryKey(name string) (lvlkey.EntryKey, bool) {
+	key, found := lvlFileEntryKeys[name]
+	return key, found
+}
@@ -14,8 +14,6 @@ import "github.com/tinne26/transition/src/game/u16"
 import "github.com/tinne26/transition/src/utils"
 import "github.com/tinne26/transition/src/project"
 
-// TODO: use the augmented tree for collisions and iteration instead of brute forcing
-
 type Level struct {
 	limits u16.Rect
 	
@@ -323,6 +321,14 @@ func (self *Level) EachBlockInRange(rangeMin, rangeMax uint16, fn func(block.Blo
 	})
 }
 
+// Calls the given function for each main block overlapping the given rect.
+func (self *Level) QueryRect(rect u16.Rect, fn func(block.Block) IterationControl) {
+	self.blocks.EachInRect(rect, func(levelBlock block.Block) collision.SearchControl {
+		if fn(levelBlock) == IterationStop { return collision.SearchStop }
+		return collision.SearchContinue
+	})
+}
+
 // --- events API ---
 
 // ...
@@ -0,0 +1,61 @@
+package level
+
+import "testing"
+import "math/rand"
+
+import "github.com/tinne26/transition/src/game/level/block"
+import "github.com/tinne26/transition/src/game/u16"
+
+// Player-sized query rects spread over all the levels, with the
+// level they must be run on.
+type benchQuery struct {
+	lvl *Level
+	rect u16.Rect
+}
+
+func benchLevelQueries(b *testing.B) []benchQuery {
+	b.Helper()
+	err := CreateAll(testFS)
+	if err != nil { b.Fatal(err) }
+	rng := rand.New(rand.NewSource(7))
+	queries := make([]benchQuery, 0, 1024)
+	for len(queries) < cap(queries) {
+		lvl := Get(Key(rng.Intn(NumLevels())))
+		limits := lvl.GetLimits()
+		x := limits.Min.X + uint16(rng.Intn(int(limits.Width()) - 32))
+		y := limits.Min.Y + uint16(rng.Intn(int(limits.Height()) - 64))
+		queries = append(queries, benchQuery{ lvl, u16.NewRect(x, y, x + 19, y + 51) })
+	}
+	return queries
+}
+
+// The player collision path before QueryRect(): iterate the blocks
+// in the horizontal range and discard the vertical misses manually.
+func BenchmarkLevelEachBlockInRange(b *testing.B) {
+	queries := benchLevelQueries(b)
+	count := 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		query := queries[i % len(queries)]
+		rect := query.rect
+		query.lvl.EachBlockInRange(rect.Min.X, rect.Max.X, func(blck block.Block) IterationControl {
+			if rect.Min.Y < blck.Bottom() && rect.Max.Y > blck.Y { count += 1 }
+			return IterationContinue
+		})
+	}
+	_ = count
+}
+
+func BenchmarkLevelQueryRect(b *testing.B) {
+	queries := benchLevelQueries(b)
+	count := 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		query := queries[i % len(queries)]
+		query.lvl.QueryRect(query.rect, func(blck block.Block) IterationControl {
+			count += 1
+			return IterationContinue
+		})
+	}
+	_ = count
+}
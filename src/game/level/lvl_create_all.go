@@ -3,6 +3,7 @@ package level
 import "io/fs"
 
 import "github.com/tinne26/transition/src/game/level/block"
+import "github.com/tinne26/transition/src/game/dialogue"
 
 var allLevels []*Level
 
@@ -16,29 +17,67 @@ var (
 	LvlGate Key // The White Gate
 )
 
+// Level files, in creation order. The files are edited by hand. The
+// old Go builders are only kept around as a reference, and the tests
+// check that both still create the same levels.
+var levelFiles = []struct {
+	key *Key
+	path string
+	build func() *Level
+}{
+	{ &LvlStart, "assets/levels/start.lvl", CreateStartLevel },
+	{ &LvlSword, "assets/levels/sword.lvl", CreateSwordLevel },
+}
+
 func Get(key Key) *Level {
 	return allLevels[key]
 }
 
+// Returns the number of created levels. Keys go from 0 to NumLevels() - 1.
+func NumLevels() int {
+	return len(allLevels)
+}
+
+// Returns the path of the level file associated to the given key.
+func FilePath(key Key) string {
+	for _, lvlFile := range levelFiles {
+		if *lvlFile.key == key { return lvlFile.path }
+	}
+	panic(key)
+}
+
 func CreateAll(filesys fs.FS) error {
-	// create blocks first
+	// create blocks and load dialogue scripts first
 	err := block.CreateAll(filesys)
 	if err != nil { return err }
+	err = dialogue.LoadAll(filesys)
+	if err != nil { return err }
 
-	// --- define level entries ---
-	var lvl *Level
-	
-	// start level
-	lvl = CreateStartLevel()
-	LvlStart = Key(len(allLevels))
-	allLevels = append(allLevels, lvl)
+	// load levels from their files
+	allLevels = allLevels[ : 0]
+	for _, lvlFile := range levelFiles {
+		lvl, err := LoadFile(filesys, lvlFile.path)
+		if err != nil { return err }
+		*lvlFile.key = Key(len(allLevels))
+		allLevels = append(allLevels, lvl)
+	}
 
-	// sword level
-	lvl = CreateSwordLevel()
-	LvlSword = Key(len(allLevels))
-	allLevels = append(allLevels, lvl)
+	return nil
+}
+
+// Like CreateAll(), but using the old Go level builders instead
+// of the level files. Only meant for cmd/lvldump and tests.
+func CreateAllFromCode(filesys fs.FS) error {
+	err := block.CreateAll(filesys)
+	if err != nil { return err }
+	err = dialogue.LoadAll(filesys)
+	if err != nil { return err }
 
-	// ...
+	allLevels = allLevels[ : 0]
+	for _, lvlFile := range levelFiles {
+		*lvlFile.key = Key(len(allLevels))
+		allLevels = append(allLevels, lvlFile.build())
+	}
 
 	return nil
 }
@@ -0,0 +1,132 @@
+package level
+
+import "bytes"
+import "reflect"
+import "testing"
+
+import "github.com/tinne26/transition/src/game/level/block"
+import "github.com/tinne26/transition/src/game/level/lvlkey"
+import "github.com/tinne26/transition/src/game/trigger"
+import "github.com/tinne26/transition/src/game/u16"
+
+// Everything the level files define, in comparable form.
+type levelSnapshot struct {
+	layers map[string][]block.Block
+	savepoints []block.Block
+	entries map[lvlkey.EntryKey]u16.Point
+	triggers []trigger.Trigger
+	limits u16.Rect
+}
+
+func takeLevelSnapshots() []levelSnapshot {
+	snapshots := make([]levelSnapshot, NumLevels())
+	for i, _ := range snapshots {
+		lvl := Get(Key(i))
+		snapshots[i] = levelSnapshot{
+			layers: map[string][]block.Block{
+				"parallax": sortedTreeBlocks(lvl.parallaxBlocks),
+				"behind": sortedTreeBlocks(lvl.decorsBehindPlayer),
+				"main": sortedTreeBlocks(lvl.blocks),
+				"front": sortedTreeBlocks(lvl.decorsInFrontPlayer),
+			},
+			savepoints: append([]block.Block(nil), lvl.savepoints...),
+			entries: make(map[lvlkey.EntryKey]u16.Point),
+			triggers: append([]trigger.Trigger(nil), lvl.triggers...),
+			limits: lvl.limits,
+		}
+		for key := lvlkey.Undefined + 1; key < entryKeyEndSentinel; key++ {
+			if allEntryLevels[key] == lvl { snapshots[i].entries[key] = allEntries[key] }
+		}
+	}
+	return snapshots
+}
+
+// The committed level files must match the Go level builders. The
+// files are edited by hand, so if this fails, the file or the builder
+// must be fixed. "go run ./cmd/lvldump" prints the builder exports,
+// which may help find the differences.
+func TestLevelFilesMatchCode(t *testing.T) {
+	err := CreateAll(testFS)
+	if err != nil { t.Fatal(err) }
+	fromFiles := takeLevelSnapshots()
+	err = CreateAllFromCode(testFS)
+	if err != nil { t.Fatal(err) }
+	fromCode := takeLevelSnapshots()
+	if len(fromFiles) != len(fromCode) {
+		t.Fatalf("%d levels loaded from files, %d created from code", len(fromFiles), len(fromCode))
+	}
+
+	for i, _ := range fromFiles {
+		compareSnapshots(t, levelFiles[i].path, fromFiles[i], fromCode[i])
+	}
+}
+
+// Exporting the code-built levels and loading the exports back
+// must give the same levels (like "go run ./cmd/lvldump --check").
+func TestExportRoundTrip(t *testing.T) {
+	err := CreateAllFromCode(testFS)
+	if err != nil { t.Fatal(err) }
+	fromCode := takeLevelSnapshots()
+
+	// export all levels first (loading levels modifies entry points)
+	exports := make([][]byte, NumLevels())
+	for i, _ := range exports {
+		var buffer bytes.Buffer
+		err = Get(Key(i)).Export(&buffer)
+		if err != nil { t.Fatalf("%s: %s", levelFiles[i].path, err) }
+		exports[i] = buffer.Bytes()
+	}
+	for i, export := range exports {
+		lvl, err := Load(bytes.NewReader(export), levelFiles[i].path)
+		if err != nil { t.Fatal(err) }
+		allLevels[i] = lvl
+	}
+
+	fromExports := takeLevelSnapshots()
+	for i, _ := range fromExports {
+		compareSnapshots(t, levelFiles[i].path + " (export)", fromExports[i], fromCode[i])
+
+		// exports of loaded levels must be stable too
+		var buffer bytes.Buffer
+		err = Get(Key(i)).Export(&buffer)
+		if err != nil { t.Fatal(err) }
+		if !bytes.Equal(buffer.Bytes(), exports[i]) {
+			t.Errorf("%s: exporting the loaded export gives a different file", levelFiles[i].path)
+		}
+	}
+}
+
+func compareSnapshots(t *testing.T, path string, got, expected levelSnapshot) {
+	t.Helper()
+	for name, blocks := range expected.layers {
+		compareBlocks(t, path + " (layer " + name + ")", got.layers[name], blocks)
+	}
+	compareBlocks(t, path + " (savepoints)", got.savepoints, expected.savepoints)
+	if !reflect.DeepEqual(got.entries, expected.entries) {
+		t.Errorf("%s: entries %v, expected %v", path, got.entries, expected.entries)
+	}
+	if len(got.triggers) != len(expected.triggers) {
+		t.Errorf("%s: %d triggers, expected %d", path, len(got.triggers), len(expected.triggers))
+	} else {
+		for j, _ := range expected.triggers {
+			if reflect.DeepEqual(got.triggers[j], expected.triggers[j]) { continue }
+			t.Errorf("%s: trigger #%d is %#v, expected %#v", path, j, got.triggers[j], expected.triggers[j])
+		}
+	}
+	if got.limits != expected.limits {
+		t.Errorf("%s: limits %v, expected %v", path, got.limits, expected.limits)
+	}
+}
+
+func compareBlocks(t *testing.T, context string, got, expected []block.Block) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Errorf("%s: %d blocks, expected %d", context, len(got), len(expected))
+		return
+	}
+	for i, _ := range expected {
+		if got[i] == expected[i] { continue }
+		t.Errorf("%s: block #%d is %s at (%d, %d), expected %s at (%d, %d)", context, i,
+			got[i].Type().Name, got[i].X, got[i].Y, expected[i].Type().Name, expected[i].X, expected[i].Y)
+	}
+}
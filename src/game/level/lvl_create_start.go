@@ -212,10 +212,7 @@ func CreateStartLevel() *Level {
 		trigger.NewShowTip(
 			u16.NewRect(base.X - Hop*5, base.Y - Hop*5, base.Right() + Hop*5, base.Bottom() + Hop*5),
 			u16.NewRect(base.X - Hop*8, base.Y - Hop*8, base.Right() + Hop*8, base.Bottom() + Hop*8),
-			text.NewSkippableMsg1(
-				"USE " + string(text.KeyD) + " TO MOVE RIGHT, " + string(text.KeyA) + " TO MOVE LEFT",
-				clr.WingsText,
-			),
+			text.NewSkippableWrappedMsg("@tip.move", clr.WingsText),
 			state.SwitchTipMove,
 		),
 	)
@@ -224,10 +221,7 @@ func CreateStartLevel() *Level {
 		trigger.NewShowTip(
 			u16.NewRect(jmp1.Right() - Hop*3, jmp1.Y - Hop*8, jmp1.Right() + Hop*6, jmp1.Y),
 			u16.NewRect(jmp2.Right() + Hop*3, jmp2.Y - Hop*8, jmp2.Right() + Hop*8, jmp2.Bottom() + Hop*0),
-			text.NewSkippableMsg1(
-				"HOLD " + string(text.KeyK) + " TO JUMP. PRESS AGAIN IN THE AIR TO USE YOUR WINGS",
-				clr.WingsText,
-			),
+			text.NewSkippableWrappedMsg("@tip.jump", clr.WingsText),
 			state.SwitchTipJump,
 		),
 	)
@@ -236,25 +230,7 @@ func CreateStartLevel() *Level {
 		trigger.NewInteractText(
 			u16.NewRect(stone.X - Hop*1, stone.Y - Hop*2, stone.Right() + Hop*1, stone.Y),
 			hint.NewHint(hint.TypeInteract, stone.CenterX(), stone.Y - 4),
-			[]string{
-				"\"EVERYTHING IS AN IMAGE\"",
-				"",
-				"THESE ARE THE WORDS THAT STARTED IT ALL.",
-				"PIXEL BY PIXEL, COMMIT BY COMMIT, DAY BY DAY,",
-				"FROM THE HANDS OF HAJIME HOSHI HIMSELF.",
-				"",
-				"STEP, AFTER STEP, AFTER STEP.",
-				"",
-				"...",
-				"",
-				"AND TEN YEARS PASSED",
-				"",
-				"AND SUBTLY, THE WORLD WAS CHANGED",
-				"",
-				"...",
-				"",
-				"[PRESS " + string(text.KeyI) + " TO CONTINUE]",
-			}),
+			[]string{"@text.first_stone"}),
 	)
 
 	transfX := flr1.Right() - Hop*3
@@ -9,6 +9,7 @@ import "github.com/tinne26/transition/src/game/clr"
 import "github.com/tinne26/transition/src/game/trigger"
 import "github.com/tinne26/transition/src/game/hint"
 import "github.com/tinne26/transition/src/game/sword"
+import "github.com/tinne26/transition/src/game/dialogue"
 import "github.com/tinne26/transition/src/text"
 import "github.com/tinne26/transition/src/game/u16"
 
@@ -73,7 +74,7 @@ func CreateSwordLevel() *Level {
 
 	// center isolated platform decors
 	_ = blocks.Add(block.TypeDecorAxe_A).CenterAbove(isld).MoveLeft(Hop*1)
-	_ = blocks.Add(block.TypeDecorSkeleton_A).CenterAbove(isld).MoveRight(Hop*1)
+	skel := blocks.Add(block.TypeDecorSkeleton_A).CenterAbove(isld).MoveRight(Hop*1)
 	_ = blocks.Add(block.TypeDecorBackSkull_A).CenterAbove(isld).MoveLeft(Hop/2)
 	_ = blocks.Add(block.TypeDecorBackSpear_A).CenterAbove(isld).MoveRight(Hop*1)
 	
@@ -131,15 +132,20 @@ func CreateSwordLevel() *Level {
 		trigger.NewShowTip(
 			u16.NewRect(leftArea.Right() - Hop*12, leftArea.Y - Hop*1, leftArea.Right(), leftArea.Bottom()),
 			u16.NewRect(centerArea.X + Hop*2, centerArea.Y - Hop*8, centerArea.Right(), centerArea.Y),
-			text.NewSkippableMsg2(
-				"YOU CAN BRIEFLY ATTACH TO WALLS WHILE JUMPING, BUT...",
-				"YOU MAY SLIP IF YOUR DOWNWARD MOMENTUM IS TOO HIGH",
-				clr.WingsText,
-			),
+			text.NewSkippableWrappedMsg("@tip.wall_stick", clr.WingsText),
 			state.SwitchTipWallStick,
 		),
 	)
 
+	// skeleton dialogue
+	level.AddTrigger(
+		trigger.NewDialogue(
+			u16.NewRect(skel.X - Hop*2, skel.Y - Hop*2, skel.Right() + Hop*2, isld.Y),
+			hint.NewHint(hint.TypeDots, skel.CenterX(), skel.Y - 4),
+			dialogue.Get("skeleton"),
+		),
+	)
+
 	// sword challenge trigger (not a challenge, I made it for dummies
 	// and you can't die, just get stuck forever because you can't read)
 	swordTriggerRect := u16.NewRect(
@@ -0,0 +1,250 @@
+package level
+
+import "io"
+import "sort"
+import "bufio"
+import "errors"
+import "reflect"
+import "strconv"
+import "strings"
+import "image/color"
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+import "github.com/tinne26/transition/src/game/level/block"
+import "github.com/tinne26/transition/src/game/level/collision"
+import "github.com/tinne26/transition/src/game/level/lvlkey"
+import "github.com/tinne26/transition/src/game/state"
+import "github.com/tinne26/transition/src/game/trigger"
+import "github.com/tinne26/transition/src/game/hint"
+import "github.com/tinne26/transition/src/game/clr"
+import "github.com/tinne26/transition/src/game/u16"
+
+// Writes the level in the level file format (see lvl_file_load.go).
+// All blocks are written with absolute positions and sorted, so the
+// output is deterministic and can be compared directly. Entry points
+// are taken from the ones currently assigned to the level.
+func (self *Level) Export(writer io.Writer) error {
+	out := lvlWriter{ writer: bufio.NewWriter(writer) }
+
+	// background
+	out.line("back_color", rgbArgs(self.backColor)...)
+	for _, rgba := range self.backMaskColors {
+		out.line("mask_color", rgbArgs(rgba)...)
+	}
+	self.backMasks.Each(func(mask *ebiten.Image, prob float64) {
+		name, found := lvlFileMaskName(mask)
+		if !found { out.fail(errors.New("mask without level file name")); return }
+		out.line("mask", name, strconv.FormatFloat(prob, 'g', -1, 64))
+	})
+
+	// block layers
+	layers := []struct{ name string; tree *collision.AugmentedTree }{
+		{ "parallax", self.parallaxBlocks },
+		{ "behind", self.decorsBehindPlayer },
+		{ "main", self.blocks },
+		{ "front", self.decorsInFrontPlayer },
+	}
+	for _, layer := range layers {
+		blocks := sortedTreeBlocks(layer.tree)
+		if len(blocks) == 0 { continue }
+		out.blank()
+		out.line("layer", layer.name)
+		for i, _ := range blocks {
+			out.block("_ =", &blocks[i])
+		}
+	}
+
+	// savepoints
+	if len(self.savepoints) > 0 { out.blank() }
+	for i, _ := range self.savepoints {
+		out.block("save " + lvlFileSaveName(i) + " =", &self.savepoints[i])
+	}
+
+	// entry points
+	out.blank()
+	for key := lvlkey.Undefined + 1; key < entryKeyEndSentinel; key++ {
+		if allEntryLevels[key] != self { continue }
+		name, found := lvlFileName(lvlFileEntryKeys, key)
+		if !found { return errors.New("entry key " + strconv.Itoa(int(key)) + " without level file name") }
+		pt := allEntries[key]
+		out.line("entry", name, u16Arg(pt.X), u16Arg(pt.Y))
+	}
+
+	// triggers
+	out.blank()
+	for _, trig := range self.triggers {
+		err := self.exportTrigger(&out, trig)
+		if err != nil { return err }
+	}
+
+	// limits
+	out.blank()
+	limits := self.limits
+	out.line("limits",
+		"min_x", u16Arg(limits.Min.X), "min_y", u16Arg(limits.Min.Y),
+		"max_x", u16Arg(limits.Max.X), "max_y", u16Arg(limits.Max.Y),
+	)
+
+	if out.err != nil { return out.err }
+	return out.writer.Flush()
+}
+
+func (self *Level) exportTrigger(out *lvlWriter, trig trigger.Trigger) error {
+	switch trig := trig.(type) {
+	case *trigger.TrigShowTip:
+		msg := trig.Message()
+		if !msg.IsSkippable || msg.IsDialogue { return errors.New("tip messages must be skippable and non-dialogue") }
+		switchName, found := lvlFileSwitchName(trig.ClearedSwitch())
+		if !found { return errors.New("tip switch without level file name") }
+		colorName, found := clr.NameOf(msg.Color)
+		if !found { return errors.New("tip color without level file name") }
+		args := append(rectArgs(trig.Area()), rectArgs(trig.ClearedArea())...)
+		args  = append(args, switchName, colorName)
+		if msg.IsWrapped() {
+			out.line("tip_text", append(args, lvlFileQuote(msg.Text()))...)
+		} else {
+			args = append(args, lvlFileQuote(msg.FirstLine))
+			if msg.HasTwoLines() { args = append(args, lvlFileQuote(msg.SecondLine)) }
+			out.line("tip", args...)
+		}
+	case *trigger.TrigInteractText:
+		trigHint := trig.Hint()
+		hintName, found := lvlFileName(lvlFileHintTypes, trigHint.Type())
+		if !found { return errors.New("interact text hint type without level file name") }
+		hx, hy := trigHint.Position()
+		args := append(rectArgs(trig.Area()), hintName, u16Arg(hx), u16Arg(hy))
+		for _, line := range trig.Text() {
+			args = append(args, lvlFileQuote(line))
+		}
+		out.line("interact_text", args...)
+	case *trigger.TrigDialogue:
+		trigHint := trig.Hint()
+		hintName, found := lvlFileName(lvlFileHintTypes, trigHint.Type())
+		if !found { return errors.New("dialogue hint type without level file name") }
+		hx, hy := trigHint.Position()
+		args := append(rectArgs(trig.Area()), hintName, u16Arg(hx), u16Arg(hy), trig.Script().Name())
+		out.line("dialogue", args...)
+	case *trigger.TrigLevelTransfer:
+		dirName, found := lvlFileName(lvlFileTransferDirs, trig.Dir())
+		if !found { return errors.New("transfer direction without level file name") }
+		keyName, found := lvlFileName(lvlFileEntryKeys, trig.EntryKey())
+		if !found { return errors.New("transfer entry key without level file name") }
+		x, y := trig.Origin()
+		out.line("transfer", dirName, u16Arg(x), u16Arg(y), keyName)
+	case *trigger.TrigSwitchSave:
+		keyName, found := lvlFileName(lvlFileEntryKeys, trig.EntryKey())
+		if !found { return errors.New("switch save entry key without level file name") }
+		for i, _ := range self.savepoints {
+			if reflect.DeepEqual(NewSwitchSaveTrigger(&self.savepoints[i], trig.EntryKey()), trig) {
+				out.line("switch_save", lvlFileSaveName(i), keyName)
+				return nil
+			}
+		}
+		return errors.New("switch save trigger not matching any savepoint")
+	case *trigger.TrigSwordChallenge:
+		trigHint := trig.Hint()
+		if trigHint.Type() != hint.TypeInteract { return errors.New("sword challenge hint must be of interact type") }
+		switchName, found := lvlFileSwitchName(trig.DoneSwitch())
+		if !found { return errors.New("sword challenge switch without level file name") }
+		hx, hy := trigHint.Position()
+		challenge := trig.Challenge()
+		args := append(rectArgs(trig.Area()), u16Arg(hx), u16Arg(hy), u16Arg(challenge.X), u16Arg(challenge.Y), switchName)
+		out.line("sword_challenge", args...)
+	default:
+		return errors.New("trigger type " + reflect.TypeOf(trig).String() + " can't be exported")
+	}
+	return nil
+}
+
+// --- writing helpers ---
+
+type lvlWriter struct {
+	writer *bufio.Writer
+	err error
+}
+
+func (self *lvlWriter) fail(err error) {
+	if self.err == nil { self.err = err }
+}
+
+func (self *lvlWriter) line(statement string, args ...string) {
+	if self.err != nil { return }
+	self.writer.WriteString(statement)
+	for _, arg := range args {
+		self.writer.WriteByte(' ')
+		self.writer.WriteString(arg)
+	}
+	self.writer.WriteByte('\n')
+}
+
+func (self *lvlWriter) blank() {
+	if self.err != nil { return }
+	self.writer.WriteByte('\n')
+}
+
+func (self *lvlWriter) block(prefix string, blck *block.Block) {
+	self.line(prefix, blck.Type().Name, "at", u16Arg(blck.X), u16Arg(blck.Y))
+}
+
+func sortedTreeBlocks(tree *collision.AugmentedTree) []block.Block {
+	var blocks []block.Block
+	tree.Each(func(blck block.Block) collision.SearchControl {
+		blocks = append(blocks, blck)
+		return collision.SearchContinue
+	})
+	sort.Slice(blocks, func(i, j int) bool {
+		a, b := blocks[i], blocks[j]
+		if a.X != b.X { return a.X < b.X }
+		if a.Y != b.Y { return a.Y < b.Y }
+		return a.Type().Name < b.Type().Name
+	})
+	return blocks
+}
+
+func lvlFileSaveName(index int) string {
+	return "svp" + strconv.Itoa(index + 1)
+}
+
+func u16Arg(value uint16) string {
+	return strconv.Itoa(int(value))
+}
+
+func rgbArgs(rgba color.RGBA) []string {
+	return []string{ u16Arg(uint16(rgba.R)), u16Arg(uint16(rgba.G)), u16Arg(uint16(rgba.B)) }
+}
+
+func rectArgs(rect u16.Rect) []string {
+	return []string{ u16Arg(rect.Min.X), u16Arg(rect.Min.Y), u16Arg(rect.Max.X), u16Arg(rect.Max.Y) }
+}
+
+// Quotes the given text, escaping quotes and backslashes and
+// replacing glyphs with their level file placeholders.
+func lvlFileQuote(str string) string {
+	str = strings.ReplaceAll(str, "\\", "\\\\")
+	str = strings.ReplaceAll(str, "\"", "\\\"")
+	str = strings.ReplaceAll(str, "\n", "\\n")
+	for placeholder, glyph := range lvlFileTextGlyphs {
+		str = strings.ReplaceAll(str, string(glyph), placeholder)
+	}
+	return "\"" + str + "\""
+}
+
+// --- reverse name lookups ---
+
+func lvlFileName[T comparable](names map[string]T, value T) (string, bool) {
+	for name, candidate := range names {
+		if candidate == value { return name, true }
+	}
+	return "", false
+}
+
+func lvlFileSwitchName(key state.Switch) (string, bool) {
+	name := key.String()
+	_, found := state.SwitchFromName(name)
+	return name, found
+}
+
+func lvlFileMaskName(mask *ebiten.Image) (string, bool) {
+	return lvlFileName(lvlFileMasks, mask)
+}
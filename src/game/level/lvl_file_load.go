@@ -0,0 +1,516 @@
+package level
+
+import "io"
+import "io/fs"
+import "bufio"
+import "math"
+import "errors"
+import "strconv"
+import "strings"
+import "image/color"
+
+import "github.com/tinne26/transition/src/game/level/block"
+import "github.com/tinne26/transition/src/game/level/lvlkey"
+import "github.com/tinne26/transition/src/game/state"
+import "github.com/tinne26/transition/src/game/trigger"
+import "github.com/tinne26/transition/src/game/sword"
+import "github.com/tinne26/transition/src/game/dialogue"
+import "github.com/tinne26/transition/src/game/hint"
+import "github.com/tinne26/transition/src/game/bckg"
+import "github.com/tinne26/transition/src/game/clr"
+import "github.com/tinne26/transition/src/game/u16"
+import "github.com/tinne26/transition/src/text"
+
+// Level files (.lvl) are line based. Each line is a statement, and
+// '#' starts a comment. Lines ending with '\' continue on the next
+// one. Numeric arguments are integer expressions, which can't contain
+// spaces unless wrapped in parentheses (except on "let" statements,
+// where the whole rest of the line is the expression). They can use
+// the constants OX, OY, Hop and SaveOffsetY, variables defined with
+// "let", and properties of previously named blocks: x, y, left, right,
+// top, bottom, width, height and center_x (e.g. "base.right").
+//
+// Statements:
+//   back_color <r> <g> <b>
+//   mask_color <r> <g> <b>                 (can be repeated)
+//   mask <sq3|sq4|sq5|ebi> <weight>        (can be repeated)
+//   layer <parallax|behind|main|front>
+//   <name> = <block_type> [<op> <args...>]...
+//   save <name> = <block_type> [<op> <args...>]...
+//   let <name> = <expr>
+//   entry <entry_key> <x> <y>
+//   tip <rect> <cleared_rect> <switch> <color> "<line>" ["<line>"]
+//   tip_text <rect> <cleared_rect> <switch> <color> "<text>"   (wrapped)
+//   interact_text <rect> <hint_type> <hint_x> <hint_y> "<line>"...
+//   dialogue <rect> <hint_type> <hint_x> <hint_y> <script>
+//   transfer <left|right> <x> <y> <entry_key>
+//   switch_save <save_name> <entry_key>
+//   sword_challenge <rect> <hint_x> <hint_y> <x> <y> <switch>
+//   limits [<pad|pad_horz|min_x|min_y|max_x|max_y> <value>]...
+//
+// Rects are given as four values: min x, min y, max x and max y.
+// Using "_" as the block name discards it. Block ops mirror the
+// block.Block placement methods: at <x> <y>, at_x <x>, at_y <y>,
+// above <name> <offset>, below <name> <offset>, right_of <name> <offset>,
+// left_of <name> <offset>, right_of_bottom_aligned <name>, center_with
+// <name>, center_above <name>, shift_height_up, shift_height_down,
+// shift_width_left, move_up <n>, move_down <n>, move_left <n> and
+// move_right <n>. Limits start from the area of all the blocks added
+// so far. Colors are clr palette names (see clr.ByName()). Quoted
+// text can use glyph placeholders like {KeyO} and text markup (see
+// the text package). Dialogue scripts are referred
+// to by name (see the dialogue package), and must be loaded first.
+
+type lvlLayer uint8
+const (
+	lvlLayerMain lvlLayer = iota
+	lvlLayerParallax
+	lvlLayerBehind
+	lvlLayerFront
+)
+
+var lvlFileLayers = map[string]lvlLayer{
+	"main": lvlLayerMain,
+	"parallax": lvlLayerParallax,
+	"behind": lvlLayerBehind,
+	"front": lvlLayerFront,
+}
+
+// Loads a level from the given file.
+func LoadFile(filesys fs.FS, path string) (*Level, error) {
+	file, err := filesys.Open(path)
+	if err != nil { return nil, err }
+	defer file.Close()
+	return Load(file, path)
+}
+
+// Loads a level in the level file format. The name is only
+// used to give context to error messages.
+func Load(reader io.Reader, name string) (*Level, error) {
+	loader := newLvlLoader()
+	scanner := bufio.NewScanner(reader)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum += 1
+		startLineNum := lineNum
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		for strings.HasSuffix(line, "\\") && scanner.Scan() {
+			lineNum += 1
+			line = line[ : len(line) - 1] + " " + strings.TrimRight(scanner.Text(), " \t\r")
+		}
+		tokens, err := tokenizeLvlLine(line)
+		if err == nil && len(tokens) > 0 {
+			err = loader.exec(tokens)
+		}
+		if err != nil {
+			return nil, errors.New(name + ":" + strconv.Itoa(startLineNum) + ": " + err.Error())
+		}
+	}
+	if err := scanner.Err(); err != nil { return nil, err }
+	if loader.level.backMasks.Len() == 0 {
+		return nil, errors.New(name + ": at least one background mask is required")
+	}
+	if !loader.hasLimits {
+		loader.level.SetLimits(loader.level.ComputeArea())
+	}
+	return loader.level, nil
+}
+
+type lvlLoader struct {
+	level *Level
+	layer lvlLayer
+	hasLimits bool
+	blocks map[string]block.Block
+	vars map[string]int
+}
+
+func newLvlLoader() *lvlLoader {
+	return &lvlLoader{
+		level: New(color.RGBA{0, 0, 0, 255}, nil, bckg.NewMaskList()),
+		blocks: make(map[string]block.Block, 32),
+		vars: map[string]int{
+			"OX": OX,
+			"OY": OY,
+			"Hop": Hop,
+			"SaveOffsetY": SaveOffsetY,
+		},
+	}
+}
+
+func (self *lvlLoader) exec(tokens []lvlToken) error {
+	// block statements
+	if len(tokens) >= 3 && tokens[1].Text == "=" && !tokens[0].Quoted && tokens[0].Text != "let" {
+		blck, err := self.execBlock(tokens[0].Text, tokens[2 : ])
+		if err != nil { return err }
+		switch self.layer {
+		case lvlLayerMain: self.level.AddBlock(blck)
+		case lvlLayerParallax: self.level.AddParallaxBlock(blck)
+		case lvlLayerBehind: self.level.AddBehindDecor(blck)
+		case lvlLayerFront: self.level.AddFrontDecor(blck)
+		default:
+			panic(self.layer)
+		}
+		return nil
+	}
+
+	// other statements
+	args := tokens[1 : ]
+	switch tokens[0].Text {
+	case "back_color":
+		rgba, err := self.rgbaArgs(args)
+		if err != nil { return err }
+		self.level.backColor = rgba
+	case "mask_color":
+		rgba, err := self.rgbaArgs(args)
+		if err != nil { return err }
+		self.level.backMaskColors = append(self.level.backMaskColors, rgba)
+	case "mask":
+		if len(args) != 2 { return errArgCount("mask", 2) }
+		mask, found := lvlFileMasks[args[0].Text]
+		if !found { return errors.New("unknown mask '" + args[0].Text + "'") }
+		weight, err := strconv.ParseFloat(args[1].Text, 64)
+		if err != nil || weight < 0 { return errors.New("invalid mask weight '" + args[1].Text + "'") }
+		self.level.backMasks.Add(mask, weight)
+	case "layer":
+		if len(args) != 1 { return errArgCount("layer", 1) }
+		layer, found := lvlFileLayers[args[0].Text]
+		if !found { return errors.New("unknown layer '" + args[0].Text + "'") }
+		self.layer = layer
+	case "save":
+		if len(args) < 3 || args[1].Text != "=" { return errors.New("expected 'save <name> = <block_type> ...'") }
+		blck, err := self.execBlock(args[0].Text, args[2 : ])
+		if err != nil { return err }
+		self.level.AddSave(blck)
+	case "let":
+		if len(args) < 3 || args[1].Text != "=" { return errors.New("expected 'let <name> = <expr>'") }
+		if err := self.checkNewVarName(args[0].Text); err != nil { return err }
+		exprParts := make([]string, 0, len(args) - 2)
+		for _, arg := range args[2 : ] {
+			if arg.Quoted { return errors.New("expected expression, found quoted text") }
+			exprParts = append(exprParts, arg.Text)
+		}
+		value, err := evalLvlExpr(strings.Join(exprParts, " "), self.lookup)
+		if err != nil { return err }
+		self.vars[args[0].Text] = value
+	case "entry":
+		if len(args) != 3 { return errArgCount("entry", 3) }
+		key, err := self.entryKeyArg(args[0])
+		if err != nil { return err }
+		x, y, err := self.evalPair(args[1], args[2])
+		if err != nil { return err }
+		SetEntryPoint(key, self.level, x, y)
+	case "tip":
+		if len(args) != 11 && len(args) != 12 { return errors.New("'tip' expects 11 or 12 arguments") }
+		area, err := self.rectArgs(args[0 : 4])
+		if err != nil { return err }
+		clearedArea, err := self.rectArgs(args[4 : 8])
+		if err != nil { return err }
+		switchKey, err := self.switchArg(args[8])
+		if err != nil { return err }
+		rgba, found := clr.ByName(args[9].Text)
+		if !found { return errors.New("unknown color '" + args[9].Text + "'") }
+		lines, err := self.textArgs(args[10 : ])
+		if err != nil { return err }
+		var msg *text.Message
+		if len(lines) == 1 {
+			msg = text.NewSkippableMsg1(lines[0], rgba)
+		} else {
+			msg = text.NewSkippableMsg2(lines[0], lines[1], rgba)
+		}
+		self.level.AddTrigger(trigger.NewShowTip(area, clearedArea, msg, switchKey))
+	case "tip_text":
+		if len(args) != 11 { return errArgCount("tip_text", 11) }
+		area, err := self.rectArgs(args[0 : 4])
+		if err != nil { return err }
+		clearedArea, err := self.rectArgs(args[4 : 8])
+		if err != nil { return err }
+		switchKey, err := self.switchArg(args[8])
+		if err != nil { return err }
+		rgba, found := clr.ByName(args[9].Text)
+		if !found { return errors.New("unknown color '" + args[9].Text + "'") }
+		lines, err := self.textArgs(args[10 : ])
+		if err != nil { return err }
+		msg := text.NewSkippableWrappedMsg(lines[0], rgba)
+		self.level.AddTrigger(trigger.NewShowTip(area, clearedArea, msg, switchKey))
+	case "interact_text":
+		if len(args) < 8 { return errors.New("'interact_text' expects at least 8 arguments") }
+		area, err := self.rectArgs(args[0 : 4])
+		if err != nil { return err }
+		hintType, found := lvlFileHintTypes[args[4].Text]
+		if !found { return errors.New("unknown hint type '" + args[4].Text + "'") }
+		hx, hy, err := self.evalPair(args[5], args[6])
+		if err != nil { return err }
+		lines, err := self.textArgs(args[7 : ])
+		if err != nil { return err }
+		self.level.AddTrigger(trigger.NewInteractText(area, hint.NewHint(hintType, hx, hy), lines))
+	case "dialogue":
+		if len(args) != 8 { return errArgCount("dialogue", 8) }
+		area, err := self.rectArgs(args[0 : 4])
+		if err != nil { return err }
+		hintType, found := lvlFileHintTypes[args[4].Text]
+		if !found { return errors.New("unknown hint type '" + args[4].Text + "'") }
+		hx, hy, err := self.evalPair(args[5], args[6])
+		if err != nil { return err }
+		script, found := dialogue.Lookup(args[7].Text)
+		if !found { return errors.New("unknown dialogue script '" + args[7].Text + "'") }
+		self.level.AddTrigger(trigger.NewDialogue(area, hint.NewHint(hintType, hx, hy), script))
+	case "transfer":
+		if len(args) != 4 { return errArgCount("transfer", 4) }
+		dir, found := lvlFileTransferDirs[args[0].Text]
+		if !found { return errors.New("unknown transfer direction '" + args[0].Text + "'") }
+		x, y, err := self.evalPair(args[1], args[2])
+		if err != nil { return err }
+		key, err := self.entryKeyArg(args[3])
+		if err != nil { return err }
+		self.level.AddTrigger(trigger.NewLevelTransfer(x, y, dir, key))
+	case "switch_save":
+		if len(args) != 2 { return errArgCount("switch_save", 2) }
+		saveBlock, err := self.blockRef(args[0].Text)
+		if err != nil { return err }
+		key, err := self.entryKeyArg(args[1])
+		if err != nil { return err }
+		self.level.AddTrigger(NewSwitchSaveTrigger(&saveBlock, key))
+	case "sword_challenge":
+		if len(args) != 9 { return errArgCount("sword_challenge", 9) }
+		area, err := self.rectArgs(args[0 : 4])
+		if err != nil { return err }
+		hx, hy, err := self.evalPair(args[4], args[5])
+		if err != nil { return err }
+		x, y, err := self.evalPair(args[6], args[7])
+		if err != nil { return err }
+		switchKey, err := self.switchArg(args[8])
+		if err != nil { return err }
+		challengeHint := hint.NewHint(hint.TypeInteract, hx, hy)
+		self.level.AddTrigger(trigger.NewSwordChallenge(area, challengeHint, sword.NewChallenge(x, y), switchKey))
+	case "limits":
+		if len(args) & 0b01 != 0 { return errors.New("'limits' expects <op> <value> pairs") }
+		area := self.level.ComputeArea()
+		for i := 0; i < len(args); i += 2 {
+			value, err := self.evalU16(args[i + 1])
+			if err != nil { return err }
+			switch args[i].Text {
+			case "pad": area = area.PadEachFace(value)
+			case "pad_horz": area = area.PadHorz(value)
+			case "min_x": area.Min.X = value
+			case "min_y": area.Min.Y = value
+			case "max_x": area.Max.X = value
+			case "max_y": area.Max.Y = value
+			default:
+				return errors.New("unknown limits op '" + args[i].Text + "'")
+			}
+		}
+		self.level.SetLimits(area)
+		self.hasLimits = true
+	default:
+		return errors.New("unknown statement '" + tokens[0].Text + "'")
+	}
+	return nil
+}
+
+func (self *lvlLoader) execBlock(name string, tokens []lvlToken) (block.Block, error) {
+	if name != "_" {
+		if err := self.checkNewName(name); err != nil { return block.Block{}, err }
+	}
+	typeID, found := block.LookupByName(tokens[0].Text)
+	if !found { return block.Block{}, errors.New("unknown block type '" + tokens[0].Text + "'") }
+	blck := block.NewBlock(typeID)
+
+	i := 1
+	for i < len(tokens) {
+		op := tokens[i].Text
+		numArgs, found := lvlBlockOpArgs[op]
+		if !found { return blck, errors.New("unknown block op '" + op + "'") }
+		if i + numArgs >= len(tokens) { return blck, errArgCount(op, numArgs) }
+		args := tokens[i + 1 : i + 1 + numArgs]
+		i += 1 + numArgs
+
+		switch op {
+		case "shift_height_up": blck.ShiftHeightUp()
+		case "shift_height_down": blck.ShiftHeightDown()
+		case "shift_width_left": blck.ShiftWidthLeft()
+		case "at":
+			x, y, err := self.evalPair(args[0], args[1])
+			if err != nil { return blck, err }
+			blck.At(x, y)
+		case "right_of_bottom_aligned", "center_with", "center_above":
+			other, err := self.blockRef(args[0].Text)
+			if err != nil { return blck, err }
+			switch op {
+			case "right_of_bottom_aligned": blck.RightOfBottomAligned(&other)
+			case "center_with": blck.CenterWith(&other)
+			case "center_above": blck.CenterAbove(&other)
+			}
+		case "above", "below", "right_of", "left_of":
+			other, err := self.blockRef(args[0].Text)
+			if err != nil { return blck, err }
+			offset, err := self.eval(args[1])
+			if err != nil { return blck, err }
+			switch op {
+			case "above": blck.Above(&other, offset)
+			case "below": blck.Below(&other, offset)
+			case "right_of": blck.RightOf(&other, offset)
+			case "left_of": blck.LeftOf(&other, offset)
+			}
+		case "at_x", "at_y":
+			value, err := self.evalU16(args[0])
+			if err != nil { return blck, err }
+			if op == "at_x" { blck.AtX(value) } else { blck.AtY(value) }
+		default: // single value ops
+			value, err := self.eval(args[0])
+			if err != nil { return blck, err }
+			switch op {
+			case "move_up": blck.MoveUp(value)
+			case "move_down": blck.MoveDown(value)
+			case "move_left": blck.MoveLeft(value)
+			case "move_right": blck.MoveRight(value)
+			default:
+				panic(op)
+			}
+		}
+	}
+
+	if name != "_" { self.blocks[name] = blck }
+	return blck, nil
+}
+
+var lvlBlockOpArgs = map[string]int{
+	"at": 2, "at_x": 1, "at_y": 1,
+	"above": 2, "below": 2, "right_of": 2, "left_of": 2,
+	"right_of_bottom_aligned": 1, "center_with": 1, "center_above": 1,
+	"shift_height_up": 0, "shift_height_down": 0, "shift_width_left": 0,
+	"move_up": 1, "move_down": 1, "move_left": 1, "move_right": 1,
+}
+
+// --- argument helpers ---
+
+func (self *lvlLoader) checkNewName(name string) error {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') || strings.IndexByte(name, '.') != -1 {
+		return errors.New("invalid name '" + name + "'")
+	}
+	for i := 0; i < len(name); i++ {
+		if !isLvlIdentByte(name[i]) { return errors.New("invalid name '" + name + "'") }
+	}
+	_, isVar := self.vars[name]
+	if isVar { return errors.New("name '" + name + "' already used by a variable") }
+	return nil
+}
+
+// Like checkNewName(), but variables can't reuse block names
+// either (blocks can be redefined, variables can't).
+func (self *lvlLoader) checkNewVarName(name string) error {
+	err := self.checkNewName(name)
+	if err != nil { return err }
+	_, isBlock := self.blocks[name]
+	if isBlock { return errors.New("name '" + name + "' already used by a block") }
+	return nil
+}
+
+func (self *lvlLoader) blockRef(name string) (block.Block, error) {
+	blck, found := self.blocks[name]
+	if !found { return blck, errors.New("undefined block '" + name + "'") }
+	return blck, nil
+}
+
+func (self *lvlLoader) eval(token lvlToken) (int, error) {
+	if token.Quoted { return 0, errors.New("expected expression, found quoted text") }
+	return evalLvlExpr(token.Text, self.lookup)
+}
+
+// Like eval(), but the value must fit in an uint16.
+func (self *lvlLoader) evalU16(token lvlToken) (uint16, error) {
+	value, err := self.eval(token)
+	if err != nil { return 0, err }
+	if value < 0 || value > math.MaxUint16 { return 0, errOutOfRange(value) }
+	return uint16(value), nil
+}
+
+func (self *lvlLoader) evalPair(a, b lvlToken) (uint16, uint16, error) {
+	x, err := self.evalU16(a)
+	if err != nil { return 0, 0, err }
+	y, err := self.evalU16(b)
+	if err != nil { return 0, 0, err }
+	return x, y, nil
+}
+
+func (self *lvlLoader) lookup(ident string) (int, error) {
+	dot := strings.IndexByte(ident, '.')
+	if dot == -1 {
+		value, found := self.vars[ident]
+		if !found { return 0, errors.New("undefined variable '" + ident + "'") }
+		return value, nil
+	}
+
+	blck, err := self.blockRef(ident[ : dot])
+	if err != nil { return 0, err }
+	switch ident[dot + 1 : ] {
+	case "x", "left": return int(blck.X), nil
+	case "y", "top": return int(blck.Y), nil
+	case "right": return int(blck.Right()), nil
+	case "bottom": return int(blck.Bottom()), nil
+	case "width": return int(blck.Width()), nil
+	case "height": return int(blck.Height()), nil
+	case "center_x": return int(blck.CenterX()), nil
+	default:
+		return 0, errors.New("unknown block property '" + ident + "'")
+	}
+}
+
+func (self *lvlLoader) rectArgs(args []lvlToken) (u16.Rect, error) {
+	minX, minY, err := self.evalPair(args[0], args[1])
+	if err != nil { return u16.Rect{}, err }
+	maxX, maxY, err := self.evalPair(args[2], args[3])
+	if err != nil { return u16.Rect{}, err }
+	return u16.NewRect(minX, minY, maxX, maxY), nil
+}
+
+func (self *lvlLoader) rgbaArgs(args []lvlToken) (color.RGBA, error) {
+	if len(args) != 3 { return color.RGBA{}, errors.New("expected <r> <g> <b>") }
+	var channels [3]uint8
+	for i, arg := range args {
+		value, err := self.eval(arg)
+		if err != nil { return color.RGBA{}, err }
+		if value < 0 || value > 255 { return color.RGBA{}, errors.New("color channel out of range") }
+		channels[i] = uint8(value)
+	}
+	return color.RGBA{channels[0], channels[1], channels[2], 255}, nil
+}
+
+func (self *lvlLoader) entryKeyArg(token lvlToken) (lvlkey.EntryKey, error) {
+	key, found := lvlFileEntryKeys[token.Text]
+	if !found { return lvlkey.Undefined, errors.New("unknown entry key '" + token.Text + "'") }
+	return key, nil
+}
+
+func (self *lvlLoader) switchArg(token lvlToken) (state.Switch, error) {
+	key, found := state.SwitchFromName(token.Text)
+	if !found { return state.SwitchNone, errors.New("unknown switch '" + token.Text + "'") }
+	return key, nil
+}
+
+func (self *lvlLoader) textArgs(args []lvlToken) ([]string, error) {
+	lines := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !arg.Quoted { return nil, errors.New("expected quoted text, found '" + arg.Text + "'") }
+		line := expandLvlTextGlyphs(arg.Text)
+		err := text.ValidateMarkup(line)
+		if err != nil { return nil, err }
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+func expandLvlTextGlyphs(str string) string {
+	if strings.IndexByte(str, '{') == -1 { return str }
+	for placeholder, glyph := range lvlFileTextGlyphs {
+		str = strings.ReplaceAll(str, placeholder, string(glyph))
+	}
+	return str
+}
+
+func errOutOfRange(value int) error {
+	return errors.New("value " + strconv.Itoa(value) + " out of range [0, " + strconv.Itoa(math.MaxUint16) + "]")
+}
+
+func errArgCount(statement string, expected int) error {
+	return errors.New("'" + statement + "' expects " + strconv.Itoa(expected) + " arguments")
+}
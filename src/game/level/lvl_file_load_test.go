@@ -0,0 +1,51 @@
+package level
+
+import "os"
+import "strconv"
+import "strings"
+import "testing"
+
+import "github.com/tinne26/transition/src/game/level/block"
+
+// The repository root, where the assets are.
+var testFS = os.DirFS("../../..")
+
+func TestLoadErrors(t *testing.T) {
+	err := block.CreateAll(testFS)
+	if err != nil { t.Fatal(err) }
+
+	const header = "mask sq3 1\n"
+	tests := []struct {
+		name string
+		src string
+		errLine int
+		errText string
+	}{
+		{ "negative at", "base = platform_ground_medium_A at -1 OY", 2, "out of range" },
+		{ "big at", "base = platform_ground_medium_A at OX 65536", 2, "out of range" },
+		{ "negative at_x", "base = platform_ground_medium_A at_x OX-OX-5", 2, "out of range" },
+		{ "big at_y", "base = platform_ground_medium_A at_y 70000", 2, "out of range" },
+		{ "negative entry", "entry start_save_left -3 OY", 2, "out of range" },
+		{ "negative limits", "base = platform_ground_medium_A at OX OY\nlimits min_x -1", 3, "out of range" },
+		{ "big limits", "base = platform_ground_medium_A at OX OY\nlimits pad 100000", 3, "out of range" },
+		{ "let after block", "base = platform_ground_medium_A at OX OY\nlet base = 3", 3, "already used by a block" },
+		{ "block after let", "let base = 3\nbase = platform_ground_medium_A at OX OY", 3, "already used by a variable" },
+	}
+
+	for _, test := range tests {
+		_, err := Load(strings.NewReader(header + test.src), "test.lvl")
+		if err == nil {
+			t.Errorf("%s: expected error", test.name)
+			continue
+		}
+		prefix := "test.lvl:" + strconv.Itoa(test.errLine) + ": "
+		if !strings.HasPrefix(err.Error(), prefix) || !strings.Contains(err.Error(), test.errText) {
+			t.Errorf("%s: expected '%s...%s' error, got '%s'", test.name, prefix, test.errText, err)
+		}
+	}
+
+	// redefining blocks is still fine
+	src := header + "step = step_small_A at OX OY\nstep = step_small_B right_of step -1"
+	_, err = Load(strings.NewReader(src), "test.lvl")
+	if err != nil { t.Fatalf("unexpected error: %s", err) }
+}
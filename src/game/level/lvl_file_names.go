@@ -0,0 +1,64 @@
+package level
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+import "github.com/tinne26/transition/src/game/level/lvlkey"
+import "github.com/tinne26/transition/src/game/trigger"
+import "github.com/tinne26/transition/src/game/bckg"
+import "github.com/tinne26/transition/src/game/hint"
+import "github.com/tinne26/transition/src/text"
+
+// Names used on level files for the different game elements.
+// Block types use their own stable names (see block.LookupByName),
+// switches the names from state.Switch.String() and colors the clr
+// palette names.
+
+var lvlFileEntryKeys = map[string]lvlkey.EntryKey{
+	"start_save_left": EntryStartSaveLeft,
+	"start_save_right": EntryStartSaveRight,
+	"start_trans_right": EntryStartTransRight,
+	"sword_trans_left": EntrySwordTransLeft,
+	"sword_trans_right": EntrySwordTransRight,
+	"sword_save_center": EntrySwordSaveCenter,
+	"basics_trans_left": EntryBasicsTransLeft,
+	"basics_trans_right": EntryBasicsTransRight,
+	"ghosts_trans_left": EntryGhostsTransLeft,
+	"ghosts_trans_right": EntryGhostsTransRight,
+	"ghosts_trans_gate": EntryGhostsTransGate,
+	"ghosts_save": EntryGhostsSave,
+	"spikes_left": EntrySpikesLeft,
+	"spikes_right": EntrySpikesRight,
+	"gate_trans_ghosts": EntryGateTransGhosts,
+}
+
+var lvlFileMasks = map[string]*ebiten.Image{
+	"sq3": bckg.MaskSq3,
+	"sq4": bckg.MaskSq4,
+	"sq5": bckg.MaskSq5,
+	"ebi": bckg.MaskEbi,
+}
+
+var lvlFileHintTypes = map[string]hint.HintType{
+	"dots": hint.TypeDots,
+	"exclam": hint.TypeExclam,
+	"reverse": hint.TypeReverse,
+	"interact": hint.TypeInteract,
+	"disrupt": hint.TypeDisrupt,
+}
+
+var lvlFileTransferDirs = map[string]trigger.TransferDir{
+	"right": trigger.RightTransfer,
+	"left": trigger.LeftTransfer,
+}
+
+// Glyph placeholders that can be used inside quoted text.
+var lvlFileTextGlyphs = map[string]rune{
+	"{KeyO}": text.KeyO,
+	"{KeyI}": text.KeyI,
+	"{KeyJ}": text.KeyJ,
+	"{KeyK}": text.KeyK,
+	"{KeyL}": text.KeyL,
+	"{KeyA}": text.KeyA,
+	"{KeyD}": text.KeyD,
+	"{KeyTAB}": text.KeyTAB,
+}
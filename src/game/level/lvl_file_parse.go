@@ -0,0 +1,173 @@
+package level
+
+import "errors"
+import "strconv"
+import "strings"
+
+type lvlToken struct {
+	Text string
+	Quoted bool
+}
+
+// Splits a level file line into tokens. Tokens are separated by
+// whitespace, except for quoted strings (which may contain escaped
+// \", \\ and \n) and parenthesized expressions, which are kept together.
+// Comments start with '#' and extend to the end of the line.
+func tokenizeLvlLine(line string) ([]lvlToken, error) {
+	var tokens []lvlToken
+	i := 0
+	for i < len(line) {
+		switch line[i] {
+		case ' ', '\t', '\r':
+			i += 1
+		case '#':
+			return tokens, nil
+		case '"':
+			var builder strings.Builder
+			i += 1
+			for {
+				if i >= len(line) { return nil, errors.New("unterminated string") }
+				if line[i] == '"' { break }
+				if line[i] == '\\' {
+					if i + 1 >= len(line) { return nil, errors.New("unterminated string") }
+					i += 1
+					if line[i] == 'n' {
+						builder.WriteByte('\n')
+						i += 1
+						continue
+					}
+					if line[i] != '"' && line[i] != '\\' {
+						return nil, errors.New("invalid escape sequence '\\" + string(line[i]) + "'")
+					}
+				}
+				builder.WriteByte(line[i])
+				i += 1
+			}
+			tokens = append(tokens, lvlToken{ Text: builder.String(), Quoted: true })
+			i += 1
+		default:
+			start := i
+			depth := 0
+			for i < len(line) {
+				b := line[i]
+				if b == '(' { depth += 1 }
+				if b == ')' { depth -= 1 }
+				if depth == 0 && (b == ' ' || b == '\t' || b == '\r' || b == '#' || b == '"') { break }
+				if depth < 0 { return nil, errors.New("unbalanced parentheses") }
+				i += 1
+			}
+			if depth != 0 { return nil, errors.New("unbalanced parentheses") }
+			tokens = append(tokens, lvlToken{ Text: line[start : i] })
+		}
+	}
+	return tokens, nil
+}
+
+// Integer expression evaluator for level files. Supports +, -, *, /,
+// unary minus, parentheses, integer literals, variables and block
+// properties (e.g. "base.right", "step.height").
+type lvlExprParser struct {
+	src string
+	pos int
+	lookup func(ident string) (int, error)
+}
+
+func evalLvlExpr(expr string, lookup func(string) (int, error)) (int, error) {
+	parser := lvlExprParser{ src: expr, lookup: lookup }
+	value, err := parser.parseSum()
+	if err != nil { return 0, err }
+	parser.skipSpaces()
+	if parser.pos != len(parser.src) {
+		return 0, errors.New("unexpected '" + parser.src[parser.pos : ] + "' in expression '" + expr + "'")
+	}
+	return value, nil
+}
+
+func (self *lvlExprParser) parseSum() (int, error) {
+	value, err := self.parseProduct()
+	if err != nil { return 0, err }
+	for {
+		self.skipSpaces()
+		if self.pos >= len(self.src) { return value, nil }
+		op := self.src[self.pos]
+		if op != '+' && op != '-' { return value, nil }
+		self.pos += 1
+		rhs, err := self.parseProduct()
+		if err != nil { return 0, err }
+		if op == '+' { value += rhs } else { value -= rhs }
+	}
+}
+
+func (self *lvlExprParser) parseProduct() (int, error) {
+	value, err := self.parseUnary()
+	if err != nil { return 0, err }
+	for {
+		self.skipSpaces()
+		if self.pos >= len(self.src) { return value, nil }
+		op := self.src[self.pos]
+		if op != '*' && op != '/' { return value, nil }
+		self.pos += 1
+		rhs, err := self.parseUnary()
+		if err != nil { return 0, err }
+		if op == '*' {
+			value *= rhs
+		} else {
+			if rhs == 0 { return 0, errors.New("division by zero in expression '" + self.src + "'") }
+			value /= rhs
+		}
+	}
+}
+
+func (self *lvlExprParser) parseUnary() (int, error) {
+	self.skipSpaces()
+	if self.pos < len(self.src) && self.src[self.pos] == '-' {
+		self.pos += 1
+		value, err := self.parseUnary()
+		return -value, err
+	}
+	return self.parsePrimary()
+}
+
+func (self *lvlExprParser) parsePrimary() (int, error) {
+	self.skipSpaces()
+	if self.pos >= len(self.src) {
+		return 0, errors.New("unexpected end of expression '" + self.src + "'")
+	}
+
+	// parenthesized expression
+	if self.src[self.pos] == '(' {
+		self.pos += 1
+		value, err := self.parseSum()
+		if err != nil { return 0, err }
+		self.skipSpaces()
+		if self.pos >= len(self.src) || self.src[self.pos] != ')' {
+			return 0, errors.New("missing ')' in expression '" + self.src + "'")
+		}
+		self.pos += 1
+		return value, nil
+	}
+
+	// number or identifier
+	start := self.pos
+	for self.pos < len(self.src) && isLvlIdentByte(self.src[self.pos]) {
+		self.pos += 1
+	}
+	if start == self.pos {
+		return 0, errors.New("unexpected '" + self.src[self.pos : ] + "' in expression '" + self.src + "'")
+	}
+	word := self.src[start : self.pos]
+	if word[0] >= '0' && word[0] <= '9' {
+		return strconv.Atoi(word)
+	}
+	return self.lookup(word)
+}
+
+func (self *lvlExprParser) skipSpaces() {
+	for self.pos < len(self.src) && (self.src[self.pos] == ' ' || self.src[self.pos] == '\t') {
+		self.pos += 1
+	}
+}
+
+func isLvlIdentByte(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9') || b == '_' || b == '.'
+}
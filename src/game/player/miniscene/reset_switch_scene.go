@@ -57,10 +57,24 @@ func NewResetSwitchScene(x, y uint16, key lvlkey.EntryKey) *ResetSwitchScene {
 	}
 }
 
+// The audio gets muffled and reverberated as the reversal progresses.
+func (self *ResetSwitchScene) updateAudioEffects(ctx *context.Context) {
+	switch self.stage {
+	case resetSwitchStageHolding, resetSwitchStageOnDesistHold, resetSwitchStageOnFloorHold:
+		progress := 1.0 - float32(self.holdTicksLeft)/refHoldTicks
+		ctx.Audio.SetParam(audio.ParamKeyMuffle, progress*0.7)
+		ctx.Audio.SetParam(audio.ParamKeyReverb, progress)
+	default:
+		ctx.Audio.SetParam(audio.ParamKeyMuffle, 0)
+		ctx.Audio.SetParam(audio.ParamKeyReverb, 0)
+	}
+}
+
 // Unused.
 func (self *ResetSwitchScene) CurrentText() *text.Message { return nil }
 
 func (self *ResetSwitchScene) Update(ctx *context.Context, cam *camera.Camera, playerInfo comm.Status) (any, error) {
+	self.updateAudioEffects(ctx)
 	switch self.stage {
 	case resetSwitchStageInitHand:
 		self.stage = resetSwitchStageInitConsumption
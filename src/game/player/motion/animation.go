@@ -61,19 +61,21 @@ func (self *Animation) InPreLoopPhase() bool {
 	return self.frameIndex < self.loopIndex
 }
 
-func (self *Animation) SkipIntro(soundscape *audio.Soundscape) {
+// The x and y coordinates in SkipIntro(), Rewind() and Update()
+// are the world position for the frame sound effects, if any.
+func (self *Animation) SkipIntro(soundscape *audio.Soundscape, x, y uint16) {
 	self.frameIndex = self.loopIndex
 	self.frameDurationLeft = self.frameDurations[self.loopIndex]
-	self.playSfx(soundscape)
+	self.playSfx(soundscape, x, y)
 }
 
-func (self *Animation) Rewind(soundscape *audio.Soundscape) {
+func (self *Animation) Rewind(soundscape *audio.Soundscape, x, y uint16) {
 	self.frameIndex = 0
 	self.frameDurationLeft = self.frameDurations[0]
-	self.playSfx(soundscape)
+	self.playSfx(soundscape, x, y)
 }
 
-func (self *Animation) Update(soundscape *audio.Soundscape) {
+func (self *Animation) Update(soundscape *audio.Soundscape, x, y uint16) {
 	self.frameDurationLeft -= 1
 	if self.frameDurationLeft == 0 {
 		if self.frameIndex == uint8(len(self.frames) - 1) {
@@ -81,7 +83,7 @@ func (self *Animation) Update(soundscape *audio.Soundscape) {
 		} else {
 			self.frameIndex += 1
 		}
-		self.playSfx(soundscape)
+		self.playSfx(soundscape, x, y)
 		self.frameDurationLeft = self.frameDurations[self.frameIndex]
 	}
 }
@@ -90,17 +92,17 @@ func (self *Animation) SetLoopStart(index uint8) {
 	self.loopIndex = index
 }
 
-func (self *Animation) playSfx(soundscape *audio.Soundscape) {
+func (self *Animation) playSfx(soundscape *audio.Soundscape, x, y uint16) {
 	sfxKey := self.sfxs[self.frameIndex]
 	switch sfxKey {
 	case SfxKeyNone:
 		// nothing
 	case SfxKeyStep:
-		soundscape.PlaySFX(audio.SfxStep)
+		soundscape.PlaySFXAt(audio.SfxStep, x, y)
 	case SfxKeyJump:
-		soundscape.PlaySFX(audio.SfxJump)
+		soundscape.PlaySFXAt(audio.SfxJump, x, y)
 	case SfxKeyDeath:
-		soundscape.PlaySFX(audio.SfxDeath)
+		soundscape.PlaySFXAt(audio.SfxDeath, x, y)
 	default:
 		panic(sfxKey)
 	}
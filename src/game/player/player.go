@@ -22,6 +22,13 @@ import "github.com/tinne26/transition/src/game/player/comm"
 
 const minConsumedPower = 0.0 // 0.9 // for debug
 
+// Player hitbox, relative to the top-left corner of the animation
+// frame. The size must match the one used in block contact tests.
+const hitboxOffsetX = 3
+const hitboxOffsetY = 5
+const hitboxWidth   = 11
+const hitboxHeight  = 43
+
 type Player struct {
 	x, y float64
 	anim *motion.Animation
@@ -30,7 +37,7 @@ type Player struct {
 
 	motionState motion.State
 	motionStateTicks uint32 // simple counter for current motion state
-	sinceJumpTrigger uint32
+	leniency Leniency
 
 	orientation motion.HorzDir // can't be HorzDirNone
 	blockFlags block.Flags
@@ -71,7 +78,7 @@ func New() *Player {
 		hearts: 5,
 		orientation: motion.HorzDirRight,
 		detailAnim: motion.AnimDetailIdle,
-		sinceJumpTrigger: 99999,
+		leniency: DefaultLeniency,
 		powerConsumed: minConsumedPower,
 	}
 	return player
@@ -105,6 +112,23 @@ func (self *Player) DebugStr() string {
 	return "Player{" + strconv.FormatFloat(self.x, 'f', 2, 64) + "X, " + strconv.FormatFloat(self.y, 'f', 2, 64) + "Y" + "}"
 }
 
+// Leniency windows to make platforming less strict, in ticks.
+// Zero windows disable the corresponding leniency.
+type Leniency struct {
+	JumpBuffer uint32 // jumps pressed shortly before they are possible still happen
+	Coyote uint32 // jumps are still possible shortly after starting to fall
+}
+
+var DefaultLeniency = Leniency{ JumpBuffer: 8, Coyote: 6 }
+
+func (self *Player) SetLeniency(leniency Leniency) {
+	self.leniency = leniency
+}
+
+func (self *Player) Leniency() Leniency {
+	return self.leniency
+}
+
 const DefaultJumpTicks = 26
 const WingJumpTicks = DefaultJumpTicks + 4
 
@@ -124,11 +148,11 @@ func (self *Player) Update(cam *camera.Camera, currentLevel *level.Level, ctx *c
 
 	// increase misc. counters
 	self.motionStateTicks += 1
-	self.sinceJumpTrigger += 1
 	self.sinceNoContactFall += 1
 	self.updateWallStickHacks()
-	self.anim.Update(ctx.Audio)
-	self.detailAnim.Update(ctx.Audio)
+	sfxX, sfxY := self.sfxPosition()
+	self.anim.Update(ctx.Audio, sfxX, sfxY)
+	self.detailAnim.Update(ctx.Audio, sfxX, sfxY)
 
 	// hacks to smooth steps on stairs
 	// (basically, a form of delayed position hacking, so we move
@@ -148,11 +172,7 @@ func (self *Player) Update(cam *camera.Camera, currentLevel *level.Level, ctx *c
 			self.stepHackVert += 1
 			self.y += 2
 		}
-
-		if ctx.Input.Trigger(input.ActionJump) {
-			self.sinceJumpTrigger = 0
-		}
-		return nil
+		return nil // (jumps pressed here are kept by input buffering)
 	}
 
 	// update power consumption
@@ -232,15 +252,11 @@ func (self *Player) Update(cam *camera.Camera, currentLevel *level.Level, ctx *c
 	}
 
 	// handle jumping
-	jumping := ctx.Input.Trigger(input.ActionJump)
-	if !jumping && self.sinceJumpTrigger < 8 {
-		jumping = true
-	} else if jumping {
-		self.sinceJumpTrigger = 0
-	}
-	if jumping && self.motionStateAllowsJump() {
-		ctx.Audio.PlaySFX(audio.SfxJump)
-		self.sinceJumpTrigger = 99999
+	jumpWindow := self.leniency.JumpBuffer
+	if jumpWindow == 0 { jumpWindow = 1 } // no buffering, only presses on this tick
+	if self.motionStateAllowsJump() && ctx.Input.ConsumeBuffered(input.ActionJump, jumpWindow) {
+		sfxX, sfxY := self.sfxPosition()
+		ctx.Audio.PlaySFXAt(audio.SfxJump, sfxX, sfxY)
 
 		// common setup
 		self.spentWallStick = false
@@ -318,10 +334,20 @@ func (self *Player) Update(cam *camera.Camera, currentLevel *level.Level, ctx *c
 	xLimitReached := (int(self.x) == int(newX))
 	yLimitReached := (int(self.y) == int(newY))
 
-	// determine relevant horizontal range to iterate
-	rangeMin, rangeMax := utils.MinMax(uint16(self.x), uint16(newX))
-	rangeMin += 2 // -1 + 3
-	rangeMax += motion.PlayerFrameWidth - 2
+	// determine the swept motion rect with the blocks that may
+	// interact with the player. contact tests are inclusive, so
+	// we need one extra pixel, and steps and slips can shift the
+	// reached position slightly, so we pad vertically too
+	const StepPad = 4
+	const ContactPad = 1
+	rangeMinX, rangeMaxX := utils.MinMax(uint16(self.x), uint16(newX))
+	rangeMinY, rangeMaxY := utils.MinMax(uint16(self.y), uint16(newY))
+	sweptRect := u16.NewRect(
+		rangeMinX + hitboxOffsetX - ContactPad,
+		rangeMinY + hitboxOffsetY - ContactPad - StepPad,
+		rangeMaxX + motion.PlayerFrameWidth - ContactPad,
+		rangeMaxY + hitboxOffsetY + hitboxHeight + ContactPad + StepPad,
+	)
 
 	// check each block that may interact with
 	// our current position or path to new position
@@ -330,8 +356,8 @@ func (self *Player) Update(cam *camera.Camera, currentLevel *level.Level, ctx *c
 		// get new integer coords to check, set up iterator
 		// and start checking each block in range
 		floorContact := block.ContactNone // track for slipping and falling
-		currentLevel.EachBlockInRange(rangeMin, rangeMax, func(levelBlock block.Block) level.IterationControl {
-			contact := levelBlock.ContactTest(reachedX + 3, reachedY + 5, self.blockFlags)
+		currentLevel.QueryRect(sweptRect, func(levelBlock block.Block) level.IterationControl {
+			contact := levelBlock.ContactTest(reachedX + hitboxOffsetX, reachedY + hitboxOffsetY, self.blockFlags)
 
 			// big-ass switch case
 		redirect:
@@ -348,17 +374,19 @@ func (self *Player) Update(cam *camera.Camera, currentLevel *level.Level, ctx *c
 					// e.g. jump start y, or airMaxY vs current Y.
 					if self.x != newX {
 						self.setMotionState(motion.Moving, motion.AnimRun, ctx)
-						self.anim.SkipIntro(ctx.Audio)
+						sfxX, sfxY := self.sfxPosition()
+						self.anim.SkipIntro(ctx.Audio, sfxX, sfxY)
 					} else {
 						self.setMotionState(motion.Idle, motion.AnimIdle, ctx)
-						ctx.Audio.PlaySFX(audio.SfxStep)
+						sfxX, sfxY := self.sfxPosition()
+						ctx.Audio.PlaySFXAt(audio.SfxStep, sfxX, sfxY)
 					}
 					yLimitReached = true
 					self.blockFlags &= ^block.FlagInertiaDown
 				}
 			case block.ContactSlipIntoFall:
 				if !self.inFloatyMotionState() {
-					if reachedX + 3 + 5 >= levelBlock.X + levelBlock.Width()/2 {
+					if reachedX + hitboxOffsetX + hitboxWidth/2 >= levelBlock.X + levelBlock.Width()/2 {
 						reachedX += 1 // fall to right
 						newX += 1
 					} else {
@@ -449,7 +477,7 @@ func (self *Player) Update(cam *camera.Camera, currentLevel *level.Level, ctx *c
 				
 				// shift x
 				toRight := up
-				stepOnPlayerLeft := (reachedX + 3 + 5 >= levelBlock.X + levelBlock.Width()/2)
+				stepOnPlayerLeft := (reachedX + hitboxOffsetX + hitboxWidth/2 >= levelBlock.X + levelBlock.Width()/2)
 				if stepOnPlayerLeft { toRight = !toRight }
 
 				shift := uint16(1) // MARK EXPERIMENT // uint16(3)
@@ -480,7 +508,8 @@ func (self *Player) Update(cam *camera.Camera, currentLevel *level.Level, ctx *c
 				// adjust state and animation if necessary
 				if self.motionState != motion.Moving {
 					self.setMotionState(motion.Moving, motion.AnimRun, ctx)
-					self.anim.SkipIntro(ctx.Audio)
+					sfxX, sfxY := self.sfxPosition()
+					self.anim.SkipIntro(ctx.Audio, sfxX, sfxY)
 				}
 				xLimitReached, yLimitReached = true, true
 			case block.ContactDeath:
@@ -651,9 +680,9 @@ func (self *Player) DrawPowerBarFill(projector *project.Projector) {
 // ---- secondary public functions ----
 
 func (self *Player) GetMotionShot() motion.Shot {
-	minX, minY := uint16(self.x) + 3, uint16(self.y) + 5
+	minX, minY := uint16(self.x) + hitboxOffsetX, uint16(self.y) + hitboxOffsetY
 	return motion.Shot{
-		Rect: u16.NewRect(minX, minY, minX + 11, minY + 43),
+		Rect: u16.NewRect(minX, minY, minX + hitboxWidth, minY + hitboxHeight),
 		Orientation: self.orientation,
 		Animation: self.anim,
 		State: self.motionState,
@@ -685,6 +714,12 @@ func (self *Player) GetCameraTargetPos() (float64, float64) {
 	return self.x + float64(int(motion.PlayerFrameWidth)/2), self.y - 20
 }
 
+// World position for the player's sound effects.
+func (self *Player) sfxPosition() (uint16, uint16) {
+	x, y := self.GetCameraTargetPos()
+	return uint16(x), uint16(y)
+}
+
 // --- block flags ---
 
 func (self *Player) refreshBlockFlags(newX, newY float64, ctx *context.Context) {
@@ -727,16 +762,17 @@ func (self *Player) setMotionState(state motion.State, anim *motion.Animation, c
 	//fmt.Printf("setting motion state %s, anim %s\n", state.String(), anim.Name())
 	self.motionState = state // always possible due to level design
 	self.motionStateTicks = 0
+	sfxX, sfxY := self.sfxPosition()
 	if anim != self.anim {
 		self.anim = anim
-		self.anim.Rewind(ctx.Audio)
+		self.anim.Rewind(ctx.Audio, sfxX, sfxY)
 	}
 	
 	// hardcoded detail anim handling, of course
 	switch state {
 	case motion.WingJump:
 		self.detailAnim = motion.AnimDetailJump
-		self.detailAnim.Rewind(ctx.Audio)
+		self.detailAnim.Rewind(ctx.Audio, sfxX, sfxY)
 	// case motion.Dash:
 	// 	self.detailAnim = motion.AnimDetailDash
 	//    self.detailAnim.Rewind()
@@ -772,7 +808,7 @@ func (self *Player) motionStateAllowsJump() bool {
 }
 
 func (self *Player) allowLenientJumpOnFall() bool {
-	return self.sinceNoContactFall < 6 // leniency on jumps
+	return self.sinceNoContactFall < self.leniency.Coyote
 }
 
 func (self *Player) motionStateCanStopGroundHorzMove() bool {
@@ -0,0 +1,47 @@
+package playersim
+
+import "io"
+import "bufio"
+import "errors"
+import "strconv"
+import "strings"
+
+import "github.com/tinne26/transition/src/input"
+
+// Parses a simulation script. Each line has the number of ticks
+// followed by the names of the actions held during those ticks
+// (see input.Action.String()), or no actions to wait. Empty lines
+// are ignored and '#' starts a comment. For example:
+//   30 move_right
+//   1 move_right jump
+//   40
+func ParseScript(reader io.Reader) (*input.ScriptedSource, error) {
+	script := input.NewScriptedSource()
+	scanner := bufio.NewScanner(reader)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum += 1
+		line := scanner.Text()
+		if comment := strings.IndexByte(line, '#'); comment != -1 {
+			line = line[ : comment]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 { continue }
+
+		numTicks, err := strconv.Atoi(fields[0])
+		if err != nil || numTicks <= 0 {
+			return nil, errors.New("line " + strconv.Itoa(lineNum) + ": invalid tick count '" + fields[0] + "'")
+		}
+		var actions input.ActionSet
+		for _, name := range fields[1 : ] {
+			action, found := input.ActionFromName(name)
+			if !found {
+				return nil, errors.New("line " + strconv.Itoa(lineNum) + ": unknown action '" + name + "'")
+			}
+			actions = actions.With(action)
+		}
+		script.Hold(actions, numTicks)
+	}
+	if err := scanner.Err(); err != nil { return nil, err }
+	return script, nil
+}
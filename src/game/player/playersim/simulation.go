@@ -0,0 +1,100 @@
+package playersim
+
+import "io"
+import "bufio"
+import "strconv"
+
+import "github.com/tinne26/transition/src/input"
+import "github.com/tinne26/transition/src/camera"
+import "github.com/tinne26/transition/src/game/context"
+import "github.com/tinne26/transition/src/game/level"
+import "github.com/tinne26/transition/src/game/level/lvlkey"
+import "github.com/tinne26/transition/src/game/player"
+import "github.com/tinne26/transition/src/game/player/motion"
+
+// Headless simulation of the player physics, driven by a scripted
+// input source. No window, audio or GPU rendering are required, but
+// the block types, levels and player animations must have been created
+// first (block.CreateAll() / level.CreateAll() and motion.LoadAnimations()).
+//
+// Only the player is simulated: level triggers, the camera and
+// miniscenes are ignored.
+type Simulation struct {
+	player *player.Player
+	level *level.Level
+	camera *camera.Camera
+	ctx *context.Context
+	tick int
+}
+
+// Creates a new simulation with the player idle at the given position
+// (same semantics as player.Player.SetIdleAt()).
+func New(lvl *level.Level, centerX, floorY uint16, script *input.ScriptedSource) *Simulation {
+	sim := &Simulation{
+		player: player.New(),
+		level: lvl,
+		camera: camera.New(),
+		ctx: context.NewHeadlessContext(script),
+	}
+	sim.player.SetIdleAt(centerX, floorY, sim.ctx)
+	return sim
+}
+
+// Creates a new simulation with the player idle at the given entry point.
+func NewAtEntry(entryKey lvlkey.EntryKey, script *input.ScriptedSource) *Simulation {
+	lvl, pt := level.GetEntryPoint(entryKey)
+	return New(lvl, pt.X, pt.Y, script)
+}
+
+func (self *Simulation) Player() *player.Player { return self.player }
+func (self *Simulation) Context() *context.Context { return self.ctx }
+func (self *Simulation) Tick() int { return self.tick }
+
+// Advances the simulation by a single tick and returns the resulting shot.
+func (self *Simulation) Step() (motion.Shot, error) {
+	err := self.ctx.Update()
+	if err != nil { return motion.Shot{}, err }
+	err = self.player.Update(self.camera, self.level, self.ctx)
+	if err != nil { return motion.Shot{}, err }
+	self.tick += 1
+	return self.player.GetMotionShot(), nil
+}
+
+// Runs the simulation for the given number of ticks, or until the player
+// dies, and returns the shot trace.
+func (self *Simulation) Run(numTicks int) ([]motion.Shot, error) {
+	trace := make([]motion.Shot, 0, numTicks)
+	for i := 0; i < numTicks; i++ {
+		shot, err := self.Step()
+		if err != nil { return trace, err }
+		trace = append(trace, shot)
+		if self.player.HasDied() { break }
+	}
+	return trace, nil
+}
+
+// Writes the trace in a line based text format, one shot per tick:
+//   <tick> <min_x> <min_y> <max_x> <max_y> <orientation> <state> <animation>
+// Meant for golden files, so the format should be kept stable.
+func WriteTrace(writer io.Writer, trace []motion.Shot) error {
+	out := bufio.NewWriter(writer)
+	for i, shot := range trace {
+		out.WriteString(strconv.Itoa(i + 1))
+		for _, value := range [4]uint16{ shot.Rect.Min.X, shot.Rect.Min.Y, shot.Rect.Max.X, shot.Rect.Max.Y } {
+			out.WriteByte(' ')
+			out.WriteString(strconv.Itoa(int(value)))
+		}
+		out.WriteByte(' ')
+		out.WriteString(shot.Orientation.String())
+		out.WriteByte(' ')
+		out.WriteString(shot.State.String())
+		out.WriteByte(' ')
+		if shot.Animation == nil {
+			out.WriteString("-")
+		} else {
+			out.WriteString(shot.Animation.Name())
+		}
+		out.WriteByte('\n')
+	}
+	return out.Flush()
+}
@@ -0,0 +1,81 @@
+package playersim
+
+import "os"
+import "flag"
+import "bytes"
+import "testing"
+import "path/filepath"
+
+import "github.com/tinne26/transition/src/game/level"
+import "github.com/tinne26/transition/src/game/player/motion"
+
+var update = flag.Bool("update", false, "rewrite the golden trace files")
+
+// Scripts in testdata/<name>.txt are simulated from the given entry
+// point, and the traces compared with testdata/<name>.golden. After
+// intentional changes to the player physics, regenerate them with:
+// > go test ./src/game/player/playersim -update
+var goldenSims = []struct {
+	name string
+	entry string
+}{
+	{ "jumps", "start_save_left" },
+	{ "stair_steps", "start_save_left" },
+	{ "wall_stick", "start_save_left" },
+	{ "slip_into_fall", "start_save_left" },
+}
+
+func TestGoldenTraces(t *testing.T) {
+	filesys := os.DirFS("../../../..")
+	err := level.CreateAll(filesys)
+	if err != nil { t.Fatal(err) }
+	err = motion.LoadAnimations(filesys)
+	if err != nil { t.Fatal(err) }
+
+	for _, sim := range goldenSims {
+		t.Run(sim.name, func(t *testing.T) {
+			got := runScriptFile(t, filepath.Join("testdata", sim.name + ".txt"), sim.entry)
+			goldenPath := filepath.Join("testdata", sim.name + ".golden")
+			if *update {
+				err := os.WriteFile(goldenPath, got, 0644)
+				if err != nil { t.Fatal(err) }
+				return
+			}
+			expected, err := os.ReadFile(goldenPath)
+			if err != nil { t.Fatal(err) }
+			if !bytes.Equal(got, expected) {
+				t.Fatalf("trace differs from %s:\n%s", goldenPath, firstLineDiff(got, expected))
+			}
+		})
+	}
+}
+
+func runScriptFile(t *testing.T, path string, entryName string) []byte {
+	file, err := os.Open(path)
+	if err != nil { t.Fatal(err) }
+	script, err := ParseScript(file)
+	file.Close()
+	if err != nil { t.Fatal(err) }
+	entryKey, found := level.LookupEntryKey(entryName)
+	if !found { t.Fatalf("unknown entry '%s'", entryName) }
+
+	trace, err := NewAtEntry(entryKey, script).Run(script.Len())
+	if err != nil { t.Fatal(err) }
+	var buffer bytes.Buffer
+	err = WriteTrace(&buffer, trace)
+	if err != nil { t.Fatal(err) }
+	return buffer.Bytes()
+}
+
+// Returns the first differing line of two traces.
+func firstLineDiff(got, expected []byte) string {
+	gotLines, expectedLines := bytes.Split(got, []byte("\n")), bytes.Split(expected, []byte("\n"))
+	for i := 0; i < len(gotLines) || i < len(expectedLines); i++ {
+		var gotLine, expectedLine []byte
+		if i < len(gotLines) { gotLine = gotLines[i] }
+		if i < len(expectedLines) { expectedLine = expectedLines[i] }
+		if bytes.Equal(gotLine, expectedLine) { continue }
+		return " got:  " + string(gotLine) + "\n want: " + string(expectedLine)
+	}
+	return ""
+}
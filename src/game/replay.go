@@ -0,0 +1,69 @@
+package game
+
+import "os"
+import "bytes"
+
+import "github.com/tinne26/transition/src/debug"
+import "github.com/tinne26/transition/src/input"
+import "github.com/tinne26/transition/src/utils"
+import "github.com/tinne26/transition/src/game/level"
+import "github.com/tinne26/transition/src/game/level/lvlkey"
+
+// Loads the replay file passed with --replay, if any.
+func loadReplayArg() (*input.Replay, error) {
+	path, found := utils.OsArgValue("--replay")
+	if !found { return nil, nil }
+	file, err := os.Open(path)
+	if err != nil { return nil, err }
+	defer file.Close()
+
+	var replay input.Replay
+	_, err = replay.ReadFrom(file)
+	if err != nil { return nil, err }
+	if !level.IsValidEntryKey(replay.EntryKey) { return nil, input.ErrInvalidReplay }
+	return &replay, nil
+}
+
+// Starts feeding the replay's input. The replay state must have
+// been loaded already. Must be called before the first update.
+func (self *Game) startReplay(replay *input.Replay) {
+	self.replaying = replay.NewSource()
+	self.ctx.Input.SetSource(self.replaying)
+}
+
+// Starts recording the input if --record was passed. Must be called
+// right after creating the game, before the first update.
+func (self *Game) startRecordingIfRequested(seed int64, entryKey lvlkey.EntryKey) error {
+	path, found := utils.OsArgValue("--record")
+	if !found { return nil }
+
+	var state bytes.Buffer
+	_, err := self.ctx.State.WriteTo(&state)
+	if err != nil { return err }
+	self.recording = &input.Replay{ EntryKey: entryKey, Seed: seed, State: state.Bytes() }
+	self.recordPath = path
+	self.recorder = input.NewRecordingSource(self.ctx.Input.Source())
+	self.ctx.Input.SetSource(self.recorder)
+	return nil
+}
+
+// Gives control back to the player once a replay is over.
+func (self *Game) updateReplay() {
+	if self.replaying == nil || !self.replaying.Done() { return }
+	debug.Printf("Replay finished at tick %d\n", self.tick)
+	self.replaying = nil
+	self.ctx.Input.SetSource(self.devices)
+}
+
+// Writes the input recording to disk, if any. Must be called
+// after the game stops running.
+func (self *Game) Close() error {
+	if self.recorder == nil { return nil }
+	self.recording.Ticks = self.recorder.Ticks()
+	file, err := os.Create(self.recordPath)
+	if err != nil { return err }
+	_, err = self.recording.WriteTo(file)
+	closeErr := file.Close()
+	if err != nil { return err }
+	return closeErr
+}
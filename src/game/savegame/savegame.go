@@ -0,0 +1,187 @@
+package savegame
+
+import "os"
+import "io/fs"
+import "sort"
+import "bytes"
+import "errors"
+import "path/filepath"
+import "strings"
+
+import "github.com/tinne26/transition/src/game/state"
+
+const DefaultSlot = "default"
+const appDirName  = "tinne26-transition"
+const saveExt     = ".sav"
+const backupExt   = ".bak"
+const tempExt     = ".tmp"
+const maxSlotNameLen = 32
+
+var ErrInvalidSlotName = errors.New("savegame: invalid slot name")
+
+// Manages multiple named save slots within a single directory. Each slot
+// is stored as "<name>.sav", and the previous version of the save is
+// kept as "<name>.sav.bak" in case the main file gets corrupted.
+//
+// Writes are atomic: data is written to a temporary file first and
+// then renamed over the main save file.
+type Manager struct {
+	dir string
+	activeSlot string
+}
+
+// Creates a manager that stores the saves under the OS user config
+// directory. May fail on platforms without one (e.g. browsers).
+func NewManager() (*Manager, error) {
+	appDir, err := AppDir()
+	if err != nil { return nil, err }
+	return NewManagerAt(filepath.Join(appDir, "saves"))
+}
+
+// Returns the game's directory within the OS user config directory,
+// also used for other settings. The directory might not exist yet.
+func AppDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil { return "", err }
+	return filepath.Join(configDir, appDirName), nil
+}
+
+func NewManagerAt(dir string) (*Manager, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil { return nil, err }
+	return &Manager{ dir: dir, activeSlot: DefaultSlot }, nil
+}
+
+func (self *Manager) Dir() string { return self.dir }
+func (self *Manager) ActiveSlot() string { return self.activeSlot }
+
+func (self *Manager) SetActiveSlot(name string) error {
+	if !IsValidSlotName(name) { return ErrInvalidSlotName }
+	self.activeSlot = name
+	return nil
+}
+
+// Returns the names of all the slots that have a save file or
+// backup in the manager's directory, in lexicographical order.
+func (self *Manager) Slots() ([]string, error) {
+	entries, err := os.ReadDir(self.dir)
+	if err != nil { return nil, err }
+
+	// (entries are sorted by file name, but "a-b.sav" comes
+	// before "a.sav.bak", so we can't dedupe adjacent names)
+	found := make(map[string]struct{}, len(entries))
+	slots := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() { continue }
+		name = strings.TrimSuffix(name, backupExt)
+		if !strings.HasSuffix(name, saveExt) { continue }
+		name = strings.TrimSuffix(name, saveExt)
+		if !IsValidSlotName(name) { continue }
+		if _, dupe := found[name]; dupe { continue }
+		found[name] = struct{}{}
+		slots = append(slots, name)
+	}
+	sort.Strings(slots)
+	return slots, nil
+}
+
+func (self *Manager) Save(gameState *state.State) error {
+	return self.SaveSlot(self.activeSlot, gameState)
+}
+
+func (self *Manager) Load(gameState *state.State) error {
+	return self.LoadSlot(self.activeSlot, gameState)
+}
+
+// Saves the given state into the given slot. The previous save,
+// if any, is kept as a backup.
+func (self *Manager) SaveSlot(name string, gameState *state.State) error {
+	if !IsValidSlotName(name) { return ErrInvalidSlotName }
+
+	var buffer bytes.Buffer
+	_, err := gameState.WriteTo(&buffer)
+	if err != nil { return err }
+
+	// write temp file and make sure it hits the disk
+	savePath := self.slotPath(name)
+	tempPath := savePath + tempExt
+	file, err := os.OpenFile(tempPath, os.O_WRONLY | os.O_CREATE | os.O_TRUNC, 0o644)
+	if err != nil { return err }
+	_, err = file.Write(buffer.Bytes())
+	if err == nil { err = file.Sync() }
+	closeErr := file.Close()
+	if err == nil { err = closeErr }
+	if err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+
+	// rotate backup and move the new save into place
+	err = os.Rename(savePath, savePath + backupExt)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		_ = os.Remove(tempPath)
+		return err
+	}
+	return os.Rename(tempPath, savePath)
+}
+
+// Loads the given slot into the given state. If the main save file
+// is missing or corrupted, the backup is tried before giving up. In
+// that case, the error for the main save file is returned. Missing
+// saves can be detected with errors.Is(err, fs.ErrNotExist).
+func (self *Manager) LoadSlot(name string, gameState *state.State) error {
+	if !IsValidSlotName(name) { return ErrInvalidSlotName }
+
+	savePath := self.slotPath(name)
+	err := loadFile(savePath, gameState)
+	if err == nil { return nil }
+	if !errors.Is(err, fs.ErrNotExist) && !isFormatError(err) { return err }
+	if loadFile(savePath + backupExt, gameState) == nil { return nil }
+	return err
+}
+
+func (self *Manager) DeleteSlot(name string) error {
+	if !IsValidSlotName(name) { return ErrInvalidSlotName }
+	savePath := self.slotPath(name)
+	err := os.Remove(savePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) { return err }
+	err = os.Remove(savePath + backupExt)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) { return err }
+	return nil
+}
+
+// Slot names must be between 1 and 32 characters long, and
+// only use lowercase ascii letters, digits, '_' and '-'.
+func IsValidSlotName(name string) bool {
+	if len(name) == 0 || len(name) > maxSlotNameLen { return false }
+	for i := 0; i < len(name); i++ {
+		b := name[i]
+		switch {
+		case b >= 'a' && b <= 'z', b >= '0' && b <= '9', b == '_', b == '-':
+			// ok
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// --- helpers ---
+
+func (self *Manager) slotPath(name string) string {
+	return filepath.Join(self.dir, name + saveExt)
+}
+
+func loadFile(path string, gameState *state.State) error {
+	file, err := os.Open(path)
+	if err != nil { return err }
+	defer file.Close()
+	_, err = gameState.ReadFrom(file)
+	return err
+}
+
+func isFormatError(err error) bool {
+	var formatErr state.FormatError
+	return errors.As(err, &formatErr)
+}
@@ -0,0 +1,162 @@
+package savegame
+
+import "os"
+import "io/fs"
+import "errors"
+import "reflect"
+import "testing"
+import "path/filepath"
+
+import "github.com/tinne26/transition/src/game/state"
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	manager, err := NewManagerAt(t.TempDir())
+	if err != nil { t.Fatal(err) }
+	return manager
+}
+
+func testState(stage uint8) *state.State {
+	gameState := state.New()
+	gameState.TransitionStage = stage
+	gameState.Switches[stage % uint8(len(gameState.Switches))] = true
+	return gameState
+}
+
+func TestSaveLoad(t *testing.T) {
+	manager := newTestManager(t)
+	err := manager.Save(testState(3))
+	if err != nil { t.Fatal(err) }
+
+	loaded := state.New()
+	err = manager.Load(loaded)
+	if err != nil { t.Fatal(err) }
+	if *loaded != *testState(3) { t.Fatalf("loaded %+v, expected %+v", *loaded, *testState(3)) }
+
+	// missing slots
+	err = manager.LoadSlot("missing", loaded)
+	if !errors.Is(err, fs.ErrNotExist) { t.Fatalf("expected fs.ErrNotExist, got %v", err) }
+}
+
+func TestBackupRotation(t *testing.T) {
+	manager := newTestManager(t)
+	err := manager.SaveSlot("slot", testState(1))
+	if err != nil { t.Fatal(err) }
+	savePath := filepath.Join(manager.Dir(), "slot.sav")
+	_, err = os.Stat(savePath + ".bak")
+	if !errors.Is(err, fs.ErrNotExist) { t.Fatalf("backup after the first save: %v", err) }
+
+	err = manager.SaveSlot("slot", testState(2))
+	if err != nil { t.Fatal(err) }
+	assertFileState(t, savePath, testState(2))
+	assertFileState(t, savePath + ".bak", testState(1))
+	_, err = os.Stat(savePath + ".tmp")
+	if !errors.Is(err, fs.ErrNotExist) { t.Fatalf("temp file left behind: %v", err) }
+}
+
+func TestBackupFallback(t *testing.T) {
+	manager := newTestManager(t)
+	for i := uint8(1); i <= 2; i++ {
+		err := manager.SaveSlot("slot", testState(i))
+		if err != nil { t.Fatal(err) }
+	}
+	savePath := filepath.Join(manager.Dir(), "slot.sav")
+
+	// corrupted main file
+	data, err := os.ReadFile(savePath)
+	if err != nil { t.Fatal(err) }
+	data[len(data) - 1] ^= 0xFF
+	err = os.WriteFile(savePath, data, 0o644)
+	if err != nil { t.Fatal(err) }
+	loaded := state.New()
+	err = manager.LoadSlot("slot", loaded)
+	if err != nil { t.Fatalf("expected backup to load, got %v", err) }
+	if *loaded != *testState(1) { t.Fatalf("loaded %+v, expected the backup", *loaded) }
+
+	// missing main file
+	err = os.Remove(savePath)
+	if err != nil { t.Fatal(err) }
+	loaded = state.New()
+	err = manager.LoadSlot("slot", loaded)
+	if err != nil { t.Fatalf("expected backup to load, got %v", err) }
+	if *loaded != *testState(1) { t.Fatalf("loaded %+v, expected the backup", *loaded) }
+
+	// both corrupted: the main file error is reported
+	err = os.WriteFile(savePath, data, 0o644)
+	if err != nil { t.Fatal(err) }
+	err = os.WriteFile(savePath + ".bak", data, 0o644)
+	if err != nil { t.Fatal(err) }
+	err = manager.LoadSlot("slot", state.New())
+	if !errors.Is(err, state.ErrChecksumMismatch) { t.Fatalf("expected checksum error, got %v", err) }
+}
+
+func TestSlots(t *testing.T) {
+	manager := newTestManager(t)
+	for _, name := range []string{"b", "a", "a-b"} {
+		err := manager.SaveSlot(name, testState(1))
+		if err != nil { t.Fatal(err) }
+	}
+	err := manager.SaveSlot("a", testState(2)) // creates a.sav.bak
+	if err != nil { t.Fatal(err) }
+	err = os.WriteFile(filepath.Join(manager.Dir(), "Invalid.sav"), nil, 0o644)
+	if err != nil { t.Fatal(err) }
+	err = os.WriteFile(filepath.Join(manager.Dir(), "notes.txt"), nil, 0o644)
+	if err != nil { t.Fatal(err) }
+
+	slots, err := manager.Slots()
+	if err != nil { t.Fatal(err) }
+	expected := []string{"a", "a-b", "b"}
+	if !reflect.DeepEqual(slots, expected) { t.Fatalf("got slots %q, expected %q", slots, expected) }
+
+	// deleting removes both the save and the backup
+	err = manager.DeleteSlot("a")
+	if err != nil { t.Fatal(err) }
+	for _, ext := range []string{".sav", ".sav.bak"} {
+		_, err = os.Stat(filepath.Join(manager.Dir(), "a" + ext))
+		if !errors.Is(err, fs.ErrNotExist) { t.Fatalf("a%s not deleted: %v", ext, err) }
+	}
+	slots, err = manager.Slots()
+	if err != nil { t.Fatal(err) }
+	expected = []string{"a-b", "b"}
+	if !reflect.DeepEqual(slots, expected) { t.Fatalf("got slots %q, expected %q", slots, expected) }
+	err = manager.DeleteSlot("a") // already gone
+	if err != nil { t.Fatal(err) }
+}
+
+func TestIsValidSlotName(t *testing.T) {
+	tests := []struct {
+		name string
+		valid bool
+	}{
+		{ "default", true },
+		{ "slot_2-b", true },
+		{ "01234567890123456789012345678901", true },
+		{ "012345678901234567890123456789012", false },
+		{ "", false },
+		{ "Slot", false },
+		{ "a b", false },
+		{ "../a", false },
+		{ "a.sav", false },
+		{ "ñ", false },
+	}
+	for _, test := range tests {
+		if IsValidSlotName(test.name) != test.valid {
+			t.Errorf("IsValidSlotName(%q) should be %t", test.name, test.valid)
+		}
+	}
+
+	manager := newTestManager(t)
+	if manager.SetActiveSlot("../a") != ErrInvalidSlotName { t.Fatal("expected ErrInvalidSlotName") }
+	if manager.SaveSlot("", state.New()) != ErrInvalidSlotName { t.Fatal("expected ErrInvalidSlotName") }
+}
+
+func assertFileState(t *testing.T, path string, expected *state.State) {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil { t.Fatal(err) }
+	defer file.Close()
+	loaded := state.New()
+	_, err = loaded.ReadFrom(file)
+	if err != nil { t.Fatalf("%s: %s", path, err) }
+	if *loaded != *expected { t.Fatalf("%s: got %+v, expected %+v", path, *loaded, *expected) }
+}
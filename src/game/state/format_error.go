@@ -0,0 +1,27 @@
+package state
+
+import "strconv"
+
+// Errors returned by [State.ReadFrom] when the input data
+// is not a valid save. Can be compared directly or with errors.Is().
+type FormatError uint8
+
+const (
+	ErrBadSignature FormatError = iota + 1
+	ErrUnsupportedVersion
+	ErrTruncated
+	ErrChecksumMismatch
+	ErrInvalidValue
+)
+
+func (self FormatError) Error() string {
+	switch self {
+	case ErrBadSignature: return "state: invalid save signature"
+	case ErrUnsupportedVersion: return "state: unsupported save version"
+	case ErrTruncated: return "state: truncated save data"
+	case ErrChecksumMismatch: return "state: save checksum mismatch"
+	case ErrInvalidValue: return "state: invalid value in save data"
+	default:
+		return "state: FormatError#" + strconv.Itoa(int(self))
+	}
+}
@@ -1,9 +1,29 @@
 package state
 
 import "io"
+import "hash/crc32"
+import "encoding/binary"
 
 import "github.com/tinne26/transition/src/game/level/lvlkey"
 
+// Binary save format (little endian):
+//  - [4]byte signature ("TRSV")
+//  - uint8 format version
+//  - uint8 transition stage
+//  - uint8 last save entry key
+//  - uint16 last save switch
+//  - uint16 number of stored switches (N)
+//  - [(N + 7)/8]byte switches bitset (LSB first)
+//  - uint32 CRC-32 (IEEE) of all the previous bytes
+//
+// Saves that store less switches than the current gameNumSwitches
+// are accepted (new switches are appended before lastSwitchSentinel,
+// so the old indices remain valid and the new ones start unset).
+const formatVersion uint8 = 1
+const formatSignature = "TRSV"
+const formatHeaderSize = 11
+const formatChecksumSize = 4
+
 type State struct {
 	TransitionStage uint8 // aka sword challenges done
 	LastSaveEntryKey lvlkey.EntryKey
@@ -17,10 +37,79 @@ func New() *State {
 	}
 }
 
-func (self *State) WriteTo(writer io.Writer) error {
-	panic("unimplemented")
+// Implements io.WriterTo.
+func (self *State) WriteTo(writer io.Writer) (int64, error) {
+	numSwitchBytes := (int(gameNumSwitches) + 7) >> 3
+	data := make([]byte, formatHeaderSize + numSwitchBytes, formatHeaderSize + numSwitchBytes + formatChecksumSize)
+
+	// header
+	copy(data[0 : 4], formatSignature)
+	data[4] = formatVersion
+	data[5] = self.TransitionStage
+	data[6] = uint8(self.LastSaveEntryKey)
+	binary.LittleEndian.PutUint16(data[7 : 9], uint16(self.LastSaveSwitch))
+	binary.LittleEndian.PutUint16(data[9 : 11], uint16(gameNumSwitches))
+
+	// switches bitset
+	for i, value := range self.Switches {
+		if !value { continue }
+		data[formatHeaderSize + (i >> 3)] |= 1 << (i & 0b111)
+	}
+
+	// checksum
+	data = binary.LittleEndian.AppendUint32(data, crc32.ChecksumIEEE(data))
+
+	n, err := writer.Write(data)
+	return int64(n), err
+}
+
+// Implements io.ReaderFrom. If an error is returned, the state is
+// left unmodified. Malformed data results in a [FormatError].
+func (self *State) ReadFrom(reader io.Reader) (int64, error) {
+	var total int64
+
+	// read and validate header
+	header := make([]byte, formatHeaderSize)
+	n, err := io.ReadFull(reader, header)
+	total += int64(n)
+	if err != nil { return total, unexpectedEOFToTruncated(err) }
+	if string(header[0 : 4]) != formatSignature { return total, ErrBadSignature }
+	if header[4] == 0 || header[4] > formatVersion { return total, ErrUnsupportedVersion }
+	numSwitches := binary.LittleEndian.Uint16(header[9 : 11])
+	if numSwitches > uint16(gameNumSwitches) { return total, ErrUnsupportedVersion }
+
+	// read switches and checksum
+	numSwitchBytes := (int(numSwitches) + 7) >> 3
+	tail := make([]byte, numSwitchBytes + formatChecksumSize)
+	n, err = io.ReadFull(reader, tail)
+	total += int64(n)
+	if err != nil { return total, unexpectedEOFToTruncated(err) }
+
+	// verify checksum
+	checksum := crc32.ChecksumIEEE(header)
+	checksum  = crc32.Update(checksum, crc32.IEEETable, tail[0 : numSwitchBytes])
+	if checksum != binary.LittleEndian.Uint32(tail[numSwitchBytes : ]) {
+		return total, ErrChecksumMismatch
+	}
+
+	// decode into a temporary state
+	var state State
+	state.TransitionStage = header[5]
+	state.LastSaveEntryKey = lvlkey.EntryKey(header[6])
+	state.LastSaveSwitch = Switch(binary.LittleEndian.Uint16(header[7 : 9]))
+	if state.LastSaveSwitch >= lastSwitchSentinel { return total, ErrInvalidValue }
+	for i := 0; i < int(numSwitches); i++ {
+		state.Switches[i] = (tail[i >> 3] & (1 << (i & 0b111))) != 0
+	}
+	if numSwitches & 0b111 != 0 && tail[numSwitchBytes - 1] >> (numSwitches & 0b111) != 0 {
+		return total, ErrInvalidValue // padding bits must be zero
+	}
+
+	*self = state
+	return total, nil
 }
 
-func (self *State) ReadFrom(reader io.Reader) error {
-	panic("unimplemented")
+func unexpectedEOFToTruncated(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF { return ErrTruncated }
+	return err
 }
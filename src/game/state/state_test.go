@@ -0,0 +1,138 @@
+package state
+
+import "bytes"
+import "errors"
+import "testing"
+import "hash/crc32"
+import "encoding/binary"
+
+import "github.com/tinne26/transition/src/game/level/lvlkey"
+
+func TestStateRoundTrip(t *testing.T) {
+	// one state per switch (only that switch set), plus all and none
+	var states []*State
+	for i := 0; i <= int(gameNumSwitches); i++ {
+		state := New()
+		state.TransitionStage = uint8(i*37)
+		state.LastSaveEntryKey = lvlkey.EntryKey(i*11 + 1)
+		state.LastSaveSwitch = Switch(i % int(gameNumSwitches))
+		if i < int(gameNumSwitches) {
+			state.Switches[i] = true
+		} else {
+			for j, _ := range state.Switches { state.Switches[j] = true }
+		}
+		states = append(states, state)
+	}
+	states = append(states, New())
+
+	for i, state := range states {
+		var buffer bytes.Buffer
+		n, err := state.WriteTo(&buffer)
+		if err != nil { t.Fatalf("state #%d: write failed: %s", i, err) }
+		if n != int64(buffer.Len()) { t.Fatalf("state #%d: WriteTo reported %d bytes, wrote %d", i, n, buffer.Len()) }
+
+		loaded := New()
+		n, err = loaded.ReadFrom(bytes.NewReader(buffer.Bytes()))
+		if err != nil { t.Fatalf("state #%d: read failed: %s", i, err) }
+		if n != int64(buffer.Len()) { t.Fatalf("state #%d: ReadFrom reported %d bytes, expected %d", i, n, buffer.Len()) }
+		if *loaded != *state { t.Fatalf("state #%d: round trip mismatch\n got  %+v\n want %+v", i, *loaded, *state) }
+	}
+}
+
+// Saves written before new switches were added must still load,
+// with the new switches unset.
+func TestStateFewerSwitches(t *testing.T) {
+	numOld := uint16(SwitchDlgSkeletonMet) // save from before the dialogue switch
+	data := rawSave(formatVersion, numOld, []bool{false, true, false, true, true})
+	state := New()
+	state.Switches[SwitchDlgSkeletonMet] = true // must be overwritten
+	_, err := state.ReadFrom(bytes.NewReader(data))
+	if err != nil { t.Fatalf("unexpected error: %s", err) }
+	var expected [gameNumSwitches]bool
+	copy(expected[ : ], []bool{false, true, false, true, true})
+	if state.Switches != expected {
+		t.Fatalf("got switches %v, expected %v", state.Switches, expected)
+	}
+
+	// no switches at all
+	_, err = state.ReadFrom(bytes.NewReader(rawSave(formatVersion, 0, nil)))
+	if err != nil { t.Fatalf("unexpected error: %s", err) }
+	if state.Switches != [gameNumSwitches]bool{} { t.Fatalf("expected all switches unset, got %v", state.Switches) }
+}
+
+func TestStateFormatErrors(t *testing.T) {
+	valid := rawSave(formatVersion, uint16(gameNumSwitches), make([]bool, gameNumSwitches))
+
+	badSignature := append([]byte(nil), valid[ : len(valid) - formatChecksumSize]...)
+	badSignature[0] = 'X'
+	badSignature = withChecksum(badSignature)
+
+	badChecksum := append([]byte(nil), valid...)
+	badChecksum[len(badChecksum) - 1] ^= 0xFF
+
+	// (needs padding bits, so it's only valid while gameNumSwitches % 8 != 0)
+	if gameNumSwitches % 8 == 0 { t.Fatal("no padding bits to test, update the test") }
+	badPadding := append([]byte(nil), valid[ : len(valid) - formatChecksumSize]...)
+	badPadding[len(badPadding) - 1] |= 0b1000_0000
+	badPadding = withChecksum(badPadding)
+
+	badSwitch := append([]byte(nil), valid[ : len(valid) - formatChecksumSize]...)
+	binary.LittleEndian.PutUint16(badSwitch[7 : 9], uint16(gameNumSwitches))
+	badSwitch = withChecksum(badSwitch)
+
+	tests := []struct {
+		name string
+		data []byte
+		err FormatError
+	}{
+		{ "bad signature", badSignature, ErrBadSignature },
+		{ "version zero", rawSave(0, 0, nil), ErrUnsupportedVersion },
+		{ "future version", rawSave(formatVersion + 1, 0, nil), ErrUnsupportedVersion },
+		{ "too many switches", rawSave(formatVersion, uint16(gameNumSwitches) + 1, make([]bool, gameNumSwitches + 1)), ErrUnsupportedVersion },
+		{ "bad checksum", badChecksum, ErrChecksumMismatch },
+		{ "bad padding", badPadding, ErrInvalidValue },
+		{ "bad last save switch", badSwitch, ErrInvalidValue },
+		{ "empty", nil, ErrTruncated },
+	}
+	for i := 0; i < len(valid); i++ {
+		tests = append(tests, struct {
+			name string
+			data []byte
+			err FormatError
+		}{ "truncated", valid[ : i], ErrTruncated })
+	}
+
+	for _, test := range tests {
+		state := New()
+		state.TransitionStage = 42
+		_, err := readNoPanic(t, state, test.data)
+		var formatErr FormatError
+		if !errors.As(err, &formatErr) || !errors.Is(err, test.err) {
+			t.Errorf("%s (%d bytes): expected %v, got %v", test.name, len(test.data), test.err, err)
+		}
+		if state.TransitionStage != 42 { t.Errorf("%s: state modified despite the error", test.name) }
+	}
+}
+
+func readNoPanic(t *testing.T, state *State, data []byte) (n int64, err error) {
+	defer func() {
+		if r := recover(); r != nil { t.Fatalf("ReadFrom panicked on %d bytes: %v", len(data), r) }
+	}()
+	return state.ReadFrom(bytes.NewReader(data))
+}
+
+// Builds a save by hand, with the given version and switches.
+func rawSave(version uint8, numSwitches uint16, switches []bool) []byte {
+	data := make([]byte, formatHeaderSize + (int(numSwitches) + 7)/8)
+	copy(data[0 : 4], formatSignature)
+	data[4] = version
+	binary.LittleEndian.PutUint16(data[9 : 11], numSwitches)
+	for i, value := range switches {
+		if value { data[formatHeaderSize + i/8] |= 1 << (i % 8) }
+	}
+	return withChecksum(data)
+}
+
+func withChecksum(data []byte) []byte {
+	return binary.LittleEndian.AppendUint32(data, crc32.ChecksumIEEE(data))
+}
@@ -1,5 +1,7 @@
 package state
 
+import "strconv"
+
 type Switch uint16
 
 const (
@@ -8,6 +10,7 @@ const (
 	SwitchTipJump
 	SwitchTipWallStick
 	SwitchSwordChallenge1
+	SwitchDlgSkeletonMet
 
 	// ... add additional game state switches here
 
@@ -16,3 +19,24 @@ const (
 )
 
 const gameNumSwitches = lastSwitchSentinel
+
+func (self Switch) String() string {
+	switch self {
+	case SwitchNone: return "none"
+	case SwitchTipMove: return "tip_move"
+	case SwitchTipJump: return "tip_jump"
+	case SwitchTipWallStick: return "tip_wall_stick"
+	case SwitchSwordChallenge1: return "sword_challenge_1"
+	case SwitchDlgSkeletonMet: return "dlg_skeleton_met"
+	default:
+		return "Switch#" + strconv.Itoa(int(self))
+	}
+}
+
+// Returns the switch with the given name, as returned by String().
+func SwitchFromName(name string) (Switch, bool) {
+	for key := Switch(0); key < lastSwitchSentinel; key++ {
+		if key.String() == name { return key, true }
+	}
+	return lastSwitchSentinel, false
+}
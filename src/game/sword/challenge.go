@@ -1,6 +1,5 @@
 package sword
 
-import "time"
 import "math"
 
 import "github.com/hajimehoshi/ebiten/v2"
@@ -27,7 +26,7 @@ type Challenge struct {
 	protectionAlpha float64
 	angleShift float64
 	consecutiveHold uint32
-	holdBgmFadedIn bool
+	beatPulse *shaders.PulseGenerator // created on the first update
 
 	holdMessage *text.Message
 	tapMessage *text.Message
@@ -45,8 +44,8 @@ func NewChallenge(x, y uint16) *Challenge {
 		opts: ebiten.DrawTrianglesShaderOptions{
 			Uniforms: make(map[string]any, 4),
 		},
-		holdMessage: text.NewMsg1("HOLD " + string(text.KeyO) + " TO ABSORB THE POWER", clr.HornsText),
-		tapMessage: text.NewMsg1("QUICKLY TAP " + string(text.KeyO) + " TO BREAK INTO THE SOURCE OF POWER", clr.HornsText),
+		holdMessage: text.NewWrappedMsg("@msg.sword_hold", clr.HornsText),
+		tapMessage: text.NewWrappedMsg("@msg.sword_tap", clr.HornsText),
 	}
 	return challenge
 }
@@ -56,6 +55,13 @@ const MaxExpansion = 1.3
 func (self *Challenge) Update(ctx *context.Context) error {
 	const FlashSpeed = 0.18
 
+	// the protection ring pulses on the beats of the challenge music
+	if self.beatPulse == nil {
+		self.beatPulse = shaders.NewPulseGenerator(0, 0.1, 0.05, 26, 26, 1)
+		self.beatPulse.LockTo(ctx.Audio.MusicClock(), 1)
+	}
+	self.beatPulse.Update()
+
 	// flashing
 	if self.flashChange > 0 {
 		self.flashAlpha += self.flashChange
@@ -75,14 +81,9 @@ func (self *Challenge) Update(ctx *context.Context) error {
 		}
 	}
 
-	// safety fade out stuff
-	if self.holdBgmFadedIn && self.consecutiveHold == 0 {
-		self.fadeOutHoldBgm(ctx)
-	}
-
 	// already over case
 	if self.hp == 0 {
-		if self.holdBgmFadedIn { self.fadeOutHoldBgm(ctx) }
+		ctx.Audio.SetMusicParam(audio.MusicParamPower, 0)
 		self.expansion -= 0.02
 		if self.expansion < 0 { self.expansion = 0 }
 		return nil
@@ -113,7 +114,7 @@ func (self *Challenge) Update(ctx *context.Context) error {
 	preConsecutiveHold := self.consecutiveHold
 	if self.isProtectionActive {
 		if acceptInput && ctx.Input.Trigger(input.ActionOutReverse) {
-			ctx.Audio.PlaySFX(audio.SfxSwordTap)
+			ctx.Audio.PlaySFXAt(audio.SfxSwordTap, self.X, self.Y)
 			self.protection -= 0.06
 			if self.protection <= 0.0 {
 				self.isProtectionActive = false
@@ -132,7 +133,6 @@ func (self *Challenge) Update(ctx *context.Context) error {
 			if ctx.Input.Pressed(input.ActionOutReverse) {
 				self.consecutiveHold += 1
 				if self.consecutiveHold < 10 {
-					if !self.holdBgmFadedIn { self.fadeInHoldBgm(ctx) }
 					self.hp -= 0.0002
 				} else {
 					self.hp -= 0.001
@@ -140,7 +140,7 @@ func (self *Challenge) Update(ctx *context.Context) error {
 				if self.hp < 0.0 {
 					self.flashChange = FlashSpeed
 					self.hp = 0.0
-					ctx.Audio.PlaySFX(audio.SfxSwordEnd)
+					ctx.Audio.PlaySFXAt(audio.SfxSwordEnd, self.X, self.Y)
 				}
 			}
 		}
@@ -148,9 +148,13 @@ func (self *Challenge) Update(ctx *context.Context) error {
 	
 	if self.consecutiveHold <= preConsecutiveHold {
 		self.consecutiveHold = 0
-		if self.holdBgmFadedIn {
-			self.fadeOutHoldBgm(ctx)
-		}
+	}
+
+	// music power layer while absorbing power
+	if self.consecutiveHold > 0 {
+		ctx.Audio.SetMusicParam(audio.MusicParamPower, 1)
+	} else {
+		ctx.Audio.SetMusicParam(audio.MusicParamPower, 0)
 	}
 
 	// update protection alpha
@@ -198,24 +202,14 @@ func (self *Challenge) Draw(activeCanvas *ebiten.Image) {
 	self.opts.Uniforms["AngleShift"] = self.angleShift
 	self.opts.Uniforms["Expansion"] = self.expansion
 	self.opts.Uniforms["HpLeft"] = min(self.hp, self.expansion)
-	self.opts.Uniforms["ProtectionAlpha"] = self.protectionAlpha
+	protectionAlpha := self.protectionAlpha
+	if self.beatPulse != nil { protectionAlpha += float64(self.beatPulse.CurrentValue()) }
+	self.opts.Uniforms["ProtectionAlpha"] = protectionAlpha
 	self.opts.Uniforms["ProtectionLevel"] = min(self.protection, self.expansion)
 	self.opts.Uniforms["FlashAlpha"] = self.flashAlpha
 	activeCanvas.DrawTrianglesShader(self.vertices[:], []uint16{0, 1, 2, 1, 3, 2}, shaders.SwordChallenge, &self.opts)
 }
 
-func (self *Challenge) fadeOutHoldBgm(ctx *context.Context) {
-	self.holdBgmFadedIn = false
-	fader := ctx.Audio.AutomationPanel().GetResource(audio.ResKeyChallengeFader).(*audio.Fader)
-	fader.Transition(0.0, 0, audio.TimeDurationToSamples(time.Millisecond*200))
-}
-
-func (self *Challenge) fadeInHoldBgm(ctx *context.Context) {
-	self.holdBgmFadedIn = true
-	fader := ctx.Audio.AutomationPanel().GetResource(audio.ResKeyChallengeFader).(*audio.Fader)
-	fader.Transition(1.0, 0, audio.TimeDurationToSamples(time.Millisecond*200))
-}
-
 func min(a, b float64) float64 {
 	if a <= b { return a }
 	return b
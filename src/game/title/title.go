@@ -1,7 +1,6 @@
 package title
 
 import "math"
-import "math/rand"
 import "image"
 import "image/color"
 
@@ -14,23 +13,11 @@ import "github.com/tinne26/transition/src/text"
 import "github.com/tinne26/transition/src/game/clr"
 import "github.com/tinne26/transition/src/game/context"
 import "github.com/tinne26/transition/src/shaders"
+import "github.com/tinne26/transition/src/rng"
 
 const TitleText  = "TRANSITION"
 const TitleScale = 8
-var ContextText = []string{
-	"TO MY SURPRISE, IT HAD BEEN A QUIET JOURNEY;",
-	"UNEVENTFUL, BORING ALMOST.",
-	"",
-	"AS I ENTERED THE OUTER RING OF LETHIEN'S DOMAINS, THOUGH,",
-	"MY FRAME OF MIND SHIFTED ALONGSIDE THE SCENERY.",
-	"",
-	"I STARTED TO GROW RESTLESS...",
-	"",
-	"WISHING FOR THE PREVIOUS QUIETNESS TO REMAIN BY MY SIDE,",
-	"EVEN IF ONLY FOR A FEW MORE STEPS.",
-	"",
-	"[PRESS " + string(text.KeyI) + " TO CONTINUE]",
-}
+var ContextText = []string{"@title.context"}
 
 type Stage uint8
 const (
@@ -197,7 +184,7 @@ func (self *Title) Draw(logicalCanvas *ebiten.Image) {
 		logicalCanvas.DrawImage(self.ebiTitleRender, &opts)
 
 		// draw helper text so the player knows what to do
-		auxText := "[ PRESS " + string(text.KeyI) + " TO START ]"
+		auxText := "@title.press_start"
 		self.helpTextMaxTicks = utils.Max(self.stageElapsedTicks - 140, self.helpTextMaxTicks)
 		helpTextAlphaFactor := utils.Min(float64(self.helpTextMaxTicks)*0.006, 1.0)*opacity
 		ox, oy = (canvasWidth - text.MeasureLineWidth(auxText))/2, oy + titleHeight + titleHeight/2 - text.LineHeight/2
@@ -251,14 +238,14 @@ func (self *Title) updateTitleTransitions() {
 
 	self.untilNewTransition -= 1
 	if self.untilNewTransition == 0 {
-		untilNewTransition := minTickTransitionMargin*float64(2 + rand.Intn(8))
+		untilNewTransition := minTickTransitionMargin*float64(2 + rng.Main.Intn(8))
 		self.untilNewTransition = int64(untilNewTransition)
 		colors := []color.RGBA{
 			clr.Dark,
 			color.RGBA{255, 255, 255, 255},
 			color.RGBA{0, 0, 0, 32},
 		}
-		self.transitions = append(self.transitions, newTransition(colors[rand.Intn(len(colors))]))
+		self.transitions = append(self.transitions, newTransition(colors[rng.Main.Intn(len(colors))]))
 	}
 }
 
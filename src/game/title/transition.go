@@ -2,9 +2,9 @@ package title
 
 import "image"
 import "image/color"
-import "math/rand"
 
 import "github.com/tinne26/transition/src/utils"
+import "github.com/tinne26/transition/src/rng"
 
 const transitionWindowSize = 128
 const transitionSpeed = 1.4
@@ -33,7 +33,7 @@ func (self *Transition) Update(img *image.RGBA) bool {
 
 		// paint each filled pixel
 		for y := 0; y < h; y++ {
-			if rand.Float64() <= fillProb {
+			if rng.Main.Float64() <= fillProb {
 				img.SetRGBA(x, y, self.rgba)
 			}
 		}
@@ -0,0 +1,48 @@
+package trigger
+
+import "github.com/tinne26/transition/src/input"
+import "github.com/tinne26/transition/src/audio"
+import "github.com/tinne26/transition/src/game/context"
+import "github.com/tinne26/transition/src/game/u16"
+import "github.com/tinne26/transition/src/game/player/motion"
+import "github.com/tinne26/transition/src/game/hint"
+import "github.com/tinne26/transition/src/game/dialogue"
+
+var _ Trigger = (*TrigDialogue)(nil)
+
+// Like TrigInteractText, but starting a dialogue instead.
+type TrigDialogue struct {
+	area u16.Rect
+	ihint hint.Hint
+	script *dialogue.Script
+}
+
+func NewDialogue(area u16.Rect, ihint hint.Hint, script *dialogue.Script) Trigger {
+	return &TrigDialogue{
+		area: area,
+		ihint: ihint,
+		script: script,
+	}
+}
+
+func (self *TrigDialogue) Update(player motion.Shot, ctx *context.Context) (any, error) {
+	if !self.area.Overlap(player.Rect) { return nil, nil }
+	if !player.IsLookingTowards(self.area.GetCenterX()) { return nil, nil }
+
+	if !ctx.Input.Trigger(input.ActionInteract) { return self.ihint, nil }
+	ctx.Audio.PlaySFX(audio.SfxInteract)
+	dlg, err := self.script.Start(ctx)
+	if err != nil { return nil, err }
+	if dlg.IsOver() { return nil, nil } // nothing to show
+	return dlg, nil
+}
+
+func (self *TrigDialogue) OnLevelEnter(_ *context.Context) {}
+func (self *TrigDialogue) OnLevelExit(_ *context.Context) {}
+func (self *TrigDialogue) OnDeath(_ *context.Context) {}
+
+// --- getters (used when exporting levels) ---
+
+func (self *TrigDialogue) Area() u16.Rect { return self.area }
+func (self *TrigDialogue) Hint() hint.Hint { return self.ihint }
+func (self *TrigDialogue) Script() *dialogue.Script { return self.script }
@@ -40,3 +40,9 @@ func (self *TrigInteractText) Update(player motion.Shot, ctx *context.Context) (
 func (self *TrigInteractText) OnLevelEnter(_ *context.Context) {}
 func (self *TrigInteractText) OnLevelExit(_ *context.Context) {}
 func (self *TrigInteractText) OnDeath(_ *context.Context) {}
+
+// --- getters (used when exporting levels) ---
+
+func (self *TrigInteractText) Area() u16.Rect { return self.area }
+func (self *TrigInteractText) Hint() hint.Hint { return self.ihint }
+func (self *TrigInteractText) Text() []string { return self.text }
@@ -62,3 +62,18 @@ func (self *TrigLevelTransfer) Update(player motion.Shot, _ *context.Context) (a
 func (self *TrigLevelTransfer) OnLevelEnter(_ *context.Context) {}
 func (self *TrigLevelTransfer) OnLevelExit(_ *context.Context) {}
 func (self *TrigLevelTransfer) OnDeath(_ *context.Context) {}
+
+// --- getters (used when exporting levels) ---
+
+func (self *TrigLevelTransfer) Dir() TransferDir { return self.dir }
+func (self *TrigLevelTransfer) EntryKey() lvlkey.EntryKey { return self.trans.Key }
+
+// Returns the x and y values originally passed to NewLevelTransfer().
+func (self *TrigLevelTransfer) Origin() (uint16, uint16) {
+	switch self.dir {
+	case RightTransfer: return self.area.Max.X, self.area.Max.Y
+	case LeftTransfer: return self.area.Min.X, self.area.Max.Y
+	default:
+		panic(self.dir)
+	}
+}
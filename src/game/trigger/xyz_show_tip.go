@@ -36,9 +36,11 @@ func (self *TrigShowTip) Update(player motion.Shot, ctx *context.Context) (any,
 		return nil, nil
 	}
 
-	// "remove" the trigger if using the right key
-	if ctx.Input.Trigger(input.ActionInteract) {
+	// show the next page or "remove" the trigger if using the right key
+	canAdvance := self.msg.HasNextPage() || self.msg.IsSkippable
+	if canAdvance && ctx.Input.Trigger(input.ActionInteract) {
 		ctx.Audio.PlaySFX(audio.SfxInteract)
+		if self.msg.NextPage() { return self.msg, nil }
 		ctx.State.Switches[self.clearedSwitch] = true
 		return nil, nil
 	}
@@ -46,7 +48,16 @@ func (self *TrigShowTip) Update(player motion.Shot, ctx *context.Context) (any,
 	return self.msg, nil
 }
 
-func (self *TrigShowTip) OnLevelEnter(_ *context.Context) {}
+func (self *TrigShowTip) OnLevelEnter(_ *context.Context) {
+	self.msg.ResetPages()
+}
 func (self *TrigShowTip) OnLevelExit(_ *context.Context) {}
 func (self *TrigShowTip) OnDeath(_ *context.Context) {}
 
+// --- getters (used when exporting levels) ---
+
+func (self *TrigShowTip) Area() u16.Rect { return self.area }
+func (self *TrigShowTip) ClearedArea() u16.Rect { return self.clearedArea }
+func (self *TrigShowTip) Message() *text.Message { return self.msg }
+func (self *TrigShowTip) ClearedSwitch() state.Switch { return self.clearedSwitch }
+
@@ -65,3 +65,9 @@ func (self *TrigSwitchSave) Update(player motion.Shot, ctx *context.Context) (an
 func (self *TrigSwitchSave) OnLevelEnter(_ *context.Context) {}
 func (self *TrigSwitchSave) OnLevelExit(_ *context.Context) {}
 func (self *TrigSwitchSave) OnDeath(_ *context.Context) {}
+
+// --- getters (used when exporting levels) ---
+
+func (self *TrigSwitchSave) Area() u16.Rect { return self.area }
+func (self *TrigSwitchSave) EntryKey() lvlkey.EntryKey { return self.key }
+func (self *TrigSwitchSave) Hint() hint.Hint { return self.trigHint }
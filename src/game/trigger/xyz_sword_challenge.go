@@ -51,3 +51,10 @@ func (self *TrigSwordChallenge) Update(player motion.Shot, ctx *context.Context)
 func (self *TrigSwordChallenge) OnLevelEnter(_ *context.Context) {}
 func (self *TrigSwordChallenge) OnLevelExit(_ *context.Context) {}
 func (self *TrigSwordChallenge) OnDeath(_ *context.Context) {}
+
+// --- getters (used when exporting levels) ---
+
+func (self *TrigSwordChallenge) Area() u16.Rect { return self.area }
+func (self *TrigSwordChallenge) Hint() hint.Hint { return self.ihint }
+func (self *TrigSwordChallenge) Challenge() *sword.Challenge { return self.challenge }
+func (self *TrigSwordChallenge) DoneSwitch() state.Switch { return self.doneSwitch }
@@ -19,10 +19,35 @@ const (
 	ActionCenterCamera
 	ActionFullscreen
 	ActionFullscreen2
+	ActionQuit
 	
 	actionEndSentinel
 )
 
 func (self Action) String() string {
-	return "Action#" + strconv.Itoa(int(self))
+	switch self {
+	case ActionMoveRight: return "move_right"
+	case ActionMoveLeft: return "move_left"
+	case ActionUp: return "up"
+	case ActionDown: return "down"
+	case ActionJump: return "jump"
+	case ActionInteract: return "interact"
+	case ActionOutReverse: return "out_reverse"
+	case ActionOnePixelRight: return "one_pixel_right"
+	case ActionOnePixelLeft: return "one_pixel_left"
+	case ActionCenterCamera: return "center_camera"
+	case ActionFullscreen: return "fullscreen"
+	case ActionFullscreen2: return "fullscreen2"
+	case ActionQuit: return "quit"
+	default:
+		return "Action#" + strconv.Itoa(int(self))
+	}
+}
+
+// Returns the action with the given name, as returned by String().
+func ActionFromName(name string) (Action, bool) {
+	for action := Action(0); action < actionEndSentinel; action++ {
+		if action.String() == name { return action, true }
+	}
+	return actionEndSentinel, false
 }
@@ -0,0 +1,135 @@
+package input
+
+import "math"
+import "errors"
+import "strconv"
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+const NumAxes = int(axisEndSentinel)
+
+// Logical analog axes. Movement axes go from -1 to 1 (left/up to
+// right/down) and map onto the movement actions, while trigger axes
+// go from 0 to 1 and are only available through Input.Axis().
+type Axis uint8
+const (
+	AxisMoveX Axis = iota
+	AxisMoveY
+	AxisTriggerLeft
+	AxisTriggerRight
+
+	axisEndSentinel
+)
+
+func (self Axis) String() string {
+	switch self {
+	case AxisMoveX: return "move_x"
+	case AxisMoveY: return "move_y"
+	case AxisTriggerLeft: return "trigger_left"
+	case AxisTriggerRight: return "trigger_right"
+	default:
+		return "Axis#" + strconv.Itoa(int(self))
+	}
+}
+
+// Returns the axis with the given name, as returned by String().
+func AxisFromName(name string) (Axis, bool) {
+	for axis := Axis(0); axis < axisEndSentinel; axis++ {
+		if axis.String() == name { return axis, true }
+	}
+	return axisEndSentinel, false
+}
+
+// Returns the negative and positive direction actions for the
+// given axis. Trigger axes have no associated actions.
+func (self Axis) actions() (Action, Action, bool) {
+	switch self {
+	case AxisMoveX: return ActionMoveLeft, ActionMoveRight, true
+	case AxisMoveY: return ActionUp, ActionDown, true
+	default:
+		return actionEndSentinel, actionEndSentinel, false
+	}
+}
+
+// Optional interface for sources that can report analog values.
+// Sources that don't implement it (like ScriptedSource) get their
+// axis values derived from the digital actions instead.
+type AxisSource interface {
+	Source
+	
+	// Returns the processed value for the given axis, with dead
+	// zones already applied.
+	AxisValue(axis Axis) float64
+}
+
+// Dead zone and hysteresis configuration for analog inputs.
+// All values are fractions of the full axis range.
+type DeadZones struct {
+	Inner float64 // values below this are considered zero
+	Outer float64 // values above 1 - Outer are considered one
+
+	// When an axis reaches Press, its direction action is considered
+	// pressed, and it stays pressed until the axis goes below Release.
+	// Release must be <= Press.
+	Press float64
+	Release float64
+}
+
+var DefaultDeadZones = DeadZones{ Inner: 0.2, Outer: 0.05, Press: 0.5, Release: 0.35 }
+
+// Applies the inner and outer dead zones, rescaling the remaining
+// range so the output still goes smoothly from 0 to 1 (keeping the
+// sign of the input value).
+func (self *DeadZones) Apply(value float64) float64 {
+	abs := math.Abs(value)
+	if abs <= self.Inner { return 0 }
+	maxValue := 1.0 - self.Outer
+	if abs >= maxValue || maxValue <= self.Inner { return math.Copysign(1.0, value) }
+	return math.Copysign((abs - self.Inner)/(maxValue - self.Inner), value)
+}
+
+// Applies hysteresis to the given processed axis value. The current
+// direction must be -1, 0 or +1, and the new direction is returned.
+func (self *DeadZones) Direction(value float64, current int8) int8 {
+	if current != 0 && value*float64(current) >= self.Release { return current }
+	if value >= self.Press { return +1 }
+	if value <= -self.Press { return -1 }
+	return 0
+}
+
+func (self *DeadZones) validate() error {
+	if self.Inner < 0 || self.Outer < 0 || self.Inner + self.Outer >= 1 {
+		return errors.New("input: dead zones must be positive and add up to less than 1")
+	}
+	if self.Release > self.Press || self.Press > 1 || self.Release <= 0 {
+		return errors.New("input: axis release threshold must be > 0 and <= press threshold <= 1")
+	}
+	return nil
+}
+
+// Physical axis for a logical movement axis. Triggers are read
+// from the standard trigger buttons and can't be rebound.
+type AxisBinding struct {
+	StdAxis ebiten.StandardGamepadAxis
+	Inverted bool
+}
+
+// Returns the name of the standard axis as used in the controls config files.
+func StdAxisName(axis ebiten.StandardGamepadAxis) string {
+	switch axis {
+	case ebiten.StandardGamepadAxisLeftStickHorizontal: return "left_stick_x"
+	case ebiten.StandardGamepadAxisLeftStickVertical: return "left_stick_y"
+	case ebiten.StandardGamepadAxisRightStickHorizontal: return "right_stick_x"
+	case ebiten.StandardGamepadAxisRightStickVertical: return "right_stick_y"
+	default:
+		return "StdAxis#" + strconv.Itoa(int(axis))
+	}
+}
+
+// Returns the standard axis with the given name, as returned by StdAxisName().
+func StdAxisFromName(name string) (ebiten.StandardGamepadAxis, bool) {
+	for axis := ebiten.StandardGamepadAxis(0); axis <= ebiten.StandardGamepadAxisMax; axis++ {
+		if StdAxisName(axis) == name { return axis, true }
+	}
+	return -1, false
+}
@@ -0,0 +1,147 @@
+package input
+
+import "testing"
+
+func TestDeadZonesApply(t *testing.T) {
+	zones := DeadZones{ Inner: 0.2, Outer: 0.05, Press: 0.5, Release: 0.35 }
+	tests := []struct{ in, out float64 }{
+		{ 0, 0 }, { 0.1, 0 }, { 0.2, 0 }, { -0.2, 0 },
+		{ 0.575, 0.5 }, { -0.575, -0.5 },
+		{ 0.95, 1 }, { 0.99, 1 }, { -0.99, -1 }, { 1, 1 },
+	}
+	for _, test := range tests {
+		got := zones.Apply(test.in)
+		if got < test.out - 1e-9 || got > test.out + 1e-9 {
+			t.Errorf("Apply(%v) = %v, expected %v", test.in, got, test.out)
+		}
+	}
+}
+
+func TestDeadZonesDirection(t *testing.T) {
+	zones := DefaultDeadZones
+	tests := []struct{
+		value float64
+		current int8
+		expected int8
+	}{
+		{ 0.5, 0, +1 },
+		{ 0.45, 0, 0 },
+		{ 0.45, +1, +1 }, // hysteresis keeps it pressed
+		{ 0.35, +1, +1 },
+		{ 0.3, +1, 0 },
+		{ -0.5, 0, -1 },
+		{ -0.45, -1, -1 },
+		{ -0.3, -1, 0 },
+		{ -0.6, +1, -1 }, // direct flip
+		{ 0.6, -1, +1 },
+	}
+	for _, test := range tests {
+		got := zones.Direction(test.value, test.current)
+		if got != test.expected {
+			t.Errorf("Direction(%v, %d) = %d, expected %d", test.value, test.current, got, test.expected)
+		}
+	}
+}
+
+func TestDeadZonesValidate(t *testing.T) {
+	tests := []struct{
+		zones DeadZones
+		valid bool
+	}{
+		{ DefaultDeadZones, true },
+		{ DeadZones{ 0, 0, 1, 1 }, true },
+		{ DeadZones{ -0.1, 0.05, 0.5, 0.35 }, false },
+		{ DeadZones{ 0.2, -0.05, 0.5, 0.35 }, false },
+		{ DeadZones{ 0.6, 0.4, 0.5, 0.35 }, false },
+		{ DeadZones{ 0.2, 0.05, 0.5, 0.6 }, false }, // release > press
+		{ DeadZones{ 0.2, 0.05, 1.1, 0.35 }, false },
+		{ DeadZones{ 0.2, 0.05, 0.5, 0 }, false },
+	}
+	for _, test := range tests {
+		err := test.zones.validate()
+		if (err == nil) != test.valid {
+			t.Errorf("%+v: expected valid = %t, got error %v", test.zones, test.valid, err)
+		}
+	}
+}
+
+// Scripted source with settable analog values.
+type testAxisSource struct {
+	*ScriptedSource
+	values [NumAxes]float64
+}
+
+func (self *testAxisSource) AxisValue(axis Axis) float64 {
+	return self.values[axis]
+}
+
+func TestAxisDigitalFallback(t *testing.T) {
+	source := NewScriptedSource()
+	source.Hold(NewActionSet(ActionMoveRight), 1)
+	source.Hold(NewActionSet(ActionMoveLeft), 1)
+	source.Hold(NewActionSet(ActionMoveLeft, ActionMoveRight), 1)
+	source.Hold(NewActionSet(ActionUp), 1)
+	source.Hold(NewActionSet(ActionDown), 2)
+	expected := []struct{ x, y float64 }{
+		{ +1, 0 }, { -1, 0 }, { 0, 0 }, { 0, -1 }, { 0, +1 },
+	}
+
+	input := NewInputFromSource(source)
+	for tick, values := range expected {
+		err := input.Update()
+		if err != nil { t.Fatal(err) }
+		x, y := input.Axis(AxisMoveX), input.Axis(AxisMoveY)
+		if x != values.x || y != values.y {
+			t.Fatalf("tick %d: got axes (%v, %v), expected (%v, %v)", tick, x, y, values.x, values.y)
+		}
+	}
+
+	// unwound directions report 0 until released
+	input.Unwind()
+	err := input.Update()
+	if err != nil { t.Fatal(err) }
+	if input.Axis(AxisMoveY) != 0 { t.Fatalf("unwound axis reported %v", input.Axis(AxisMoveY)) }
+}
+
+func TestAxisAnalog(t *testing.T) {
+	source := &testAxisSource{ ScriptedSource: NewScriptedSource() }
+	source.Hold(NewActionSet(ActionMoveRight), 3)
+	source.Wait(1)
+	source.Hold(NewActionSet(ActionMoveRight), 1)
+	input := NewInputFromSource(source)
+
+	source.values[AxisMoveX] = 0.7
+	source.values[AxisTriggerLeft] = 0.25
+	err := input.Update()
+	if err != nil { t.Fatal(err) }
+	if input.Axis(AxisMoveX) != 0.7 { t.Fatalf("got move_x %v, expected 0.7", input.Axis(AxisMoveX)) }
+	if input.Axis(AxisTriggerLeft) != 0.25 {
+		t.Fatalf("got trigger_left %v, expected 0.25", input.Axis(AxisTriggerLeft))
+	}
+
+	// analog values take precedence over the digital direction
+	source.values[AxisMoveX] = 0.3
+	err = input.Update()
+	if err != nil { t.Fatal(err) }
+	if input.Axis(AxisMoveX) != 0.3 { t.Fatalf("got move_x %v, expected 0.3", input.Axis(AxisMoveX)) }
+
+	// unwinding silences the held direction, but not the triggers
+	input.Unwind()
+	err = input.Update()
+	if err != nil { t.Fatal(err) }
+	if input.Axis(AxisMoveX) != 0 { t.Fatalf("unwound axis reported %v", input.Axis(AxisMoveX)) }
+	if input.Axis(AxisTriggerLeft) != 0.25 {
+		t.Fatalf("got trigger_left %v after unwinding, expected 0.25", input.Axis(AxisTriggerLeft))
+	}
+
+	// the opposite direction isn't unwound
+	source.values[AxisMoveX] = -0.3
+	err = input.Update() // move_right released
+	if err != nil { t.Fatal(err) }
+	if input.Axis(AxisMoveX) != -0.3 { t.Fatalf("got move_x %v, expected -0.3", input.Axis(AxisMoveX)) }
+
+	source.values[AxisMoveX] = 0.8
+	err = input.Update() // move_right pressed again
+	if err != nil { t.Fatal(err) }
+	if input.Axis(AxisMoveX) != 0.8 { t.Fatalf("got move_x %v after release, expected 0.8", input.Axis(AxisMoveX)) }
+}
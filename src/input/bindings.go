@@ -0,0 +1,288 @@
+package input
+
+import "errors"
+import "strconv"
+import "strings"
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// Keyboard and gamepad bindings for each action. Each action can
+// have multiple keys and buttons, or none at all, but the same key
+// or button can't be bound to more than one action.
+//
+// Bindings also include the gamepad sticks used for the movement
+// axes, and the dead zones applied to all analog inputs.
+type Bindings struct {
+	keys [NumActions][]ebiten.Key
+	buttons [NumActions][]ebiten.StandardGamepadButton
+	axes [NumAxes]AxisBinding // StdAxis == -1 if unbound
+	deadZones DeadZones
+}
+
+// Error returned when trying to bind a key or button that's already
+// bound to a different action.
+type ConflictError struct {
+	Binding string // key or button name
+	Action Action // action we were trying to bind
+	BoundTo Action // action that already had the binding
+}
+
+func (self *ConflictError) Error() string {
+	return "input: " + self.Binding + " can't be bound to " + self.Action.String() +
+		", already bound to " + self.BoundTo.String()
+}
+
+// Creates bindings from the given mappings, like the ones in
+// KeyboardPresets and GamepadPresets. Negative gamepad buttons
+// are considered unassigned.
+func NewBindings(keyboardMappings map[Action]ebiten.Key, gamepadMappings map[Action]ebiten.StandardGamepadButton) (*Bindings, error) {
+	bindings := newEmptyBindings()
+	bindings.axes[AxisMoveX].StdAxis = ebiten.StandardGamepadAxisLeftStickHorizontal
+	bindings.axes[AxisMoveY].StdAxis = ebiten.StandardGamepadAxisLeftStickVertical
+	for action, key := range keyboardMappings {
+		err := bindings.AddKey(action, key)
+		if err != nil { return nil, err }
+	}
+	for action, btn := range gamepadMappings {
+		if btn < 0 { continue }
+		err := bindings.AddButton(action, btn)
+		if err != nil { return nil, err }
+	}
+	return bindings, nil
+}
+
+// Creates bindings from the presets with the given names.
+func NewBindingsFromPresets(keyboardPreset, gamepadPreset string) (*Bindings, error) {
+	keyboardMappings, found := KeyboardPresets[keyboardPreset]
+	if !found { return nil, errors.New("input: unknown keyboard preset '" + keyboardPreset + "'") }
+	gamepadMappings, found := GamepadPresets[gamepadPreset]
+	if !found { return nil, errors.New("input: unknown gamepad preset '" + gamepadPreset + "'") }
+	return NewBindings(keyboardMappings, gamepadMappings)
+}
+
+// Creates bindings without any keys, buttons nor sticks bound.
+func newEmptyBindings() *Bindings {
+	bindings := &Bindings{ deadZones: DefaultDeadZones }
+	for i, _ := range bindings.axes {
+		bindings.axes[i].StdAxis = -1
+	}
+	return bindings
+}
+
+func (self *Bindings) Clone() *Bindings {
+	clone := &Bindings{ axes: self.axes, deadZones: self.deadZones }
+	for i := 0; i < NumActions; i++ {
+		clone.keys[i] = append([]ebiten.Key(nil), self.keys[i]...)
+		clone.buttons[i] = append([]ebiten.StandardGamepadButton(nil), self.buttons[i]...)
+	}
+	return clone
+}
+
+// Returns the keys bound to the given action, in the order they were
+// bound. The first key is the primary one. The slice must not be modified.
+func (self *Bindings) Keys(action Action) []ebiten.Key {
+	return self.keys[action]
+}
+
+// Like Keys(), but for gamepad buttons.
+func (self *Bindings) Buttons(action Action) []ebiten.StandardGamepadButton {
+	return self.buttons[action]
+}
+
+// Returns the action the key is bound to, if any.
+func (self *Bindings) KeyAction(key ebiten.Key) (Action, bool) {
+	for action, keys := range self.keys {
+		for _, boundKey := range keys {
+			if boundKey == key { return Action(action), true }
+		}
+	}
+	return actionEndSentinel, false
+}
+
+// Returns the action the button is bound to, if any.
+func (self *Bindings) ButtonAction(btn ebiten.StandardGamepadButton) (Action, bool) {
+	for action, buttons := range self.buttons {
+		for _, boundBtn := range buttons {
+			if boundBtn == btn { return Action(action), true }
+		}
+	}
+	return actionEndSentinel, false
+}
+
+// Binds an additional key to the given action. If the key is already
+// bound to a different action, a *ConflictError is returned and the
+// bindings are left unmodified. Binding a key twice to the same action
+// is allowed and has no effect.
+func (self *Bindings) AddKey(action Action, key ebiten.Key) error {
+	if action >= actionEndSentinel { panic(action) }
+	boundTo, isBound := self.KeyAction(key)
+	if isBound {
+		if boundTo == action { return nil }
+		return &ConflictError{ Binding: KeyName(key), Action: action, BoundTo: boundTo }
+	}
+	self.keys[action] = append(self.keys[action], key)
+	return nil
+}
+
+// Like AddKey(), but for gamepad buttons.
+func (self *Bindings) AddButton(action Action, btn ebiten.StandardGamepadButton) error {
+	if action >= actionEndSentinel { panic(action) }
+	if btn < 0 || btn > ebiten.StandardGamepadButtonMax { panic(btn) }
+	boundTo, isBound := self.ButtonAction(btn)
+	if isBound {
+		if boundTo == action { return nil }
+		return &ConflictError{ Binding: ButtonName(btn), Action: action, BoundTo: boundTo }
+	}
+	self.buttons[action] = append(self.buttons[action], btn)
+	return nil
+}
+
+// Unbinds the given key, whatever action it was bound to.
+func (self *Bindings) RemoveKey(key ebiten.Key) {
+	for action, keys := range self.keys {
+		for i, boundKey := range keys {
+			if boundKey != key { continue }
+			self.keys[action] = append(keys[ : i], keys[i + 1 : ]...)
+			return
+		}
+	}
+}
+
+// Unbinds the given button, whatever action it was bound to.
+func (self *Bindings) RemoveButton(btn ebiten.StandardGamepadButton) {
+	for action, buttons := range self.buttons {
+		for i, boundBtn := range buttons {
+			if boundBtn != btn { continue }
+			self.buttons[action] = append(buttons[ : i], buttons[i + 1 : ]...)
+			return
+		}
+	}
+}
+
+// Binds the key to the given action, replacing all its previous
+// keys. If the key was bound to another action, it's unbound from it.
+// Returns the action the key was taken from, if any.
+func (self *Bindings) RebindKey(action Action, key ebiten.Key) (Action, bool) {
+	prevAction, wasBound := self.KeyAction(key)
+	if wasBound && prevAction == action { wasBound = false }
+	self.RemoveKey(key)
+	self.keys[action] = append(self.keys[action][ : 0], key)
+	return prevAction, wasBound
+}
+
+// Like RebindKey(), but for gamepad buttons.
+func (self *Bindings) RebindButton(action Action, btn ebiten.StandardGamepadButton) (Action, bool) {
+	prevAction, wasBound := self.ButtonAction(btn)
+	if wasBound && prevAction == action { wasBound = false }
+	self.RemoveButton(btn)
+	self.buttons[action] = append(self.buttons[action][ : 0], btn)
+	return prevAction, wasBound
+}
+
+// Returns the stick axis bound to the given movement axis, if any.
+func (self *Bindings) StickAxis(axis Axis) (AxisBinding, bool) {
+	binding := self.axes[axis]
+	return binding, binding.StdAxis >= 0
+}
+
+// Binds the given stick axis to a movement axis. Trigger axes
+// can't be rebound, and doing so will panic.
+func (self *Bindings) SetStickAxis(axis Axis, binding AxisBinding) {
+	if _, _, isMoveAxis := axis.actions(); !isMoveAxis { panic(axis) }
+	if binding.StdAxis < 0 || binding.StdAxis > ebiten.StandardGamepadAxisMax { panic(binding.StdAxis) }
+	self.axes[axis] = binding
+}
+
+func (self *Bindings) ClearStickAxis(axis Axis) {
+	self.axes[axis] = AxisBinding{ StdAxis: -1 }
+}
+
+func (self *Bindings) DeadZones() DeadZones {
+	return self.deadZones
+}
+
+// Returns an error if the dead zones are not valid (see DeadZones).
+func (self *Bindings) SetDeadZones(deadZones DeadZones) error {
+	err := deadZones.validate()
+	if err != nil { return err }
+	self.deadZones = deadZones
+	return nil
+}
+
+// Returns the actions that have neither keys nor buttons bound.
+func (self *Bindings) Unbound() []Action {
+	var actions []Action
+	for action := Action(0); action < actionEndSentinel; action++ {
+		if len(self.keys[action]) == 0 && len(self.buttons[action]) == 0 {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// --- names ---
+
+// Returns the name of the key as used in the controls config
+// files. This is the same name used by ebitengine.
+func KeyName(key ebiten.Key) string {
+	name := key.String()
+	if name == "" { return "Key#" + strconv.Itoa(int(key)) }
+	return name
+}
+
+// Returns the key with the given name, as returned by KeyName().
+func KeyFromName(name string) (ebiten.Key, bool) {
+	var key ebiten.Key
+	err := key.UnmarshalText([]byte(name))
+	return key, err == nil
+}
+
+// Returns a short uppercase label for the key, to be displayed
+// in key glyphs.
+func KeyLabel(key ebiten.Key) string {
+	switch key {
+	case ebiten.KeyArrowLeft: return "LEFT"
+	case ebiten.KeyArrowRight: return "RIGHT"
+	case ebiten.KeyArrowUp: return "UP"
+	case ebiten.KeyArrowDown: return "DOWN"
+	case ebiten.KeyShiftLeft, ebiten.KeyShiftRight: return "SHIFT"
+	case ebiten.KeyControlLeft, ebiten.KeyControlRight: return "CTRL"
+	case ebiten.KeyAltLeft, ebiten.KeyAltRight: return "ALT"
+	case ebiten.KeyEscape: return "ESC"
+	default:
+		return strings.ToUpper(strings.TrimPrefix(KeyName(key), "Digit"))
+	}
+}
+
+// Returns the name of the button as used in the controls config files.
+func ButtonName(btn ebiten.StandardGamepadButton) string {
+	switch btn {
+	case ebiten.StandardGamepadButtonRightBottom: return "right_bottom"
+	case ebiten.StandardGamepadButtonRightRight: return "right_right"
+	case ebiten.StandardGamepadButtonRightLeft: return "right_left"
+	case ebiten.StandardGamepadButtonRightTop: return "right_top"
+	case ebiten.StandardGamepadButtonFrontTopLeft: return "front_top_left"
+	case ebiten.StandardGamepadButtonFrontTopRight: return "front_top_right"
+	case ebiten.StandardGamepadButtonFrontBottomLeft: return "front_bottom_left"
+	case ebiten.StandardGamepadButtonFrontBottomRight: return "front_bottom_right"
+	case ebiten.StandardGamepadButtonCenterLeft: return "center_left"
+	case ebiten.StandardGamepadButtonCenterRight: return "center_right"
+	case ebiten.StandardGamepadButtonLeftStick: return "left_stick"
+	case ebiten.StandardGamepadButtonRightStick: return "right_stick"
+	case ebiten.StandardGamepadButtonLeftTop: return "left_top"
+	case ebiten.StandardGamepadButtonLeftBottom: return "left_bottom"
+	case ebiten.StandardGamepadButtonLeftLeft: return "left_left"
+	case ebiten.StandardGamepadButtonLeftRight: return "left_right"
+	case ebiten.StandardGamepadButtonCenterCenter: return "center_center"
+	default:
+		return "Button#" + strconv.Itoa(int(btn))
+	}
+}
+
+// Returns the button with the given name, as returned by ButtonName().
+func ButtonFromName(name string) (ebiten.StandardGamepadButton, bool) {
+	for btn := ebiten.StandardGamepadButton(0); btn <= ebiten.StandardGamepadButtonMax; btn++ {
+		if ButtonName(btn) == name { return btn, true }
+	}
+	return -1, false
+}
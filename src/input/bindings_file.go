@@ -0,0 +1,162 @@
+package input
+
+import "io"
+import "bufio"
+import "errors"
+import "strconv"
+import "strings"
+
+// Controls config file format. One statement per line:
+//  - keyboard <action> [<key> ...]
+//  - gamepad <action> [<button> ...]
+//  - axis <move_x|move_y> [<stick_axis> [inverted]]
+//  - deadzones <inner> <outer> <press> <release>
+// Actions and buttons use the names returned by Action.String() and
+// ButtonName(), stick axes use StdAxisName() and keys use the ebitengine
+// key names (see KeyName()). An action listed without keys or buttons
+// is explicitly left unbound.
+// Empty lines and lines starting with '#' are ignored.
+
+// Implements io.WriterTo. Writes the bindings in the controls
+// config file format.
+func (self *Bindings) WriteTo(writer io.Writer) (int64, error) {
+	var builder strings.Builder
+	builder.WriteString("# keyboard <action> [<key> ...]\n")
+	builder.WriteString("# gamepad <action> [<button> ...]\n")
+	builder.WriteString("# axis <move_x|move_y> [<stick_axis> [inverted]]\n")
+	builder.WriteString("# deadzones <inner> <outer> <press> <release>\n\n")
+	for action := Action(0); action < actionEndSentinel; action++ {
+		builder.WriteString("keyboard " + action.String())
+		for _, key := range self.keys[action] {
+			builder.WriteString(" " + KeyName(key))
+		}
+		builder.WriteByte('\n')
+	}
+	builder.WriteByte('\n')
+	for action := Action(0); action < actionEndSentinel; action++ {
+		builder.WriteString("gamepad " + action.String())
+		for _, btn := range self.buttons[action] {
+			builder.WriteString(" " + ButtonName(btn))
+		}
+		builder.WriteByte('\n')
+	}
+	builder.WriteByte('\n')
+	for _, axis := range []Axis{ AxisMoveX, AxisMoveY } {
+		builder.WriteString("axis " + axis.String())
+		binding, isBound := self.StickAxis(axis)
+		if isBound { builder.WriteString(" " + StdAxisName(binding.StdAxis)) }
+		if isBound && binding.Inverted { builder.WriteString(" inverted") }
+		builder.WriteByte('\n')
+	}
+	dz := self.deadZones
+	builder.WriteString("deadzones")
+	for _, value := range []float64{ dz.Inner, dz.Outer, dz.Press, dz.Release } {
+		builder.WriteString(" " + strconv.FormatFloat(value, 'g', -1, 64))
+	}
+	builder.WriteByte('\n')
+
+	n, err := io.WriteString(writer, builder.String())
+	return int64(n), err
+}
+
+// Reads bindings in the controls config file format. Actions that
+// don't appear in the file take the keys and buttons from the given
+// defaults instead, as long as they don't conflict with the bindings
+// in the file. Conflicts within the file are reported as errors.
+func ReadBindings(reader io.Reader, defaults *Bindings) (*Bindings, error) {
+	bindings := newEmptyBindings()
+	bindings.deadZones = defaults.deadZones
+	var listedKeys, listedButtons [NumActions]bool
+	var listedAxes [NumAxes]bool
+
+	scanner := bufio.NewScanner(reader)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum += 1
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") { continue }
+		if len(fields) < 2 {
+			return nil, bindingsLineErr(lineNum, "missing arguments")
+		}
+
+		// non-action statements
+		switch fields[0] {
+		case "axis":
+			axis, found := AxisFromName(fields[1])
+			if !found || (axis != AxisMoveX && axis != AxisMoveY) {
+				return nil, bindingsLineErr(lineNum, "invalid axis '" + fields[1] + "'")
+			}
+			listedAxes[axis] = true
+			if len(fields) == 2 { continue }
+			stdAxis, found := StdAxisFromName(fields[2])
+			if !found { return nil, bindingsLineErr(lineNum, "unknown stick axis '" + fields[2] + "'") }
+			inverted := (len(fields) == 4 && fields[3] == "inverted")
+			if len(fields) > 4 || (len(fields) == 4 && !inverted) {
+				return nil, bindingsLineErr(lineNum, "unexpected axis arguments")
+			}
+			bindings.SetStickAxis(axis, AxisBinding{ StdAxis: stdAxis, Inverted: inverted })
+			continue
+		case "deadzones":
+			if len(fields) != 5 { return nil, bindingsLineErr(lineNum, "deadzones expects 4 values") }
+			var values [4]float64
+			for i, _ := range values {
+				value, err := strconv.ParseFloat(fields[i + 1], 64)
+				if err != nil { return nil, bindingsLineErr(lineNum, "invalid deadzone value '" + fields[i + 1] + "'") }
+				values[i] = value
+			}
+			err := bindings.SetDeadZones(DeadZones{ Inner: values[0], Outer: values[1], Press: values[2], Release: values[3] })
+			if err != nil { return nil, bindingsLineErr(lineNum, err.Error()) }
+			continue
+		}
+
+		// action statements
+		action, found := ActionFromName(fields[1])
+		if !found { return nil, bindingsLineErr(lineNum, "unknown action '" + fields[1] + "'") }
+
+		switch fields[0] {
+		case "keyboard":
+			listedKeys[action] = true
+			for _, name := range fields[2 : ] {
+				key, found := KeyFromName(name)
+				if !found { return nil, bindingsLineErr(lineNum, "unknown key '" + name + "'") }
+				err := bindings.AddKey(action, key)
+				if err != nil { return nil, bindingsLineErr(lineNum, err.Error()) }
+			}
+		case "gamepad":
+			listedButtons[action] = true
+			for _, name := range fields[2 : ] {
+				btn, found := ButtonFromName(name)
+				if !found { return nil, bindingsLineErr(lineNum, "unknown button '" + name + "'") }
+				err := bindings.AddButton(action, btn)
+				if err != nil { return nil, bindingsLineErr(lineNum, err.Error()) }
+			}
+		default:
+			return nil, bindingsLineErr(lineNum, "unexpected statement '" + fields[0] + "'")
+		}
+	}
+	err := scanner.Err()
+	if err != nil { return nil, err }
+
+	// fill actions and axes missing from the file
+	for axis := Axis(0); axis < axisEndSentinel; axis++ {
+		if !listedAxes[axis] { bindings.axes[axis] = defaults.axes[axis] }
+	}
+	for action := Action(0); action < actionEndSentinel; action++ {
+		if !listedKeys[action] {
+			for _, key := range defaults.Keys(action) {
+				_ = bindings.AddKey(action, key) // conflicting defaults are skipped
+			}
+		}
+		if !listedButtons[action] {
+			for _, btn := range defaults.Buttons(action) {
+				_ = bindings.AddButton(action, btn)
+			}
+		}
+	}
+
+	return bindings, nil
+}
+
+func bindingsLineErr(lineNum int, msg string) error {
+	return errors.New("input: controls line " + strconv.Itoa(lineNum) + ": " + strings.TrimPrefix(msg, "input: "))
+}
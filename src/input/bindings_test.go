@@ -0,0 +1,161 @@
+package input
+
+import "bytes"
+import "errors"
+import "reflect"
+import "strings"
+import "testing"
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+func TestBindingConflicts(t *testing.T) {
+	bindings := newEmptyBindings()
+	err := bindings.AddKey(ActionJump, ebiten.KeyK)
+	if err != nil { t.Fatal(err) }
+	err = bindings.AddKey(ActionJump, ebiten.KeyK) // same action, no effect
+	if err != nil { t.Fatal(err) }
+	if len(bindings.Keys(ActionJump)) != 1 { t.Fatalf("got keys %v", bindings.Keys(ActionJump)) }
+
+	err = bindings.AddKey(ActionInteract, ebiten.KeyK)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) { t.Fatalf("expected *ConflictError, got %v", err) }
+	if conflict.Action != ActionInteract || conflict.BoundTo != ActionJump || conflict.Binding != KeyName(ebiten.KeyK) {
+		t.Fatalf("unexpected conflict %+v", *conflict)
+	}
+	if len(bindings.Keys(ActionInteract)) != 0 { t.Fatal("conflicting key was bound anyway") }
+
+	btn := ebiten.StandardGamepadButtonRightBottom
+	err = bindings.AddButton(ActionJump, btn)
+	if err != nil { t.Fatal(err) }
+	err = bindings.AddButton(ActionInteract, btn)
+	if !errors.As(err, &conflict) { t.Fatalf("expected *ConflictError, got %v", err) }
+	if conflict.Action != ActionInteract || conflict.BoundTo != ActionJump || conflict.Binding != ButtonName(btn) {
+		t.Fatalf("unexpected conflict %+v", *conflict)
+	}
+	if len(bindings.Buttons(ActionInteract)) != 0 { t.Fatal("conflicting button was bound anyway") }
+
+	// the presets must be conflict-free
+	for keyboard, _ := range KeyboardPresets {
+		for gamepad, _ := range GamepadPresets {
+			_, err = NewBindingsFromPresets(keyboard, gamepad)
+			if err != nil { t.Errorf("presets %s + %s: %s", keyboard, gamepad, err) }
+		}
+	}
+}
+
+func TestRebind(t *testing.T) {
+	bindings := newEmptyBindings()
+	for _, key := range []ebiten.Key{ ebiten.KeyK, ebiten.KeySpace } {
+		err := bindings.AddKey(ActionJump, key)
+		if err != nil { t.Fatal(err) }
+	}
+	err := bindings.AddKey(ActionInteract, ebiten.KeyI)
+	if err != nil { t.Fatal(err) }
+
+	// taking a key from another action
+	prev, taken := bindings.RebindKey(ActionInteract, ebiten.KeySpace)
+	if !taken || prev != ActionJump { t.Fatalf("RebindKey() = %s, %t", prev, taken) }
+	if !reflect.DeepEqual(bindings.Keys(ActionInteract), []ebiten.Key{ ebiten.KeySpace }) {
+		t.Fatalf("interact keys %v", bindings.Keys(ActionInteract))
+	}
+	if !reflect.DeepEqual(bindings.Keys(ActionJump), []ebiten.Key{ ebiten.KeyK }) {
+		t.Fatalf("jump keys %v", bindings.Keys(ActionJump))
+	}
+
+	// rebinding to the same action doesn't report a previous action
+	_, taken = bindings.RebindKey(ActionInteract, ebiten.KeySpace)
+	if taken { t.Fatal("key reported as taken from its own action") }
+
+	// buttons
+	btn := ebiten.StandardGamepadButtonRightBottom
+	err = bindings.AddButton(ActionJump, btn)
+	if err != nil { t.Fatal(err) }
+	prev, taken = bindings.RebindButton(ActionInteract, btn)
+	if !taken || prev != ActionJump { t.Fatalf("RebindButton() = %s, %t", prev, taken) }
+	if len(bindings.Buttons(ActionJump)) != 0 { t.Fatalf("jump buttons %v", bindings.Buttons(ActionJump)) }
+	unbound := bindings.Unbound()
+	if len(unbound) != NumActions - 2 { t.Fatalf("%d unbound actions", len(unbound)) }
+}
+
+func TestBindingsFileRoundTrip(t *testing.T) {
+	defaults, err := NewBindingsFromPresets(DefaultKeyboardPreset, DefaultGamepadPreset)
+	if err != nil { t.Fatal(err) }
+	bindings := defaults.Clone()
+	_, _ = bindings.RebindKey(ActionJump, ebiten.KeySpace)
+	err = bindings.AddKey(ActionJump, ebiten.KeyK)
+	if err != nil { t.Fatal(err) }
+	bindings.RemoveButton(bindings.Buttons(ActionInteract)[0]) // leave interact without buttons
+	bindings.SetStickAxis(AxisMoveY, AxisBinding{ StdAxis: ebiten.StandardGamepadAxisRightStickVertical, Inverted: true })
+	err = bindings.SetDeadZones(DeadZones{ Inner: 0.15, Outer: 0.1, Press: 0.6, Release: 0.4 })
+	if err != nil { t.Fatal(err) }
+
+	var buffer bytes.Buffer
+	_, err = bindings.WriteTo(&buffer)
+	if err != nil { t.Fatal(err) }
+	loaded, err := ReadBindings(bytes.NewReader(buffer.Bytes()), defaults)
+	if err != nil { t.Fatal(err) }
+	if !sameBindings(loaded, bindings) {
+		t.Fatalf("round trip mismatch\n got  %+v\n want %+v", *loaded, *bindings)
+	}
+
+	// missing actions are filled from the defaults, except for
+	// the keys already bound in the file
+	config := "keyboard jump K\nkeyboard interact\naxis move_x\n"
+	loaded, err = ReadBindings(strings.NewReader(config), defaults)
+	if err != nil { t.Fatal(err) }
+	if len(loaded.Keys(ActionInteract)) != 0 { t.Fatalf("interact keys %v, expected none", loaded.Keys(ActionInteract)) }
+	if !reflect.DeepEqual(loaded.Keys(ActionJump), []ebiten.Key{ ebiten.KeyK }) { t.Fatalf("jump keys %v", loaded.Keys(ActionJump)) }
+	if !reflect.DeepEqual(loaded.Keys(ActionMoveLeft), defaults.Keys(ActionMoveLeft)) { t.Fatal("move_left keys not filled from the defaults") }
+	if !reflect.DeepEqual(loaded.Buttons(ActionJump), defaults.Buttons(ActionJump)) { t.Fatal("jump buttons not filled from the defaults") }
+	if _, bound := loaded.StickAxis(AxisMoveX); bound { t.Fatal("move_x explicitly unbound in the file") }
+	if loaded.axes[AxisMoveY] != defaults.axes[AxisMoveY] { t.Fatal("move_y not filled from the defaults") }
+	if loaded.DeadZones() != defaults.DeadZones() { t.Fatal("dead zones not filled from the defaults") }
+
+	config = "keyboard out_reverse " + KeyName(defaults.Keys(ActionJump)[0]) + "\n"
+	loaded, err = ReadBindings(strings.NewReader(config), defaults)
+	if err != nil { t.Fatal(err) }
+	if len(loaded.Keys(ActionJump)) != 0 { t.Fatalf("conflicting default jump keys %v not skipped", loaded.Keys(ActionJump)) }
+}
+
+func TestBindingsFileErrors(t *testing.T) {
+	defaults, err := NewBindingsFromPresets(DefaultKeyboardPreset, DefaultGamepadPreset)
+	if err != nil { t.Fatal(err) }
+	tests := []struct {
+		config string
+		errText string
+	}{
+		{ "keyboard jump K\nkeyboard interact K", "line 2: K can't be bound to interact, already bound to jump" },
+		{ "gamepad jump " + ButtonName(0) + "\ngamepad up " + ButtonName(0), "line 2: " },
+		{ "keyboard jmp K", "line 1: unknown action 'jmp'" },
+		{ "keyboard jump NotAKey", "line 1: unknown key 'NotAKey'" },
+		{ "gamepad jump a_button", "line 1: unknown button 'a_button'" },
+		{ "axis move_z", "line 1: invalid axis 'move_z'" },
+		{ "axis move_x left_stick_x flipped", "line 1: unexpected axis arguments" },
+		{ "deadzones 0.1 0.9", "line 1: deadzones expects 4 values" },
+		{ "deadzones 0.2 0.05 0.3 0.5", "line 1: axis release threshold" },
+		{ "deadzones 0.6 0.4 0.5 0.3", "line 1: dead zones must be positive" },
+		{ "# comment\n\nkeyboard", "line 3: missing arguments" },
+		{ "mouse jump left", "line 1: unexpected statement 'mouse'" },
+	}
+	for _, test := range tests {
+		_, err := ReadBindings(strings.NewReader(test.config), defaults)
+		if err == nil || !strings.Contains(err.Error(), test.errText) {
+			t.Errorf("config %q: expected error containing %q, got %v", test.config, test.errText, err)
+		}
+	}
+}
+
+func sameBindings(a, b *Bindings) bool {
+	if a.axes != b.axes || a.deadZones != b.deadZones { return false }
+	for action := Action(0); action < actionEndSentinel; action++ {
+		if len(a.keys[action]) != len(b.keys[action]) { return false }
+		if len(a.buttons[action]) != len(b.buttons[action]) { return false }
+		for i, key := range a.keys[action] {
+			if key != b.keys[action][i] { return false }
+		}
+		for i, btn := range a.buttons[action] {
+			if btn != b.buttons[action][i] { return false }
+		}
+	}
+	return true
+}
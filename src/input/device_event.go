@@ -0,0 +1,49 @@
+package input
+
+import "strconv"
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+type Device uint8
+const (
+	DeviceKeyboard Device = iota
+	DeviceGamepad
+)
+
+func (self Device) String() string {
+	switch self {
+	case DeviceKeyboard: return "keyboard"
+	case DeviceGamepad: return "gamepad"
+	default:
+		return "Device#" + strconv.Itoa(int(self))
+	}
+}
+
+type DeviceEventType uint8
+const (
+	EventGamepadConnected DeviceEventType = iota
+	EventGamepadDisconnected
+	EventActiveDeviceChanged // Device is the new active device
+)
+
+func (self DeviceEventType) String() string {
+	switch self {
+	case EventGamepadConnected: return "gamepad_connected"
+	case EventGamepadDisconnected: return "gamepad_disconnected"
+	case EventActiveDeviceChanged: return "active_device_changed"
+	default:
+		return "DeviceEventType#" + strconv.Itoa(int(self))
+	}
+}
+
+// Events sent by DeviceSource. See DeviceSource.OnEvent().
+type DeviceEvent struct {
+	Type DeviceEventType
+	Device Device
+	GamepadID ebiten.GamepadID // only relevant for gamepad events
+
+	// only set for EventGamepadConnected
+	GamepadName string
+	GamepadGUID string
+	Supported bool // false if no standard layout nor gamepad db mapping
+}
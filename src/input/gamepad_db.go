@@ -0,0 +1,280 @@
+package input
+
+import "io"
+import "io/fs"
+import "bufio"
+import "errors"
+import "strconv"
+import "runtime"
+import "strings"
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// Database of raw mappings for gamepads that don't have a standard
+// layout available (see ebiten.IsStandardGamepadLayoutAvailable()).
+// Gamepads are identified by their SDL GUID (ebiten.GamepadSDLID()).
+//
+// The database uses the SDL_GameControllerDB format, so mappings can
+// be copied directly from github.com/gabomdq/SDL_GameControllerDB or
+// generated with SDL tools. One gamepad per line:
+//  <guid>,<name>,<sdl_name>:<raw>,...,platform:<platform>,
+// Raw inputs can be buttons (b<index>), axes (a<index>, with a '~'
+// suffix for inverted axes, or a '+'/'-' prefix for half axes) and
+// hats (h<hat>.<mask>). Standard buttons can also be mapped to full
+// raw axes (e.g. analog triggers), in which case the axis range
+// [-1, 1] is converted to [0, 1]. Lines for other platforms, empty
+// lines and lines starting with '#' are ignored, and so are unknown
+// SDL names (e.g. misc1 or paddle1).
+type GamepadDB struct {
+	mappings map[string]*RawGamepadMapping
+}
+
+// Raw mapping for a specific gamepad model.
+type RawGamepadMapping struct {
+	buttons [ebiten.StandardGamepadButtonMax + 1]rawInput
+	axes [ebiten.StandardGamepadAxisMax + 1]rawInput
+	numHats int
+}
+
+type rawInputKind uint8
+const (
+	rawNone rawInputKind = iota
+	rawButton
+	rawAxis
+	rawHat
+)
+
+type rawInput struct {
+	kind rawInputKind
+	index int16 // button, axis or hat index
+	inverted bool // axes only
+	half int8 // axes only: 0 for full axes, +1 or -1 for half axes
+	hatDir int8 // hats only: 0 (up), 1 (right), 2 (down) or 3 (left)
+}
+
+// SDL game controller names for the standard buttons and axes.
+var sdlStdButtons = map[string]ebiten.StandardGamepadButton{
+	"a": ebiten.StandardGamepadButtonRightBottom,
+	"b": ebiten.StandardGamepadButtonRightRight,
+	"x": ebiten.StandardGamepadButtonRightLeft,
+	"y": ebiten.StandardGamepadButtonRightTop,
+	"leftshoulder": ebiten.StandardGamepadButtonFrontTopLeft,
+	"rightshoulder": ebiten.StandardGamepadButtonFrontTopRight,
+	"lefttrigger": ebiten.StandardGamepadButtonFrontBottomLeft,
+	"righttrigger": ebiten.StandardGamepadButtonFrontBottomRight,
+	"back": ebiten.StandardGamepadButtonCenterLeft,
+	"start": ebiten.StandardGamepadButtonCenterRight,
+	"guide": ebiten.StandardGamepadButtonCenterCenter,
+	"leftstick": ebiten.StandardGamepadButtonLeftStick,
+	"rightstick": ebiten.StandardGamepadButtonRightStick,
+	"dpup": ebiten.StandardGamepadButtonLeftTop,
+	"dpdown": ebiten.StandardGamepadButtonLeftBottom,
+	"dpleft": ebiten.StandardGamepadButtonLeftLeft,
+	"dpright": ebiten.StandardGamepadButtonLeftRight,
+}
+
+var sdlStdAxes = map[string]ebiten.StandardGamepadAxis{
+	"leftx": ebiten.StandardGamepadAxisLeftStickHorizontal,
+	"lefty": ebiten.StandardGamepadAxisLeftStickVertical,
+	"rightx": ebiten.StandardGamepadAxisRightStickHorizontal,
+	"righty": ebiten.StandardGamepadAxisRightStickVertical,
+}
+
+func NewGamepadDB() *GamepadDB {
+	return &GamepadDB{ mappings: make(map[string]*RawGamepadMapping) }
+}
+
+func LoadGamepadDBFile(filesys fs.FS, path string) (*GamepadDB, error) {
+	file, err := filesys.Open(path)
+	if err != nil { return nil, err }
+	defer file.Close()
+	db, err := LoadGamepadDB(file)
+	if err != nil { return nil, errors.New(path + ": " + err.Error()) }
+	return db, nil
+}
+
+// Loads the mappings for the current platform.
+func LoadGamepadDB(reader io.Reader) (*GamepadDB, error) {
+	return loadGamepadDB(reader, sdlPlatform())
+}
+
+func loadGamepadDB(reader io.Reader, platform string) (*GamepadDB, error) {
+	db := NewGamepadDB()
+	scanner := bufio.NewScanner(reader)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum += 1
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") { continue }
+
+		fields := strings.Split(strings.TrimSuffix(line, ","), ",")
+		if len(fields) < 2 { return nil, gamepadDBLineErr(lineNum, "expected '<guid>,<name>,<mappings>...'") }
+		guid := strings.ToLower(fields[0])
+		if len(guid) != 32 { return nil, gamepadDBLineErr(lineNum, "invalid guid '" + fields[0] + "'") }
+		mapping := &RawGamepadMapping{}
+		linePlatform := ""
+		for _, field := range fields[2 : ] {
+			if value, found := strings.CutPrefix(field, "platform:"); found {
+				linePlatform = value
+				continue
+			}
+			err := mapping.parseEntry(field)
+			if err != nil { return nil, gamepadDBLineErr(lineNum, err.Error()) }
+		}
+		if linePlatform != "" && linePlatform != platform { continue }
+
+		if _, found := db.mappings[guid]; found {
+			return nil, gamepadDBLineErr(lineNum, "duplicated guid " + guid)
+		}
+		db.mappings[guid] = mapping
+	}
+	err := scanner.Err()
+	if err != nil { return nil, err }
+	return db, nil
+}
+
+func gamepadDBLineErr(lineNum int, msg string) error {
+	return errors.New("input: gamepad db line " + strconv.Itoa(lineNum) + ": " + msg)
+}
+
+// Returns the SDL platform name for the current OS.
+func sdlPlatform() string {
+	switch runtime.GOOS {
+	case "windows": return "Windows"
+	case "darwin": return "Mac OS X"
+	case "linux": return "Linux"
+	case "android": return "Android"
+	case "ios": return "iOS"
+	default:
+		return runtime.GOOS
+	}
+}
+
+// Returns the number of gamepads in the database.
+func (self *GamepadDB) Len() int {
+	return len(self.mappings)
+}
+
+func (self *GamepadDB) Lookup(guid string) (*RawGamepadMapping, bool) {
+	mapping, found := self.mappings[strings.ToLower(guid)]
+	return mapping, found
+}
+
+func (self *RawGamepadMapping) parseEntry(entry string) error {
+	sdlName, rawName, found := strings.Cut(entry, ":")
+	if !found { return errors.New("invalid entry '" + entry + "'") }
+	btn, isButton := sdlStdButtons[sdlName]
+	axis, isAxis := sdlStdAxes[sdlName]
+	if !isButton && !isAxis { return nil } // no standard equivalent
+
+	raw, err := parseRawInput(rawName)
+	if err != nil { return err }
+	switch raw.kind {
+	case rawHat:
+		if int(raw.index) + 1 > self.numHats { self.numHats = int(raw.index) + 1 }
+	case rawAxis:
+		if raw.half != 0 && raw.inverted { return errors.New("half axes can't be inverted") }
+	}
+
+	if isButton {
+		if raw.inverted { return errors.New("standard button '" + sdlName + "' can't map to an inverted axis") }
+		self.buttons[btn] = raw
+	} else {
+		if raw.kind != rawAxis || raw.half != 0 {
+			return errors.New("standard axis '" + sdlName + "' must map to a full raw axis")
+		}
+		self.axes[axis] = raw
+	}
+	return nil
+}
+
+func parseRawInput(rawName string) (rawInput, error) {
+	var raw rawInput
+	name := rawName
+	if strings.HasPrefix(name, "+") || strings.HasPrefix(name, "-") {
+		raw.half = +1
+		if name[0] == '-' { raw.half = -1 }
+		name = name[1 : ]
+	}
+	name, raw.inverted = strings.CutSuffix(name, "~")
+	if len(name) < 2 { return raw, errors.New("invalid raw input '" + rawName + "'") }
+
+	switch name[0] {
+	case 'b': raw.kind = rawButton
+	case 'a': raw.kind = rawAxis
+	case 'h': raw.kind = rawHat
+	default:
+		return raw, errors.New("invalid raw input '" + rawName + "'")
+	}
+	if raw.kind != rawAxis && (raw.half != 0 || raw.inverted) {
+		return raw, errors.New("invalid raw input '" + rawName + "'")
+	}
+
+	indexStr := name[1 : ]
+	if raw.kind == rawHat {
+		var maskStr string
+		indexStr, maskStr, _ = strings.Cut(indexStr, ".")
+		switch maskStr {
+		case "1": raw.hatDir = 0
+		case "2": raw.hatDir = 1
+		case "4": raw.hatDir = 2
+		case "8": raw.hatDir = 3
+		default:
+			return raw, errors.New("invalid hat mask in '" + rawName + "'")
+		}
+	}
+	index, err := strconv.ParseUint(indexStr, 10, 15)
+	if err != nil { return raw, errors.New("invalid raw input '" + rawName + "'") }
+	raw.index = int16(index)
+	return raw, nil
+}
+
+func (self *RawGamepadMapping) ButtonPressed(id ebiten.GamepadID, btn ebiten.StandardGamepadButton) bool {
+	raw := self.buttons[btn]
+	switch raw.kind {
+	case rawButton, rawHat:
+		return ebiten.IsGamepadButtonPressed(id, self.rawButton(id, raw))
+	case rawAxis:
+		return self.ButtonValue(id, btn) > 0.5
+	default:
+		return false
+	}
+}
+
+// Returns a value between 0 and 1.
+func (self *RawGamepadMapping) ButtonValue(id ebiten.GamepadID, btn ebiten.StandardGamepadButton) float64 {
+	raw := self.buttons[btn]
+	switch raw.kind {
+	case rawButton, rawHat:
+		if ebiten.IsGamepadButtonPressed(id, self.rawButton(id, raw)) { return 1 }
+		return 0
+	case rawAxis:
+		value := ebiten.GamepadAxisValue(id, int(raw.index))
+		if raw.half == 0 { return (value + 1)/2 }
+		value *= float64(raw.half)
+		if value < 0 { return 0 }
+		if value > 1 { return 1 }
+		return value
+	default:
+		return 0
+	}
+}
+
+// Returns a value between -1 and 1.
+func (self *RawGamepadMapping) AxisValue(id ebiten.GamepadID, axis ebiten.StandardGamepadAxis) float64 {
+	raw := self.axes[axis]
+	if raw.kind != rawAxis { return 0 }
+	value := ebiten.GamepadAxisValue(id, int(raw.index))
+	if raw.inverted { return -value }
+	return value
+}
+
+// Ebitengine reports hats as 4 extra buttons each, after the regular
+// buttons. The number of regular buttons isn't exposed, so this assumes
+// that the gamepad has as many hats as the mapping uses (gamepads
+// rarely have more than one hat, if any).
+func (self *RawGamepadMapping) rawButton(id ebiten.GamepadID, raw rawInput) ebiten.GamepadButton {
+	if raw.kind != rawHat { return ebiten.GamepadButton(raw.index) }
+	numButtons := ebiten.GamepadButtonCount(id) - self.numHats*4
+	return ebiten.GamepadButton(numButtons + int(raw.index)*4 + int(raw.hatDir))
+}
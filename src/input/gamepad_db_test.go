@@ -0,0 +1,73 @@
+package input
+
+import "os"
+import "strings"
+import "testing"
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+func TestGamepadDBParse(t *testing.T) {
+	const data = `# comment
+03000000c82d00000160000000000000,Test Pad,a:b1,b:b0,dpdown:h0.4,dpleft:h0.8,lefttrigger:a4,righttrigger:+a5,leftx:a0,lefty:a1~,misc1:b15,platform:Windows,
+03000000C82D00000160000000000000,Test Pad,a:b3,platform:Linux,
+030000000000000000000000000000ff,Any Platform,dpup:-a1,
+`
+	db, err := loadGamepadDB(strings.NewReader(data), "Windows")
+	if err != nil { t.Fatal(err) }
+	if db.Len() != 2 { t.Fatalf("expected 2 mappings, got %d", db.Len()) }
+
+	mapping, found := db.Lookup("03000000C82D00000160000000000000")
+	if !found { t.Fatal("mapping not found") }
+	expectRaw := func(name string, got, expected rawInput) {
+		if got != expected { t.Errorf("%s: got %+v, expected %+v", name, got, expected) }
+	}
+	expectRaw("a", mapping.buttons[ebiten.StandardGamepadButtonRightBottom], rawInput{ kind: rawButton, index: 1 })
+	expectRaw("dpdown", mapping.buttons[ebiten.StandardGamepadButtonLeftBottom], rawInput{ kind: rawHat, hatDir: 2 })
+	expectRaw("dpleft", mapping.buttons[ebiten.StandardGamepadButtonLeftLeft], rawInput{ kind: rawHat, hatDir: 3 })
+	expectRaw("lefttrigger", mapping.buttons[ebiten.StandardGamepadButtonFrontBottomLeft], rawInput{ kind: rawAxis, index: 4 })
+	expectRaw("righttrigger", mapping.buttons[ebiten.StandardGamepadButtonFrontBottomRight], rawInput{ kind: rawAxis, index: 5, half: +1 })
+	expectRaw("lefty", mapping.axes[ebiten.StandardGamepadAxisLeftStickVertical], rawInput{ kind: rawAxis, index: 1, inverted: true })
+	expectRaw("x", mapping.buttons[ebiten.StandardGamepadButtonRightLeft], rawInput{})
+	if mapping.numHats != 1 { t.Errorf("expected 1 hat, got %d", mapping.numHats) }
+
+	mapping, found = db.Lookup("030000000000000000000000000000ff")
+	if !found { t.Fatal("mapping without platform not found") }
+	expectRaw("dpup", mapping.buttons[ebiten.StandardGamepadButtonLeftTop], rawInput{ kind: rawAxis, index: 1, half: -1 })
+
+	db, err = loadGamepadDB(strings.NewReader(data), "Linux")
+	if err != nil { t.Fatal(err) }
+	mapping, _ = db.Lookup("03000000c82d00000160000000000000")
+	expectRaw("a (linux)", mapping.buttons[ebiten.StandardGamepadButtonRightBottom], rawInput{ kind: rawButton, index: 3 })
+}
+
+func TestGamepadDBErrors(t *testing.T) {
+	const guid = "03000000c82d00000160000000000000"
+	lines := []string{
+		"badguid,Pad,a:b0,",
+		guid,
+		guid + ",Pad,a:c0,",
+		guid + ",Pad,a:b,",
+		guid + ",Pad,a:-b0,",
+		guid + ",Pad,a:a0~,",
+		guid + ",Pad,dpup:h0.3,",
+		guid + ",Pad,leftx:b0,",
+		guid + ",Pad,leftx:+a0,",
+		guid + ",Pad,a:b0,\n" + guid + ",Pad,a:b1,",
+	}
+	for _, line := range lines {
+		_, err := loadGamepadDB(strings.NewReader(line), "Windows")
+		if err == nil { t.Errorf("expected error for '%s'", line) }
+	}
+}
+
+// The bundled database must load on every platform.
+func TestGamepadDBAsset(t *testing.T) {
+	for _, platform := range []string{ "Windows", "Mac OS X", "Linux" } {
+		file, err := os.Open("../../assets/input/gamepads.txt")
+		if err != nil { t.Fatal(err) }
+		db, err := loadGamepadDB(file, platform)
+		file.Close()
+		if err != nil { t.Fatalf("%s: %s", platform, err) }
+		if db.Len() == 0 { t.Errorf("%s: no gamepad mappings", platform) }
+	}
+}
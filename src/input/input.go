@@ -6,75 +6,79 @@ import "github.com/hajimehoshi/ebiten/v2"
 
 type Input struct {
 	pressedTicks [NumActions]int32
-	keyboardMappings [NumActions]ebiten.Key
-	gamepadMappings [NumActions]ebiten.StandardGamepadButton
-	gamepadIds []ebiten.GamepadID
+	analogValues [NumAxes]float64
+	source Source
 	blockedTicksLeft uint64
+
+	// input buffering (see ConsumeBuffered())
+	sinceTrigger [NumActions]uint32
+	consumed [NumActions]bool
+	bufferWindows [NumActions]uint32
 }
 
+// Buffer window used for all actions unless changed
+// with Input.SetBufferWindow(). Equivalent to Trigger().
+const DefaultBufferWindow = 1
+
 func NewInput(keyboardMappings map[Action]ebiten.Key, gamepadMappings map[Action]ebiten.StandardGamepadButton) *Input {
-	if len(keyboardMappings) != NumActions {
-		panic("incorrect number of keyboard mappings given")
-	}
-	if len(gamepadMappings) != NumActions {
-		panic("incorrect number of gamepad mappings given")
-	}
-	
-	var kbMappings [NumActions]ebiten.Key
-	var gpMappings [NumActions]ebiten.StandardGamepadButton
-	for action, key := range keyboardMappings { kbMappings[action] = key }
-	for action, btn := range gamepadMappings  { gpMappings[action] = btn }
-
-	return &Input{
-		keyboardMappings: kbMappings,
-		gamepadMappings: gpMappings,
+	return NewInputFromSource(NewDeviceSource(keyboardMappings, gamepadMappings))
+}
+
+func NewInputFromSource(source Source) *Input {
+	input := &Input{ source: source }
+	for i := 0; i < NumActions; i++ {
+		input.sinceTrigger[i] = math.MaxUint32
+		input.consumed[i] = true
+		input.bufferWindows[i] = DefaultBufferWindow
 	}
+	return input
+}
+
+func (self *Input) Source() Source {
+	return self.source
+}
+
+// Changes the input source. Press durations are kept, so switching
+// sources while actions are pressed can lead to unexpected triggers.
+func (self *Input) SetSource(source Source) {
+	self.source = source
 }
 
 func (self *Input) Update() error {
-	// detect gamepad changes
-	hasActiveGamepad := (len(self.gamepadIds) != 0)
-	var currGamepadID ebiten.GamepadID
-	if hasActiveGamepad {
-		currGamepadID = self.gamepadIds[len(self.gamepadIds) - 1]
-	}
-	self.gamepadIds = self.gamepadIds[ : 0]
-	self.gamepadIds = ebiten.AppendGamepadIDs(self.gamepadIds)
-	newGpCount := len(self.gamepadIds)
-	gamepadChanged := 
-		(hasActiveGamepad && newGpCount == 0) ||
-		(!hasActiveGamepad && newGpCount > 0) ||
-		(newGpCount > 0 && self.gamepadIds[newGpCount - 1] != currGamepadID)
-	if gamepadChanged {
-		// TODO: mostly store so we can notify the player.
-		//       I don't think I need to do much here yet
+	self.source.Update()
+
+	// advance buffers (they keep expiring even while blocked)
+	for i := 0; i < NumActions; i++ {
+		if self.sinceTrigger[i] < math.MaxUint32 { self.sinceTrigger[i] += 1 }
 	}
 	
 	// consider input blocking
 	if self.blockedTicksLeft > 0 {
 		self.blockedTicksLeft -= 1
+		self.analogValues = [NumAxes]float64{}
 		return nil
 	}
 
-	// update input
-	if newGpCount > 0 {
-		currGamepadID = self.gamepadIds[newGpCount - 1]
-		for action, ticks := range self.pressedTicks {
-			hasKbPress := ebiten.IsKeyPressed(self.keyboardMappings[action])
-			hasGpPress := ebiten.IsStandardGamepadButtonPressed(currGamepadID, self.gamepadMappings[action])
-			if hasKbPress || hasGpPress {
-				if ticks != -1 { self.pressedTicks[action] += 1 }
-			} else {
-				self.pressedTicks[action] = 0
-			}
+	// update analog values
+	axisSource, isAxisSource := self.source.(AxisSource)
+	for axis := Axis(0); axis < axisEndSentinel; axis++ {
+		if isAxisSource {
+			self.analogValues[axis] = axisSource.AxisValue(axis)
+		} else {
+			self.analogValues[axis] = 0
 		}
-	} else { // no gamepad, check only keyboard
-		for action, ticks := range self.pressedTicks {
-			if ebiten.IsKeyPressed(self.keyboardMappings[action]) {
-				if ticks != -1 { self.pressedTicks[action] += 1 }
-			} else {
-				self.pressedTicks[action] = 0
+	}
+
+	// update input
+	for action, ticks := range self.pressedTicks {
+		if self.source.ActionPressed(Action(action)) {
+			if ticks != -1 { self.pressedTicks[action] += 1 }
+			if self.pressedTicks[action] == 1 {
+				self.sinceTrigger[action] = 0
+				self.consumed[action] = false
 			}
+		} else {
+			self.pressedTicks[action] = 0
 		}
 	}
 
@@ -91,16 +95,76 @@ func (self *Input) PressedTicks(action Action) int32 {
 	return ticks
 }
 
+// Returns the value of the given axis, in [-1, 1] for movement axes
+// and [0, 1] for triggers. When there's no analog input for a movement
+// axis, the value is derived from its direction actions instead, so
+// keys and d-pads report -1 or +1. Like with Pressed(), directions
+// that are blocked or unwound report 0.
+func (self *Input) Axis(axis Axis) float64 {
+	if self.IsBlocked() { return 0 }
+	value := self.analogValues[axis]
+	negAction, posAction, isMoveAxis := axis.actions()
+	if !isMoveAxis { return value }
+
+	if value < 0 && self.pressedTicks[negAction] == -1 { return 0 }
+	if value > 0 && self.pressedTicks[posAction] == -1 { return 0 }
+	if value != 0 { return value }
+	if self.Pressed(posAction) { value += 1 }
+	if self.Pressed(negAction) { value -= 1 }
+	return value
+}
+
 func (self *Input) Trigger(action Action) bool {
 	return self.pressedTicks[action] == 1
 }
 
+// Sets the default buffer window for the given action, in ticks.
+// A window of 0 disables buffering, like a window of 1. See
+// ConsumeBuffered().
+func (self *Input) SetBufferWindow(action Action, ticks uint32) {
+	self.bufferWindows[action] = ticks
+}
+
+func (self *Input) BufferWindow(action Action) uint32 {
+	return self.bufferWindows[action]
+}
+
+// Returns whether the action was triggered within the last 'window'
+// ticks (including the current one) and the press hasn't been consumed
+// yet. A window of 1 is equivalent to Trigger(), and a window of 0
+// means using the action's default window (see SetBufferWindow()).
+func (self *Input) Buffered(action Action, window uint32) bool {
+	if window == 0 { window = self.bufferWindows[action] }
+	if window == 0 { window = 1 } // buffering disabled
+	return !self.consumed[action] && self.sinceTrigger[action] < window
+}
+
+// Like Buffered(), but consumes the press if it's buffered, so it can't
+// be used again. Typical usage is buffering jumps pressed slightly
+// before landing, which should only be consumed if the jump happens:
+//   if canJump && ctx.Input.ConsumeBuffered(input.ActionJump, 8) { ... }
+// Consuming doesn't affect Pressed() nor Trigger().
+func (self *Input) ConsumeBuffered(action Action, window uint32) bool {
+	if !self.Buffered(action, window) { return false }
+	self.consumed[action] = true
+	return true
+}
+
+// Discards any buffered presses.
+func (self *Input) ClearBuffers() {
+	for i := 0; i < NumActions; i++ {
+		self.consumed[i] = true
+	}
+}
+
 // Makes all input unpressed and keeps it locked until the
-// currently pressed actions are unpressed.
+// currently pressed actions are unpressed. Buffered presses
+// are discarded too.
 func (self *Input) Unwind() {
 	for i := 0; i < NumActions; i++ {
 		self.pressedTicks[i] = -1
 	}
+	self.ClearBuffers()
 }
 
 func (self *Input) SetBlocked(blocked bool) {
@@ -110,6 +174,7 @@ func (self *Input) SetBlocked(blocked bool) {
 			for i := 0; i < NumActions; i++ {
 				self.pressedTicks[i] = 0
 			}
+			self.ClearBuffers()
 		}
 		self.blockedTicksLeft = math.MaxUint64
 	} else {
@@ -0,0 +1,30 @@
+package input
+
+import "testing"
+
+func TestBufferWindows(t *testing.T) {
+	jump := ActionSet(0).With(ActionJump)
+	script := NewScriptedSource()
+	script.Hold(jump, 1)
+	script.Wait(5)
+	input := NewInputFromSource(script)
+	input.SetBufferWindow(ActionJump, 4)
+	input.SetBufferWindow(ActionInteract, 0) // disabled, must not panic
+
+	var buffered, bufferedDefault []bool
+	for i := 0; i < 6; i++ {
+		err := input.Update()
+		if err != nil { t.Fatal(err) }
+		buffered = append(buffered, input.Buffered(ActionJump, 0))
+		input.SetBufferWindow(ActionJump, 0)
+		bufferedDefault = append(bufferedDefault, input.Buffered(ActionJump, 0))
+		input.SetBufferWindow(ActionJump, 4)
+	}
+	expected := []bool{ true, true, true, true, false, false }
+	expectedDisabled := []bool{ true, false, false, false, false, false }
+	for i, _ := range expected {
+		if buffered[i] != expected[i] { t.Fatalf("tick %d: Buffered() = %t with a window of 4", i, buffered[i]) }
+		if bufferedDefault[i] != expectedDisabled[i] { t.Fatalf("tick %d: Buffered() = %t with buffering disabled", i, bufferedDefault[i]) }
+	}
+	if input.BufferWindow(ActionInteract) != 0 { t.Fatal("expected the disabled window to be kept as 0") }
+}
@@ -0,0 +1,41 @@
+package input
+
+var _ Source = (*RecordingSource)(nil)
+
+// Source that wraps another source and records the actions
+// pressed on each tick. See also Replay. Analog values can't be
+// recorded, so the wrapped source's axes are hidden and Input.Axis()
+// falls back to digital values, exactly as when replaying.
+type RecordingSource struct {
+	source Source
+	current ActionSet
+	ticks []ActionSet
+}
+
+func NewRecordingSource(source Source) *RecordingSource {
+	return &RecordingSource{
+		source: source,
+		ticks: make([]ActionSet, 0, 60*60),
+	}
+}
+
+// Returns the recorded action sets, one per tick. The
+// returned slice must not be modified.
+func (self *RecordingSource) Ticks() []ActionSet {
+	return self.ticks
+}
+
+func (self *RecordingSource) Update() {
+	self.source.Update()
+	self.current = 0
+	for action := Action(0); action < actionEndSentinel; action++ {
+		if self.source.ActionPressed(action) {
+			self.current = self.current.With(action)
+		}
+	}
+	self.ticks = append(self.ticks, self.current)
+}
+
+func (self *RecordingSource) ActionPressed(action Action) bool {
+	return self.current.Has(action)
+}
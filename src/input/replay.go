@@ -0,0 +1,114 @@
+package input
+
+import "io"
+import "errors"
+import "hash/crc32"
+import "encoding/binary"
+
+import "github.com/tinne26/transition/src/game/level/lvlkey"
+
+// Replay file format (little endian):
+//  - [4]byte signature ("TRRP")
+//  - uint8 format version
+//  - uint8 starting entry key
+//  - int64 rng seed
+//  - uint32 initial state length (S), followed by S bytes
+//  - uint32 number of ticks
+//  - run-length encoded action sets: uvarint run length
+//    followed by uvarint action set, until all ticks are covered
+//  - uint32 CRC-32 (IEEE) of all the previous bytes
+//
+// Action sets tend to stay the same for many ticks in a row, so
+// even long sessions only take a few kilobytes.
+const replayVersion uint8 = 1
+const replaySignature = "TRRP"
+
+var ErrInvalidReplay = errors.New("input: invalid replay data")
+
+// A recorded session. The initial state is stored as an opaque blob
+// (see state.State.WriteTo()) to avoid depending on the game packages.
+type Replay struct {
+	EntryKey lvlkey.EntryKey
+	Seed int64
+	State []byte
+	Ticks []ActionSet
+}
+
+// Implements io.WriterTo.
+func (self *Replay) WriteTo(writer io.Writer) (int64, error) {
+	data := make([]byte, 0, 32 + len(self.State) + len(self.Ticks)/8)
+	data  = append(data, replaySignature...)
+	data  = append(data, replayVersion, uint8(self.EntryKey))
+	data  = binary.LittleEndian.AppendUint64(data, uint64(self.Seed))
+	data  = binary.LittleEndian.AppendUint32(data, uint32(len(self.State)))
+	data  = append(data, self.State...)
+	data  = binary.LittleEndian.AppendUint32(data, uint32(len(self.Ticks)))
+	for i := 0; i < len(self.Ticks); {
+		run := 1
+		for i + run < len(self.Ticks) && self.Ticks[i + run] == self.Ticks[i] { run += 1 }
+		data = binary.AppendUvarint(data, uint64(run))
+		data = binary.AppendUvarint(data, uint64(self.Ticks[i]))
+		i += run
+	}
+	data = binary.LittleEndian.AppendUint32(data, crc32.ChecksumIEEE(data))
+
+	n, err := writer.Write(data)
+	return int64(n), err
+}
+
+// Implements io.ReaderFrom. The whole reader is consumed. If an error
+// is returned, the replay is left unmodified. Malformed data results
+// in ErrInvalidReplay.
+func (self *Replay) ReadFrom(reader io.Reader) (int64, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil { return int64(len(data)), err }
+	total := int64(len(data))
+
+	// verify signature and checksum
+	if len(data) < 22 + 4 { return total, ErrInvalidReplay }
+	if string(data[0 : 4]) != replaySignature { return total, ErrInvalidReplay }
+	if data[4] == 0 || data[4] > replayVersion { return total, ErrInvalidReplay }
+	body, checksum := data[ : len(data) - 4], data[len(data) - 4 : ]
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(checksum) {
+		return total, ErrInvalidReplay
+	}
+
+	// decode header and state
+	var replay Replay
+	replay.EntryKey = lvlkey.EntryKey(body[5])
+	replay.Seed = int64(binary.LittleEndian.Uint64(body[6 : 14]))
+	stateLen := int(binary.LittleEndian.Uint32(body[14 : 18]))
+	body = body[18 : ]
+	if len(body) < stateLen + 4 { return total, ErrInvalidReplay }
+	replay.State = append([]byte(nil), body[ : stateLen]...)
+	body = body[stateLen : ]
+	numTicks := int(binary.LittleEndian.Uint32(body[0 : 4]))
+	body = body[4 : ]
+
+	// decode ticks. numTicks can't be trusted before decoding, so
+	// preallocation is capped by the remaining payload (each run
+	// takes at least two bytes, but runs can span many ticks)
+	capacity := numTicks
+	if capacity > len(body) { capacity = len(body) }
+	replay.Ticks = make([]ActionSet, 0, capacity)
+	for len(replay.Ticks) < numTicks {
+		run, n := binary.Uvarint(body)
+		if n <= 0 || run == 0 || run > uint64(numTicks - len(replay.Ticks)) { return total, ErrInvalidReplay }
+		body = body[n : ]
+		set, n := binary.Uvarint(body)
+		if n <= 0 || set >= (1 << NumActions) { return total, ErrInvalidReplay }
+		body = body[n : ]
+		for i := uint64(0); i < run; i++ {
+			replay.Ticks = append(replay.Ticks, ActionSet(set))
+		}
+	}
+	if len(body) != 0 { return total, ErrInvalidReplay }
+
+	*self = replay
+	return total, nil
+}
+
+// Creates a source that plays back the replay's ticks.
+func (self *Replay) NewSource() *ScriptedSource {
+	return NewScriptedSourceFromTicks(self.Ticks)
+}
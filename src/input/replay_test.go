@@ -0,0 +1,46 @@
+package input
+
+import "bytes"
+import "errors"
+import "testing"
+import "hash/crc32"
+import "encoding/binary"
+
+func TestReplayRoundTrip(t *testing.T) {
+	replay := Replay{ EntryKey: 3, Seed: -42, State: []byte{ 1, 2, 3 } }
+	for i := 0; i < 500; i++ {
+		replay.Ticks = append(replay.Ticks, ActionSet((i/7) % 5))
+	}
+	var buffer bytes.Buffer
+	_, err := replay.WriteTo(&buffer)
+	if err != nil { t.Fatal(err) }
+
+	var loaded Replay
+	_, err = loaded.ReadFrom(&buffer)
+	if err != nil { t.Fatal(err) }
+	if loaded.EntryKey != replay.EntryKey || loaded.Seed != replay.Seed || !bytes.Equal(loaded.State, replay.State) {
+		t.Fatalf("header mismatch: got %+v", loaded)
+	}
+	if len(loaded.Ticks) != len(replay.Ticks) { t.Fatalf("got %d ticks, expected %d", len(loaded.Ticks), len(replay.Ticks)) }
+	for i, _ := range replay.Ticks {
+		if loaded.Ticks[i] != replay.Ticks[i] { t.Fatalf("tick #%d mismatch", i) }
+	}
+}
+
+// A corrupted tick count must not make ReadFrom() allocate
+// memory for all those ticks (~16GB here) before finding the
+// data invalid.
+func TestReplayHugeTickCount(t *testing.T) {
+	replay := Replay{ Ticks: []ActionSet{ 1, 1, 2 } }
+	var buffer bytes.Buffer
+	_, err := replay.WriteTo(&buffer)
+	if err != nil { t.Fatal(err) }
+	data := buffer.Bytes()
+	data = data[ : len(data) - 4]
+	binary.LittleEndian.PutUint32(data[18 : 22], 0xFFFFFFFF)
+	data = binary.LittleEndian.AppendUint32(data, crc32.ChecksumIEEE(data))
+
+	var loaded Replay
+	_, err = loaded.ReadFrom(bytes.NewReader(data))
+	if !errors.Is(err, ErrInvalidReplay) { t.Fatalf("expected ErrInvalidReplay, got %v", err) }
+}
@@ -0,0 +1,91 @@
+package input
+
+import "strings"
+
+// Bitset of actions, used to represent the pressed actions
+// on a given tick.
+type ActionSet uint32
+
+func init() {
+	if NumActions > 32 { panic("ActionSet can't hold more than 32 actions") }
+}
+
+func NewActionSet(actions ...Action) ActionSet {
+	var set ActionSet
+	for _, action := range actions {
+		set = set.With(action)
+	}
+	return set
+}
+
+func (self ActionSet) With(action Action) ActionSet {
+	return self | (1 << action)
+}
+
+func (self ActionSet) Has(action Action) bool {
+	return self & (1 << action) != 0
+}
+
+func (self ActionSet) String() string {
+	if self == 0 { return "none" }
+	var names []string
+	for action := Action(0); action < actionEndSentinel; action++ {
+		if self.Has(action) { names = append(names, action.String()) }
+	}
+	return strings.Join(names, " ")
+}
+
+var _ Source = (*ScriptedSource)(nil)
+
+// Source that replays a fixed sequence of action sets, one per tick.
+// Once the script is over, no actions are reported as pressed.
+type ScriptedSource struct {
+	ticks []ActionSet
+	index int // index of the next tick
+	current ActionSet
+}
+
+func NewScriptedSource() *ScriptedSource {
+	return &ScriptedSource{}
+}
+
+// Creates a scripted source that replays the given ticks. The
+// slice is retained, but never modified.
+func NewScriptedSourceFromTicks(ticks []ActionSet) *ScriptedSource {
+	return &ScriptedSource{ ticks: ticks }
+}
+
+// Appends the given actions to the script, held for numTicks.
+func (self *ScriptedSource) Hold(actions ActionSet, numTicks int) {
+	for i := 0; i < numTicks; i++ {
+		self.ticks = append(self.ticks, actions)
+	}
+}
+
+// Appends numTicks without any action pressed to the script.
+func (self *ScriptedSource) Wait(numTicks int) {
+	self.Hold(0, numTicks)
+}
+
+// Returns the total number of ticks in the script.
+func (self *ScriptedSource) Len() int {
+	return len(self.ticks)
+}
+
+// Returns whether all the ticks in the script have been consumed.
+func (self *ScriptedSource) Done() bool {
+	return self.index >= len(self.ticks)
+}
+
+func (self *ScriptedSource) Update() {
+	if self.index >= len(self.ticks) {
+		self.current = 0
+		return
+	}
+	self.current = self.ticks[self.index]
+	self.index += 1
+}
+
+func (self *ScriptedSource) ActionPressed(action Action) bool {
+	return self.current.Has(action)
+}
@@ -0,0 +1,256 @@
+package input
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// A Source provides the raw pressed state of each action. Input
+// uses it once per tick to keep track of press durations, triggers
+// and so on. The default source reads the keyboard and gamepads
+// through ebitengine, but scripted sources can be used to drive
+// the game without a window (see ScriptedSource).
+type Source interface {
+	// Called once per tick, before any ActionPressed() queries.
+	Update()
+	ActionPressed(action Action) bool
+}
+
+var _ AxisSource = (*DeviceSource)(nil)
+
+// Source reading the keyboard and the most recently connected gamepad.
+// Movement sticks are mapped onto the movement actions, and analog
+// triggers bound as buttons use the dead zones and hysteresis from
+// the bindings instead of the gamepad's own pressed state.
+//
+// Gamepads without a standard layout can only be used if they
+// appear in the gamepad database (see SetGamepadDB()).
+type DeviceSource struct {
+	bindings *Bindings
+	gamepadDB *GamepadDB
+	gamepadIds []ebiten.GamepadID
+	prevGamepadIds []ebiten.GamepadID
+	activeGamepad ebiten.GamepadID
+	activeMapping *RawGamepadMapping // nil if standard layout
+	hasActiveGamepad bool
+	activeDevice Device
+	listeners []func(DeviceEvent)
+
+	axisValues [NumAxes]float64
+	axisDirs [NumAxes]int8
+}
+
+func NewDeviceSource(keyboardMappings map[Action]ebiten.Key, gamepadMappings map[Action]ebiten.StandardGamepadButton) *DeviceSource {
+	if len(keyboardMappings) != NumActions {
+		panic("incorrect number of keyboard mappings given")
+	}
+	if len(gamepadMappings) != NumActions {
+		panic("incorrect number of gamepad mappings given")
+	}
+	
+	bindings, err := NewBindings(keyboardMappings, gamepadMappings)
+	if err != nil { panic(err) }
+	return NewDeviceSourceFromBindings(bindings)
+}
+
+func NewDeviceSourceFromBindings(bindings *Bindings) *DeviceSource {
+	return &DeviceSource{ bindings: bindings, gamepadDB: NewGamepadDB() }
+}
+
+// Returns the bindings currently in use. They can be modified
+// directly to rebind actions at runtime.
+func (self *DeviceSource) Bindings() *Bindings {
+	return self.bindings
+}
+
+func (self *DeviceSource) SetBindings(bindings *Bindings) {
+	self.bindings = bindings
+}
+
+// Sets the database used for gamepads without a standard layout.
+// Takes effect the next time a gamepad is connected.
+func (self *DeviceSource) SetGamepadDB(db *GamepadDB) {
+	self.gamepadDB = db
+}
+
+// Registers a function to be called on each device event. Events
+// are sent during Update(), in the order they are detected.
+func (self *DeviceSource) OnEvent(listener func(DeviceEvent)) {
+	self.listeners = append(self.listeners, listener)
+}
+
+// Returns the device used most recently.
+func (self *DeviceSource) ActiveDevice() Device {
+	return self.activeDevice
+}
+
+// Returns the gamepad currently used for input, if any.
+func (self *DeviceSource) ActiveGamepad() (ebiten.GamepadID, bool) {
+	return self.activeGamepad, self.hasActiveGamepad
+}
+
+// Returns the number of gamepads currently connected.
+func (self *DeviceSource) NumGamepads() int {
+	return len(self.gamepadIds)
+}
+
+func (self *DeviceSource) Update() {
+	self.updateGamepads()
+	self.updateAnalogValues()
+
+	// detect active device changes
+	var keyboardUsed, gamepadUsed bool
+	for action := Action(0); action < actionEndSentinel; action++ {
+		keyboardUsed = keyboardUsed || self.keyboardPressed(action)
+		gamepadUsed  = gamepadUsed  || self.gamepadPressed(action)
+	}
+	if keyboardUsed && !gamepadUsed && self.activeDevice != DeviceKeyboard {
+		self.activeDevice = DeviceKeyboard
+		self.notify(DeviceEvent{ Type: EventActiveDeviceChanged, Device: DeviceKeyboard })
+	} else if gamepadUsed && !keyboardUsed && self.activeDevice != DeviceGamepad {
+		self.activeDevice = DeviceGamepad
+		self.notify(DeviceEvent{ Type: EventActiveDeviceChanged, Device: DeviceGamepad, GamepadID: self.activeGamepad })
+	}
+}
+
+func (self *DeviceSource) updateGamepads() {
+	self.prevGamepadIds = append(self.prevGamepadIds[ : 0], self.gamepadIds...)
+	self.gamepadIds = ebiten.AppendGamepadIDs(self.gamepadIds[ : 0])
+
+	// notify connections and disconnections
+	for _, id := range self.prevGamepadIds {
+		if containsGamepadID(self.gamepadIds, id) { continue }
+		self.notify(DeviceEvent{ Type: EventGamepadDisconnected, Device: DeviceGamepad, GamepadID: id })
+	}
+	for _, id := range self.gamepadIds {
+		if containsGamepadID(self.prevGamepadIds, id) { continue }
+		self.notify(DeviceEvent{
+			Type: EventGamepadConnected,
+			Device: DeviceGamepad,
+			GamepadID: id,
+			GamepadName: ebiten.GamepadName(id),
+			GamepadGUID: ebiten.GamepadSDLID(id),
+			Supported: self.isSupported(id),
+		})
+	}
+
+	// the most recently connected supported gamepad is the active one
+	hadActiveGamepad, prevActiveGamepad := self.hasActiveGamepad, self.activeGamepad
+	self.hasActiveGamepad = false
+	for i := len(self.gamepadIds) - 1; i >= 0; i-- {
+		id := self.gamepadIds[i]
+		if !self.isSupported(id) { continue }
+		if !hadActiveGamepad || id != prevActiveGamepad {
+			self.activeMapping = nil
+			if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+				self.activeMapping, _ = self.gamepadDB.Lookup(ebiten.GamepadSDLID(id))
+			}
+		}
+		self.activeGamepad = id
+		self.hasActiveGamepad = true
+		break
+	}
+
+	// if the active gamepad is lost, go back to the keyboard
+	if hadActiveGamepad && !self.hasActiveGamepad && self.activeDevice == DeviceGamepad {
+		self.activeDevice = DeviceKeyboard
+		self.notify(DeviceEvent{ Type: EventActiveDeviceChanged, Device: DeviceKeyboard })
+	}
+}
+
+func (self *DeviceSource) isSupported(id ebiten.GamepadID) bool {
+	if ebiten.IsStandardGamepadLayoutAvailable(id) { return true }
+	_, found := self.gamepadDB.Lookup(ebiten.GamepadSDLID(id))
+	return found
+}
+
+func (self *DeviceSource) updateAnalogValues() {
+	if !self.hasActiveGamepad {
+		self.axisValues = [NumAxes]float64{}
+		self.axisDirs = [NumAxes]int8{}
+		return
+	}
+
+	deadZones := self.bindings.DeadZones()
+	for axis := Axis(0); axis < axisEndSentinel; axis++ {
+		var value float64
+		switch axis {
+		case AxisTriggerLeft:
+			value = self.stdButtonValue(ebiten.StandardGamepadButtonFrontBottomLeft)
+		case AxisTriggerRight:
+			value = self.stdButtonValue(ebiten.StandardGamepadButtonFrontBottomRight)
+		default:
+			binding, isBound := self.bindings.StickAxis(axis)
+			if isBound {
+				value = self.stdAxisValue(binding.StdAxis)
+				if binding.Inverted { value = -value }
+			}
+		}
+		self.axisValues[axis] = deadZones.Apply(value)
+		self.axisDirs[axis] = deadZones.Direction(self.axisValues[axis], self.axisDirs[axis])
+	}
+}
+
+func (self *DeviceSource) AxisValue(axis Axis) float64 {
+	return self.axisValues[axis]
+}
+
+func (self *DeviceSource) ActionPressed(action Action) bool {
+	return self.keyboardPressed(action) || self.gamepadPressed(action)
+}
+
+func (self *DeviceSource) keyboardPressed(action Action) bool {
+	for _, key := range self.bindings.Keys(action) {
+		if ebiten.IsKeyPressed(key) { return true }
+	}
+	return false
+}
+
+func (self *DeviceSource) gamepadPressed(action Action) bool {
+	if !self.hasActiveGamepad { return false }
+	for _, btn := range self.bindings.Buttons(action) {
+		switch btn {
+		case ebiten.StandardGamepadButtonFrontBottomLeft:
+			if self.axisDirs[AxisTriggerLeft] > 0 { return true }
+		case ebiten.StandardGamepadButtonFrontBottomRight:
+			if self.axisDirs[AxisTriggerRight] > 0 { return true }
+		default:
+			if self.stdButtonPressed(btn) { return true }
+		}
+	}
+
+	// movement sticks
+	for axis := AxisMoveX; axis <= AxisMoveY; axis++ {
+		negAction, posAction, _ := axis.actions()
+		if action == negAction && self.axisDirs[axis] < 0 { return true }
+		if action == posAction && self.axisDirs[axis] > 0 { return true }
+	}
+	return false
+}
+
+// --- standard layout helpers (with raw mapping fallbacks) ---
+
+func (self *DeviceSource) stdButtonPressed(btn ebiten.StandardGamepadButton) bool {
+	if self.activeMapping != nil { return self.activeMapping.ButtonPressed(self.activeGamepad, btn) }
+	return ebiten.IsStandardGamepadButtonPressed(self.activeGamepad, btn)
+}
+
+func (self *DeviceSource) stdButtonValue(btn ebiten.StandardGamepadButton) float64 {
+	if self.activeMapping != nil { return self.activeMapping.ButtonValue(self.activeGamepad, btn) }
+	return ebiten.StandardGamepadButtonValue(self.activeGamepad, btn)
+}
+
+func (self *DeviceSource) stdAxisValue(axis ebiten.StandardGamepadAxis) float64 {
+	if self.activeMapping != nil { return self.activeMapping.AxisValue(self.activeGamepad, axis) }
+	return ebiten.StandardGamepadAxisValue(self.activeGamepad, axis)
+}
+
+func (self *DeviceSource) notify(event DeviceEvent) {
+	for _, listener := range self.listeners {
+		listener(event)
+	}
+}
+
+func containsGamepadID(ids []ebiten.GamepadID, id ebiten.GamepadID) bool {
+	for _, candidate := range ids {
+		if candidate == id { return true }
+	}
+	return false
+}
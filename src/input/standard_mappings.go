@@ -2,7 +2,20 @@ package input
 
 import "github.com/hajimehoshi/ebiten/v2"
 
-// TODO: stdKeyboardMappingArrowsASD, stdKeyboardMappingArrowsZXC, ...
+// Named mapping presets. See NewBindingsFromPresets().
+var KeyboardPresets = map[string]map[Action]ebiten.Key{
+	"wasd": StdKeyboardMappingWASD,
+	"arrows_asd": StdKeyboardMappingArrowsASD,
+	"arrows_zxc": StdKeyboardMappingArrowsZXC,
+}
+var GamepadPresets = map[string]map[Action]ebiten.StandardGamepadButton{
+	"standard": StdGamepadMapping,
+	"alt": StdGamepadMappingAlt,
+}
+
+const DefaultKeyboardPreset = "wasd"
+const DefaultGamepadPreset = "standard"
+
 var StdKeyboardMappingWASD = map[Action]ebiten.Key{
 	ActionMoveLeft: ebiten.KeyA,
 	ActionMoveRight: ebiten.KeyD,
@@ -17,9 +30,43 @@ var StdKeyboardMappingWASD = map[Action]ebiten.Key{
 	ActionCenterCamera: ebiten.KeyQ,
 	ActionFullscreen: ebiten.KeyF,
 	ActionFullscreen2: ebiten.KeyF11,
+	ActionQuit: ebiten.KeyEscape,
+}
+
+var StdKeyboardMappingArrowsASD = map[Action]ebiten.Key{
+	ActionMoveLeft: ebiten.KeyArrowLeft,
+	ActionMoveRight: ebiten.KeyArrowRight,
+	ActionDown: ebiten.KeyArrowDown,
+	ActionUp: ebiten.KeyArrowUp,
+	ActionJump: ebiten.KeyA,
+	ActionInteract: ebiten.KeyS,
+	ActionOutReverse: ebiten.KeyD,
+	ActionOnePixelRight: ebiten.Key0,
+	ActionOnePixelLeft: ebiten.Key9,
+
+	ActionCenterCamera: ebiten.KeyQ,
+	ActionFullscreen: ebiten.KeyF,
+	ActionFullscreen2: ebiten.KeyF11,
+	ActionQuit: ebiten.KeyEscape,
+}
+
+var StdKeyboardMappingArrowsZXC = map[Action]ebiten.Key{
+	ActionMoveLeft: ebiten.KeyArrowLeft,
+	ActionMoveRight: ebiten.KeyArrowRight,
+	ActionDown: ebiten.KeyArrowDown,
+	ActionUp: ebiten.KeyArrowUp,
+	ActionJump: ebiten.KeyZ,
+	ActionInteract: ebiten.KeyX,
+	ActionOutReverse: ebiten.KeyC,
+	ActionOnePixelRight: ebiten.Key0,
+	ActionOnePixelLeft: ebiten.Key9,
+
+	ActionCenterCamera: ebiten.KeyQ,
+	ActionFullscreen: ebiten.KeyF,
+	ActionFullscreen2: ebiten.KeyF11,
+	ActionQuit: ebiten.KeyEscape,
 }
 
-// TODO: stdGamepadMappingAlt, etc
 var StdGamepadMapping = map[Action]ebiten.StandardGamepadButton{
 	ActionMoveLeft: ebiten.StandardGamepadButtonLeftLeft,
 	ActionMoveRight: ebiten.StandardGamepadButtonLeftRight,
@@ -36,4 +83,26 @@ var StdGamepadMapping = map[Action]ebiten.StandardGamepadButton{
 	ActionOnePixelRight: -1,
 	ActionOnePixelLeft: -1,
 	ActionFullscreen2: -1,
+	ActionQuit: -1,
+}
+
+// Swaps jump and interact (for pads with the confirm button on
+// the right) and moves reversing to the right trigger.
+var StdGamepadMappingAlt = map[Action]ebiten.StandardGamepadButton{
+	ActionMoveLeft: ebiten.StandardGamepadButtonLeftLeft,
+	ActionMoveRight: ebiten.StandardGamepadButtonLeftRight,
+	ActionUp: ebiten.StandardGamepadButtonLeftTop,
+	ActionDown: ebiten.StandardGamepadButtonLeftBottom,
+	ActionJump: ebiten.StandardGamepadButtonRightRight,
+	ActionOutReverse: ebiten.StandardGamepadButtonFrontBottomRight,
+	ActionInteract: ebiten.StandardGamepadButtonRightBottom,
+
+	ActionCenterCamera: ebiten.StandardGamepadButtonFrontBottomLeft,
+	ActionFullscreen: ebiten.StandardGamepadButtonCenterLeft,
+
+	// unassigned actions
+	ActionOnePixelRight: -1,
+	ActionOnePixelLeft: -1,
+	ActionFullscreen2: -1,
+	ActionQuit: -1,
 }
@@ -0,0 +1,239 @@
+// Package lang provides localized strings. Each language has a string
+// table in the assets (assets/lang/<code>.txt), and strings are looked
+// up by ID. English is the fallback for strings missing in the active
+// language.
+//
+// Table file format, one string per line:
+//   <id> = <text>
+//   <id>[<plural_form>] = <text>
+// Lines ending with '\' continue on the next one (leading whitespace
+// is trimmed), and "\n" can be used for line breaks. Plural forms are
+// "one" and "other" (see PluralForm()), and "{n}" is replaced with the
+// count in plural strings. Empty lines and lines starting with '#' are
+// ignored.
+//
+// String IDs use dotted prefixes to indicate where they are shown:
+// "msg." strings are shown in message boxes that can't be paged,
+// "tip." and "dlg." (dialogue) strings in message boxes that can,
+// and other strings are drawn as raw lines.
+package lang
+
+import "io"
+import "io/fs"
+import "sort"
+import "path"
+import "bufio"
+import "errors"
+import "strconv"
+import "strings"
+
+// Language used when a string is missing in the active one.
+const Fallback = "en"
+
+// Directory of the string tables in the assets filesystem.
+const Dir = "assets/lang"
+
+var pkgActive *Table
+var pkgFallback *Table
+var pkgRevision int
+
+type Table struct {
+	code string
+	entries map[string]string
+}
+
+// Loads the string table for the given language from the filesystem
+// (see Dir) and sets it as the active language. The fallback table is
+// loaded too if necessary.
+func Load(filesys fs.FS, code string) error {
+	if pkgFallback == nil || pkgFallback.code != Fallback {
+		fallback, err := LoadTable(filesys, Fallback)
+		if err != nil { return err }
+		pkgFallback = fallback
+	}
+	if code == Fallback {
+		pkgActive = pkgFallback
+	} else {
+		table, err := LoadTable(filesys, code)
+		if err != nil { return err }
+		pkgActive = table
+	}
+	pkgRevision += 1
+	return nil
+}
+
+// Returns the code of the active language.
+func Language() string {
+	if pkgActive == nil { return "" }
+	return pkgActive.code
+}
+
+// Returns a counter that increases each time the active language
+// changes, so cached text layouts can be invalidated.
+func Revision() int {
+	return pkgRevision
+}
+
+// Returns the string with the given ID in the active language, or
+// in the fallback language if missing. Panics if missing in both.
+func Get(id string) string {
+	if pkgActive == nil { panic("lang: no language loaded") }
+	str, found := pkgActive.entries[id]
+	if found { return str }
+	str, found = pkgFallback.entries[id]
+	if !found { panic("lang: missing string '" + id + "'") }
+	return str
+}
+
+// Returns the plural string with the given ID for the given count,
+// with "{n}" replaced by the count.
+func Plural(id string, n int) string {
+	if pkgActive == nil { panic("lang: no language loaded") }
+	str, found := pkgActive.lookupPlural(id, n)
+	if !found {
+		str, found = pkgFallback.lookupPlural(id, n)
+		if !found { panic("lang: missing plural string '" + id + "'") }
+	}
+	return strings.ReplaceAll(str, "{n}", strconv.Itoa(n))
+}
+
+// Returns the plural form used by the given language for the count.
+func PluralForm(code string, n int) string {
+	switch code {
+	case "fr":
+		if n == 0 || n == 1 { return "one" }
+	default:
+		if n == 1 { return "one" }
+	}
+	return "other"
+}
+
+// Returns the codes of all the languages with a string table in
+// the filesystem, sorted.
+func Available(filesys fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(filesys, Dir)
+	if err != nil { return nil, err }
+	var codes []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || path.Ext(name) != ".txt" { continue }
+		codes = append(codes, strings.TrimSuffix(name, ".txt"))
+	}
+	sort.Strings(codes)
+	return codes, nil
+}
+
+// --- tables ---
+
+func LoadTable(filesys fs.FS, code string) (*Table, error) {
+	filename := path.Join(Dir, code + ".txt")
+	file, err := filesys.Open(filename)
+	if err != nil { return nil, err }
+	defer file.Close()
+	table, err := ParseTable(code, file)
+	if err != nil { return nil, errors.New(filename + ": " + err.Error()) }
+	return table, nil
+}
+
+func ParseTable(code string, reader io.Reader) (*Table, error) {
+	table := &Table{ code: code, entries: make(map[string]string) }
+	scanner := bufio.NewScanner(reader)
+	lineNum, startLineNum := 0, 0
+	var pending strings.Builder
+	for scanner.Scan() {
+		lineNum += 1
+		line := scanner.Text()
+		if pending.Len() == 0 {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") { continue }
+			startLineNum = lineNum
+		} else {
+			line = strings.TrimLeft(line, " \t")
+		}
+
+		// handle line continuations
+		if strings.HasSuffix(line, "\\") && !strings.HasSuffix(line, "\\\\") {
+			pending.WriteString(line[ : len(line) - 1])
+			continue
+		}
+		pending.WriteString(line)
+		err := table.parseEntry(pending.String())
+		if err != nil { return nil, tableLineErr(startLineNum, err.Error()) }
+		pending.Reset()
+	}
+	err := scanner.Err()
+	if err != nil { return nil, err }
+	if pending.Len() != 0 { return nil, tableLineErr(startLineNum, "unterminated line continuation") }
+	return table, nil
+}
+
+func tableLineErr(lineNum int, msg string) error {
+	return errors.New("lang: string table line " + strconv.Itoa(lineNum) + ": " + msg)
+}
+
+func (self *Table) parseEntry(line string) error {
+	id, value, found := strings.Cut(line, "=")
+	if !found { return errors.New("expected '<id> = <text>'") }
+	id = strings.TrimSpace(id)
+	if id == "" || strings.ContainsAny(id, " \t") { return errors.New("invalid string id '" + id + "'") }
+	if base, form, isPlural := strings.Cut(id, "["); isPlural {
+		if base == "" || (form != "one]" && form != "other]") {
+			return errors.New("invalid plural string id '" + id + "'")
+		}
+	}
+	if _, found := self.entries[id]; found { return errors.New("duplicated string id '" + id + "'") }
+
+	value, err := unescapeTableText(strings.TrimSpace(value))
+	if err != nil { return err }
+	self.entries[id] = value
+	return nil
+}
+
+func unescapeTableText(str string) (string, error) {
+	if strings.IndexByte(str, '\\') == -1 { return str, nil }
+	var builder strings.Builder
+	for i := 0; i < len(str); i++ {
+		if str[i] != '\\' {
+			builder.WriteByte(str[i])
+			continue
+		}
+		i += 1
+		if i >= len(str) { return "", errors.New("unterminated escape sequence") }
+		switch str[i] {
+		case 'n': builder.WriteByte('\n')
+		case '\\': builder.WriteByte('\\')
+		default:
+			return "", errors.New("invalid escape sequence '\\" + string(str[i]) + "'")
+		}
+	}
+	return builder.String(), nil
+}
+
+func (self *Table) Code() string {
+	return self.code
+}
+
+// Returns all the string IDs in the table, sorted. Plural strings
+// appear once for each form, as "<id>[<form>]".
+func (self *Table) IDs() []string {
+	ids := make([]string, 0, len(self.entries))
+	for id, _ := range self.entries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Returns the string with the given ID, which may include a plural
+// form as returned by IDs().
+func (self *Table) Lookup(id string) (string, bool) {
+	str, found := self.entries[id]
+	return str, found
+}
+
+func (self *Table) lookupPlural(id string, n int) (string, bool) {
+	str, found := self.entries[id + "[" + PluralForm(self.code, n) + "]"]
+	if found { return str, true }
+	str, found = self.entries[id + "[other]"]
+	return str, found
+}
@@ -0,0 +1,37 @@
+package rng
+
+import "time"
+import "math/rand"
+
+// Seedable random number generators. Everything in the game should use
+// these instead of the global math/rand functions, so sessions can be
+// replayed exactly with the same seed.
+//
+// Audio uses its own generator because sound effects can be skipped
+// depending on voice limits and the audio thread's timing, which would
+// otherwise make the rest of the sequence diverge. The same applies to
+// anything locked to the music clock. Main must only be used during updates
+// (not on draws), as the number of draws per tick is not fixed.
+var Main  = rand.New(rand.NewSource(1))
+var Audio = rand.New(rand.NewSource(1))
+
+const audioSeedMask = 0x5F3759DF
+
+var currentSeed int64 = 1
+
+// Reseeds all the generators.
+func Seed(seed int64) {
+	currentSeed = seed
+	Main.Seed(seed)
+	Audio.Seed(seed ^ audioSeedMask)
+}
+
+// Returns the seed passed to the last Seed() call.
+func CurrentSeed() int64 {
+	return currentSeed
+}
+
+// Returns a seed based on the current time.
+func NewSeed() int64 {
+	return time.Now().UnixNano()
+}
@@ -0,0 +1,11 @@
+package shaders
+
+// A musical clock that oscillators and pulse generators can lock to
+// instead of using their own tick counters. audio.MusicClock is the
+// main implementation.
+type BeatClock interface {
+	// Returns the current position in beats, with the fractional
+	// part indicating the progress within the current beat, and
+	// whether the clock is running at all.
+	BeatPosition() (float64, bool)
+}
@@ -1,6 +1,8 @@
 package shaders
 
-import "math/rand"
+import "math"
+
+import "github.com/tinne26/transition/src/rng"
 
 type Oscillator struct {
 	minValue float32
@@ -16,6 +18,10 @@ type Oscillator struct {
 	nextKeyValue float32
 
 	interpolator Interpolator
+
+	clock BeatClock
+	beatsPerOsc float64
+	locked bool // whether the clock is running and being followed
 }
 
 func NewOscillator(minValue, maxValue float32, minTicks, maxTicks, valueVariance float64) *Oscillator {
@@ -31,7 +37,26 @@ func NewOscillator(minValue, maxValue float32, minTicks, maxTicks, valueVariance
 	return osc
 }
 
+// Makes the oscillation key points follow the clock, with a key point
+// every beatsPerOsc beats. While the clock is not running, the oscillator
+// keeps using its own tick counter. Pass a nil clock to unlock.
+func (self *Oscillator) LockTo(clock BeatClock, beatsPerOsc float64) {
+	if clock != nil && beatsPerOsc <= 0 { panic("beatsPerOsc <= 0") }
+	self.clock = clock
+	self.beatsPerOsc = beatsPerOsc
+	if self.locked { self.unlock() }
+}
+
 func (self *Oscillator) Update() {
+	if self.clock != nil {
+		beats, running := self.clock.BeatPosition()
+		if running {
+			self.updateLocked(beats)
+			return
+		}
+	}
+	if self.locked { self.unlock() }
+
 	self.currentTick += 1.0
 	for self.currentTick >= self.nextKeyTick {
 		self.generateNewNextKeyData()
@@ -44,10 +69,36 @@ func (self *Oscillator) generateNewNextKeyData() {
 	self.prevKeyValue = self.nextKeyValue
 
 	// re-generate next
-	self.nextKeyTick = self.prevKeyTick + self.minOscTicks + (self.maxOscTicks - self.minOscTicks)*rand.Float64()
+	self.nextKeyTick = self.prevKeyTick + self.minOscTicks + (self.maxOscTicks - self.minOscTicks)*rng.Main.Float64()
+	self.nextKeyValue = self.newKeyValue(rng.Main.Float64())
+}
+
+func (self *Oscillator) newKeyValue(unit float64) float32 {
 	valueRange    := self.maxValue - self.minValue
-	varianceRange := float32(self.valueVariance*rand.Float64())
-	self.nextKeyValue = self.minValue + self.maxValue - varianceRange*valueRange
+	varianceRange := float32(self.valueVariance*unit)
+	return self.minValue + self.maxValue - varianceRange*valueRange
+}
+
+// When locked, "ticks" are actually beats. The values generated here
+// use rng.Audio because they depend on the audio thread's timing.
+func (self *Oscillator) updateLocked(beats float64) {
+	prevKeyBeat := math.Floor(beats/self.beatsPerOsc)*self.beatsPerOsc
+	if !self.locked || prevKeyBeat != self.prevKeyTick {
+		self.locked = true
+		self.prevKeyTick  = prevKeyBeat
+		self.prevKeyValue = self.nextKeyValue
+		self.nextKeyTick  = prevKeyBeat + self.beatsPerOsc
+		self.nextKeyValue = self.newKeyValue(rng.Audio.Float64())
+	}
+	self.currentTick = beats
+}
+
+// Goes back to the oscillator's own tick counter.
+func (self *Oscillator) unlock() {
+	self.locked = false
+	self.currentTick = 0
+	self.nextKeyTick = 0
+	self.generateNewNextKeyData()
 }
 
 func (self *Oscillator) CurrentValue() float32 {
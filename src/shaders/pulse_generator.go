@@ -1,7 +1,8 @@
 package shaders
 
 import "math"
-import "math/rand"
+
+import "github.com/tinne26/transition/src/rng"
 
 type PulseGenerator struct {
 	floorValue float32
@@ -18,6 +19,10 @@ type PulseGenerator struct {
 	nextPeakValue float32
 
 	interpolator Interpolator
+
+	clock BeatClock
+	beatsPerPulse float64
+	locked bool // whether the clock is running and being followed
 }
 
 func NewPulseGenerator(floorValue, minValue, maxValue float32, minTicks, maxTicks, regularity float64) *PulseGenerator {
@@ -34,7 +39,26 @@ func NewPulseGenerator(floorValue, minValue, maxValue float32, minTicks, maxTick
 	return gen
 }
 
+// Makes the pulses follow the clock, with a peak every beatsPerPulse
+// beats. While the clock is not running, the generator keeps using
+// its own tick counter. Pass a nil clock to unlock.
+func (self *PulseGenerator) LockTo(clock BeatClock, beatsPerPulse float64) {
+	if clock != nil && beatsPerPulse <= 0 { panic("beatsPerPulse <= 0") }
+	self.clock = clock
+	self.beatsPerPulse = beatsPerPulse
+	if self.locked { self.unlock() }
+}
+
 func (self *PulseGenerator) Update() {
+	if self.clock != nil {
+		beats, running := self.clock.BeatPosition()
+		if running {
+			self.updateLocked(beats)
+			return
+		}
+	}
+	if self.locked { self.unlock() }
+
 	self.currentTick += 1.0
 	for self.currentTick >= self.nextPeakTick {
 		self.generateNewNextPeak()		
@@ -49,7 +73,29 @@ func (self *PulseGenerator) generateNewNextPeak() {
 	// re-generate next
 	unit := self.regularUnit()
 	self.nextPeakTick = self.prevPeakTick + self.minPulseTicks + (self.maxPulseTicks - self.minPulseTicks)*unit
-	self.nextPeakValue = self.minValue + rand.Float32()*self.maxValue
+	self.nextPeakValue = self.minValue + rng.Main.Float32()*self.maxValue
+}
+
+// When locked, "ticks" are actually beats. The values generated here
+// use rng.Audio because they depend on the audio thread's timing.
+func (self *PulseGenerator) updateLocked(beats float64) {
+	prevPeakBeat := math.Floor(beats/self.beatsPerPulse)*self.beatsPerPulse
+	if !self.locked || prevPeakBeat != self.prevPeakTick {
+		self.locked = true
+		self.prevPeakTick  = prevPeakBeat
+		self.prevPeakValue = self.nextPeakValue
+		self.nextPeakTick  = prevPeakBeat + self.beatsPerPulse
+		self.nextPeakValue = self.minValue + rng.Audio.Float32()*self.maxValue
+	}
+	self.currentTick = beats
+}
+
+// Goes back to the generator's own tick counter.
+func (self *PulseGenerator) unlock() {
+	self.locked = false
+	self.currentTick = 0
+	self.nextPeakTick = 0
+	self.generateNewNextPeak()
 }
 
 func (self *PulseGenerator) CurrentValue() float32 {
@@ -64,8 +110,8 @@ func (self *PulseGenerator) CurrentValue() float32 {
 }
 
 func (self *PulseGenerator) regularUnit() float64 {
-	nvalue := rand.NormFloat64()/6 + 0.5
+	nvalue := rng.Main.NormFloat64()/6 + 0.5
 	nvalue  = math.Min(math.Max(nvalue, 0), 1) // clamped to [0, 1]
-	lvalue := rand.Float64()
+	lvalue := rng.Main.Float64()
 	return self.regularity*nvalue + lvalue*(1.0 - self.regularity)
 }
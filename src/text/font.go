@@ -0,0 +1,318 @@
+package text
+
+import "io"
+import "io/fs"
+import "path"
+import "image"
+import "bufio"
+import "errors"
+import "strconv"
+import "strings"
+import "unicode/utf8"
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+import "github.com/tinne26/transition/src/utils"
+
+// Bitmap fonts are loaded from a glyph atlas PNG (white glyphs on a
+// transparent background) and a metrics file. All glyphs in the atlas
+// share the same cell height, so the glyph rects only need a position
+// and a width. Key glyphs are not part of fonts; they are always drawn
+// with the bitmaps from masks.go.
+//
+// Metrics file format, one statement per line:
+//   atlas <png_path>          (relative to the metrics file)
+//   cell_height <rows>
+//   line_top <rows>           (cell rows above the top of the line)
+//   space_advance <pixels>
+//   letter_spacing <pixels>   (between consecutive non-space glyphs)
+//   fallback <char>           (drawn for chars without a glyph)
+//   glyph <char> <x> <y> <width> [advance:<pixels>] [offset:<pixels>]
+//   kern <char> <char> <pixels>
+// Chars are written literally, or as U+XXXX. Glyph advances default
+// to the glyph width, and offsets (horizontal shifts applied when
+// drawing) to zero. Empty lines and lines starting with '#' are
+// ignored.
+type Font struct {
+	glyphs map[rune]fontGlyph
+	kerning map[[2]rune]int
+	lineTop int
+	spaceAdvance int
+	letterSpacing int
+	fallback rune
+}
+
+type fontGlyph struct {
+	image *ebiten.Image
+	advance int
+	offset int
+}
+
+// The font used by DrawLine(), MeasureLineWidth() and everything
+// built on them. Initialized to the built-in font in masks.go.
+var pkgFont *Font
+
+// Sets the font used to draw all text. If nil, the built-in
+// uppercase font is restored.
+func SetFont(font *Font) {
+	if font == nil { font = pkgBuiltinFont }
+	pkgFont = font
+}
+
+// Loads a font and sets it as the font used to draw all text.
+func LoadAndSetFont(filesys fs.FS, metricsPath string) error {
+	font, err := LoadFont(filesys, metricsPath)
+	if err != nil { return err }
+	SetFont(font)
+	return nil
+}
+
+func LoadFont(filesys fs.FS, metricsPath string) (*Font, error) {
+	file, err := filesys.Open(metricsPath)
+	if err != nil { return nil, err }
+	defer file.Close()
+	metrics, err := parseFontMetrics(file)
+	if err != nil { return nil, errors.New(metricsPath + ": " + err.Error()) }
+
+	atlasPath := path.Join(path.Dir(metricsPath), metrics.atlas)
+	atlas, err := utils.LoadFsEbiImage(filesys, atlasPath)
+	if err != nil { return nil, err }
+	font, err := metrics.build(atlas)
+	if err != nil { return nil, errors.New(metricsPath + ": " + err.Error()) }
+	return font, nil
+}
+
+// Returns whether the font has a glyph for the given code point.
+// Spaces and key glyphs are always supported.
+func (self *Font) HasGlyph(codePoint rune) bool {
+	if codePoint == ' ' || IsKeyGlyph(codePoint) { return true }
+	_, found := self.glyphs[codePoint]
+	return found
+}
+
+// Returns the glyph for the given code point, or the fallback glyph
+// if missing, and the number of rows to draw it above the line top.
+func (self *Font) glyph(codePoint rune) (fontGlyph, int) {
+	if IsKeyGlyph(codePoint) {
+		bitmap := keyGlyphBitmap(codePoint) // may change with SetKeyGlyphLabel()
+		return fontGlyph{ image: bitmap, advance: bitmap.Bounds().Dx() }, 0
+	}
+	glyph, found := self.glyphs[codePoint]
+	if !found { glyph = self.glyphs[self.fallback] }
+	return glyph, self.lineTop
+}
+
+// Lays out the line and calls fn (if not nil) for each glyph to draw,
+// with its byte index in the line and its position relative to the
+// line origin. Returns the advance width of the line. The line can't
+// contain markup.
+func (self *Font) layout(line string, fn func(*ebiten.Image, int, int, int)) int {
+	x := 0
+	prevCodePoint := ' '
+	for index, codePoint := range line {
+		if codePoint == ' ' {
+			x += self.spaceAdvance
+			prevCodePoint = codePoint
+			continue
+		}
+
+		glyph, top := self.glyph(codePoint)
+		if prevCodePoint != ' ' {
+			x += self.letterSpacing + self.kerning[[2]rune{prevCodePoint, codePoint}]
+		}
+		if fn != nil { fn(glyph.image, index, x + glyph.offset, -top) }
+		x += glyph.advance
+		prevCodePoint = codePoint
+	}
+	return x
+}
+
+// --- metrics parsing ---
+
+type fontMetrics struct {
+	atlas string
+	cellHeight int
+	lineTop int
+	spaceAdvance int
+	letterSpacing int
+	fallback rune
+	glyphs map[rune]fontGlyphRect
+	kerning map[[2]rune]int
+}
+
+type fontGlyphRect struct {
+	x, y, width int
+	advance int
+	offset int
+}
+
+func parseFontMetrics(reader io.Reader) (*fontMetrics, error) {
+	metrics := &fontMetrics{
+		cellHeight: -1,
+		lineTop: -1,
+		spaceAdvance: -1,
+		letterSpacing: -1,
+		fallback: -1,
+		glyphs: make(map[rune]fontGlyphRect),
+		kerning: make(map[[2]rune]int),
+	}
+
+	scanner := bufio.NewScanner(reader)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum += 1
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") { continue }
+		err := metrics.parseStatement(fields)
+		if err != nil { return nil, fontLineErr(lineNum, err.Error()) }
+	}
+	err := scanner.Err()
+	if err != nil { return nil, err }
+
+	switch {
+	case metrics.atlas == ""        : return nil, errors.New("text: missing font atlas")
+	case metrics.cellHeight < 0     : return nil, errors.New("text: missing font cell_height")
+	case metrics.lineTop < 0        : return nil, errors.New("text: missing font line_top")
+	case metrics.spaceAdvance < 0   : return nil, errors.New("text: missing font space_advance")
+	case metrics.letterSpacing < 0  : return nil, errors.New("text: missing font letter_spacing")
+	case metrics.fallback < 0       : return nil, errors.New("text: missing font fallback")
+	}
+	if _, found := metrics.glyphs[metrics.fallback]; !found {
+		return nil, errors.New("text: missing glyph for fallback '" + string(metrics.fallback) + "'")
+	}
+	for pair, _ := range metrics.kerning {
+		for _, codePoint := range pair {
+			if _, found := metrics.glyphs[codePoint]; !found {
+				return nil, errors.New("text: kerning pair uses missing glyph '" + string(codePoint) + "'")
+			}
+		}
+	}
+	return metrics, nil
+}
+
+func fontLineErr(lineNum int, msg string) error {
+	return errors.New("text: font metrics line " + strconv.Itoa(lineNum) + ": " + msg)
+}
+
+func (self *fontMetrics) parseStatement(fields []string) error {
+	var err error
+	args := fields[1 : ]
+	switch fields[0] {
+	case "atlas":
+		if len(args) != 1 { return errors.New("atlas expects 1 argument") }
+		self.atlas = args[0]
+	case "cell_height":
+		self.cellHeight, err = parseFontInt(args, 1, 64)
+	case "line_top":
+		self.lineTop, err = parseFontInt(args, 0, 64)
+	case "space_advance":
+		self.spaceAdvance, err = parseFontInt(args, 0, 64)
+	case "letter_spacing":
+		self.letterSpacing, err = parseFontInt(args, 0, 64)
+	case "fallback":
+		if len(args) != 1 { return errors.New("fallback expects 1 argument") }
+		self.fallback, err = parseFontChar(args[0])
+	case "glyph":
+		return self.parseGlyph(args)
+	case "kern":
+		if len(args) != 3 { return errors.New("kern expects 3 arguments") }
+		first, err := parseFontChar(args[0])
+		if err != nil { return err }
+		second, err := parseFontChar(args[1])
+		if err != nil { return err }
+		pixels, err := strconv.ParseInt(args[2], 10, 8)
+		if err != nil { return errors.New("invalid kerning '" + args[2] + "'") }
+		self.kerning[[2]rune{first, second}] = int(pixels)
+	default:
+		return errors.New("unknown statement '" + fields[0] + "'")
+	}
+	return err
+}
+
+func (self *fontMetrics) parseGlyph(args []string) error {
+	if len(args) < 4 { return errors.New("glyph expects at least 4 arguments") }
+	codePoint, err := parseFontChar(args[0])
+	if err != nil { return err }
+	if codePoint == ' ' || IsKeyGlyph(codePoint) {
+		return errors.New("glyph can't be defined for '" + args[0] + "'")
+	}
+	if _, found := self.glyphs[codePoint]; found {
+		return errors.New("duplicated glyph '" + args[0] + "'")
+	}
+
+	var rect fontGlyphRect
+	for i, value := range []*int{&rect.x, &rect.y, &rect.width} {
+		n, err := strconv.ParseUint(args[i + 1], 10, 16)
+		if err != nil { return errors.New("invalid glyph coordinate '" + args[i + 1] + "'") }
+		*value = int(n)
+	}
+	if rect.width == 0 { return errors.New("glyph width must be positive") }
+	rect.advance = rect.width
+
+	for _, option := range args[4 : ] {
+		key, value, found := strings.Cut(option, ":")
+		if !found { return errors.New("invalid glyph option '" + option + "'") }
+		n, err := strconv.ParseInt(value, 10, 8)
+		if err != nil { return errors.New("invalid glyph option '" + option + "'") }
+		switch key {
+		case "advance":
+			if n < 0 { return errors.New("negative glyph advance") }
+			rect.advance = int(n)
+		case "offset":
+			rect.offset = int(n)
+		default:
+			return errors.New("unknown glyph option '" + key + "'")
+		}
+	}
+	self.glyphs[codePoint] = rect
+	return nil
+}
+
+func (self *fontMetrics) build(atlas *ebiten.Image) (*Font, error) {
+	if self.lineTop >= self.cellHeight {
+		return nil, errors.New("text: font line_top must be below cell_height")
+	}
+	font := &Font{
+		glyphs: make(map[rune]fontGlyph, len(self.glyphs)),
+		kerning: self.kerning,
+		lineTop: self.lineTop,
+		spaceAdvance: self.spaceAdvance,
+		letterSpacing: self.letterSpacing,
+		fallback: self.fallback,
+	}
+	bounds := atlas.Bounds()
+	for codePoint, rect := range self.glyphs {
+		glyphRect := image.Rect(rect.x, rect.y, rect.x + rect.width, rect.y + self.cellHeight)
+		if !glyphRect.In(bounds) {
+			return nil, errors.New("text: glyph '" + string(codePoint) + "' out of atlas bounds")
+		}
+		font.glyphs[codePoint] = fontGlyph{
+			image: atlas.SubImage(glyphRect).(*ebiten.Image),
+			advance: rect.advance,
+			offset: rect.offset,
+		}
+	}
+	return font, nil
+}
+
+func parseFontInt(args []string, lo, hi int) (int, error) {
+	if len(args) != 1 { return 0, errors.New("expected 1 argument") }
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < lo || n > hi {
+		return 0, errors.New("invalid value '" + args[0] + "'")
+	}
+	return n, nil
+}
+
+func parseFontChar(str string) (rune, error) {
+	if strings.HasPrefix(str, "U+") && len(str) > 2 {
+		n, err := strconv.ParseUint(str[2 : ], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(n)) { return 0, errors.New("invalid char '" + str + "'") }
+		return rune(n), nil
+	}
+	codePoint, size := utf8.DecodeRuneInString(str)
+	if codePoint == utf8.RuneError || size != len(str) {
+		return 0, errors.New("invalid char '" + str + "'")
+	}
+	return codePoint, nil
+}
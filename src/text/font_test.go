@@ -0,0 +1,130 @@
+package text
+
+import "strings"
+import "testing"
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+const testFontMetrics = `# test font
+atlas test.png
+cell_height 8
+line_top 1
+space_advance 4
+letter_spacing 1
+fallback ?
+
+glyph ? 0 0 3
+glyph A 4 0 3
+glyph V 8 0 3
+glyph U+00C9 12 0 3
+glyph j 16 0 2 advance:1 offset:-1
+kern A V -1
+kern V U+00C9 2
+`
+
+func TestParseFontMetrics(t *testing.T) {
+	metrics, err := parseFontMetrics(strings.NewReader(testFontMetrics))
+	if err != nil { t.Fatal(err) }
+	if metrics.atlas != "test.png" || metrics.cellHeight != 8 || metrics.lineTop != 1 ||
+		metrics.spaceAdvance != 4 || metrics.letterSpacing != 1 || metrics.fallback != '?' {
+		t.Fatalf("unexpected metrics %+v", *metrics)
+	}
+	if len(metrics.glyphs) != 5 { t.Fatalf("expected 5 glyphs, got %d", len(metrics.glyphs)) }
+	expected := fontGlyphRect{ x: 12, y: 0, width: 3, advance: 3, offset: 0 }
+	if metrics.glyphs['É'] != expected {
+		t.Fatalf("got glyph %+v for 'É', expected %+v", metrics.glyphs['É'], expected)
+	}
+	expected = fontGlyphRect{ x: 16, y: 0, width: 2, advance: 1, offset: -1 }
+	if metrics.glyphs['j'] != expected {
+		t.Fatalf("got glyph %+v for 'j', expected %+v", metrics.glyphs['j'], expected)
+	}
+	if metrics.kerning[[2]rune{'A', 'V'}] != -1 || metrics.kerning[[2]rune{'V', 'É'}] != 2 || len(metrics.kerning) != 2 {
+		t.Fatalf("unexpected kerning %v", metrics.kerning)
+	}
+}
+
+func TestParseFontMetricsErrors(t *testing.T) {
+	tests := []struct{
+		name string
+		replace string // line of testFontMetrics to replace
+		with string
+		errMsg string
+	}{
+		{ "missing atlas", "atlas test.png", "", "missing font atlas" },
+		{ "missing cell height", "cell_height 8", "", "missing font cell_height" },
+		{ "missing line top", "line_top 1", "", "missing font line_top" },
+		{ "missing space advance", "space_advance 4", "", "missing font space_advance" },
+		{ "missing letter spacing", "letter_spacing 1", "", "missing font letter_spacing" },
+		{ "missing fallback", "fallback ?", "", "missing font fallback" },
+		{ "missing fallback glyph", "glyph ? 0 0 3", "", "missing glyph for fallback" },
+		{ "duplicated glyph", "glyph V 8 0 3", "glyph A 8 0 3", "line 11: duplicated glyph 'A'" },
+		{ "duplicated U+ glyph", "glyph V 8 0 3", "glyph É 8 0 3", "line 12: duplicated glyph 'U+00C9'" },
+		{ "kerning missing glyph", "kern A V -1", "kern A B -1", "kerning pair uses missing glyph 'B'" },
+		{ "invalid U+ char", "glyph V 8 0 3", "glyph U+D800 8 0 3", "invalid char 'U+D800'" },
+		{ "invalid char", "glyph V 8 0 3", "glyph VV 8 0 3", "invalid char 'VV'" },
+		{ "space glyph", "glyph V 8 0 3", "glyph U+0020 8 0 3", "glyph can't be defined" },
+		{ "zero width", "glyph V 8 0 3", "glyph V 8 0 0", "glyph width must be positive" },
+		{ "missing coordinates", "glyph V 8 0 3", "glyph V 8 0", "glyph expects at least 4 arguments" },
+		{ "negative advance", "glyph V 8 0 3", "glyph V 8 0 3 advance:-1", "negative glyph advance" },
+		{ "unknown option", "glyph V 8 0 3", "glyph V 8 0 3 width:2", "unknown glyph option 'width'" },
+		{ "invalid option", "glyph V 8 0 3", "glyph V 8 0 3 offset", "invalid glyph option 'offset'" },
+		{ "invalid kerning", "kern A V -1", "kern A V x", "invalid kerning 'x'" },
+		{ "unknown statement", "letter_spacing 1", "letter_spacing 1\nbaseline 6", "unknown statement 'baseline'" },
+		{ "out of range", "cell_height 8", "cell_height 0", "invalid value '0'" },
+	}
+
+	for _, test := range tests {
+		if !strings.Contains(testFontMetrics, test.replace + "\n") { panic(test.replace) }
+		source := strings.Replace(testFontMetrics, test.replace + "\n", test.with + "\n", 1)
+		_, err := parseFontMetrics(strings.NewReader(source))
+		if err == nil {
+			t.Errorf("%s: expected error", test.name)
+		} else if !strings.Contains(err.Error(), test.errMsg) {
+			t.Errorf("%s: expected error containing \"%s\", got \"%s\"", test.name, test.errMsg, err.Error())
+		}
+	}
+}
+
+func TestFontLayout(t *testing.T) {
+	metrics, err := parseFontMetrics(strings.NewReader(testFontMetrics))
+	if err != nil { t.Fatal(err) }
+	font, err := metrics.build(ebiten.NewImage(32, 8))
+	if err != nil { t.Fatal(err) }
+
+	type placement struct{ index, x, y int }
+	tests := []struct{
+		line string
+		width int
+		placements []placement
+	}{
+		{ "", 0, nil },
+		{ "A", 3, []placement{{0, 0, -1}} },
+		{ "AA", 7, []placement{{0, 0, -1}, {1, 4, -1}} }, // letter spacing
+		{ "AV", 6, []placement{{0, 0, -1}, {1, 3, -1}} }, // kerning -1
+		{ "VA", 7, []placement{{0, 0, -1}, {1, 4, -1}} }, // kerning is ordered
+		{ "VÉ", 9, []placement{{0, 0, -1}, {1, 6, -1}} }, // kerning +2
+		{ "A A", 10, []placement{{0, 0, -1}, {2, 7, -1}} }, // no spacing around spaces
+		{ "AjA", 9, []placement{{0, 0, -1}, {1, 3, -1}, {2, 6, -1}} }, // advance and offset
+		{ "ß", 3, []placement{{0, 0, -1}} }, // fallback
+	}
+	for _, test := range tests {
+		var placements []placement
+		width := font.layout(test.line, func(glyph *ebiten.Image, index, x, y int) {
+			placements = append(placements, placement{ index, x, y })
+		})
+		if width != test.width {
+			t.Errorf("'%s': got width %d, expected %d", test.line, width, test.width)
+		}
+		if len(placements) != len(test.placements) {
+			t.Errorf("'%s': got placements %v, expected %v", test.line, placements, test.placements)
+			continue
+		}
+		for i, _ := range placements {
+			if placements[i] != test.placements[i] {
+				t.Errorf("'%s': got placements %v, expected %v", test.line, placements, test.placements)
+				break
+			}
+		}
+		if font.layout(test.line, nil) != width { t.Errorf("'%s': width changed without fn", test.line) }
+	}
+}
@@ -0,0 +1,158 @@
+package text
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+import "github.com/tinne26/transition/src/utils"
+import "github.com/tinne26/transition/src/input"
+
+// Key glyphs are drawn as key caps. By default they show the keys of
+// the standard WASD layout, but they can be relabeled with
+// SetKeyGlyphLabel() to match the current bindings instead. Text
+// using the glyphs doesn't need to be recreated, as the glyphs are
+// resolved on each draw.
+
+// The letters shown by the key glyphs when using their
+// original hand-drawn bitmaps.
+var keyGlyphDefaultLabels = map[rune]string{
+	KeyO: "O", KeyI: "I", KeyJ: "J", KeyK: "K", KeyL: "L",
+	KeyA: "A", KeyD: "D", KeyTAB: "TAB", KeyMsgI: "I",
+}
+
+// Original bitmaps of the glyphs that have been relabeled.
+var keyGlyphDefaultBitmaps = make(map[rune]*ebiten.Image, len(keyGlyphDefaultLabels))
+
+// Action key glyphs are also drawn as key caps, but there's one for
+// each input.Action, and their labels must be set explicitly with
+// SetActionKeyLabel(). They are mapped to the private use area of
+// unicode, starting at keyActionBase. Markup key tags ({key:<action>})
+// are resolved to these glyphs.
+const keyActionBase = '\uE000'
+
+var actionKeyLabels [input.NumActions]string
+var actionKeyBitmaps [input.NumActions]*ebiten.Image
+
+func IsKeyGlyph(codePoint rune) bool {
+	if isActionKeyGlyph(codePoint) { return true }
+	_, found := keyGlyphDefaultLabels[codePoint]
+	return found
+}
+
+func isActionKeyGlyph(codePoint rune) bool {
+	return codePoint >= keyActionBase && codePoint < keyActionBase + rune(input.NumActions)
+}
+
+// Returns the glyph showing the key for the given action.
+func ActionKeyGlyph(action input.Action) rune {
+	if int(action) >= input.NumActions { panic(action) }
+	return keyActionBase + rune(action)
+}
+
+// Changes the text shown by the key glyph of the given action. Same
+// rules as SetKeyGlyphLabel(). Until set, action key glyphs show '?'.
+func SetActionKeyLabel(action input.Action, label string) {
+	if int(action) >= input.NumActions { panic(action) }
+	if actionKeyLabels[action] == label && actionKeyBitmaps[action] != nil { return }
+	actionKeyLabels[action] = label
+	actionKeyBitmaps[action] = nil // recreated lazily on keyGlyphBitmap()
+}
+
+// Returns the current bitmap for the given key glyph.
+func keyGlyphBitmap(glyph rune) *ebiten.Image {
+	if !isActionKeyGlyph(glyph) { return pkgBitmaps[glyph] }
+	action := glyph - keyActionBase
+	if actionKeyBitmaps[action] == nil {
+		label := actionKeyLabels[action]
+		if label == "" { label = "?" }
+		actionKeyBitmaps[action] = handDrawnKeyCap(label)
+		if actionKeyBitmaps[action] == nil {
+			mask := keyCapMask(label)
+			actionKeyBitmaps[action] = utils.RawAlphaMaskToWhiteMask(mask.width, mask.data)
+		}
+	}
+	return actionKeyBitmaps[action]
+}
+
+// Returns the original bitmap of the key cap glyph with the given
+// label, or nil if there's none.
+func handDrawnKeyCap(label string) *ebiten.Image {
+	for glyph, defaultLabel := range keyGlyphDefaultLabels {
+		if glyph == KeyMsgI || defaultLabel != label { continue }
+		bitmap, relabeled := keyGlyphDefaultBitmaps[glyph]
+		if !relabeled { bitmap = pkgBitmaps[glyph] }
+		return bitmap
+	}
+	return nil
+}
+
+// Changes the text shown by the given key glyph. The label should
+// use uppercase letters; code points without a bitmap are drawn
+// as '?'. KeyMsgI is special, as it's drawn without a key cap and
+// only has space for a single letter ('?' is used for longer labels).
+func SetKeyGlyphLabel(glyph rune, label string) {
+	defaultLabel, found := keyGlyphDefaultLabels[glyph]
+	if !found { panic(glyph) }
+	_, hasDefault := keyGlyphDefaultBitmaps[glyph]
+	if !hasDefault { keyGlyphDefaultBitmaps[glyph] = pkgBitmaps[glyph] }
+	if label == defaultLabel {
+		pkgBitmaps[glyph] = keyGlyphDefaultBitmaps[glyph]
+		return
+	}
+
+	var mask rawMask
+	if glyph == KeyMsgI {
+		mask = labelMask(label)
+		if len([]rune(label)) != 1 { mask = pkgMasks['?'] }
+	} else {
+		mask = keyCapMask(label)
+	}
+	pkgBitmaps[glyph] = utils.RawAlphaMaskToWhiteMask(mask.width, mask.data)
+}
+
+// Restores the original bitmaps for all key glyphs.
+func ResetKeyGlyphs() {
+	for glyph, bitmap := range keyGlyphDefaultBitmaps {
+		pkgBitmaps[glyph] = bitmap
+	}
+}
+
+// Creates an inverted mask for the label, surrounded by a
+// one pixel border, like the hand-drawn key glyphs.
+func keyCapMask(label string) rawMask {
+	inner := labelMask(label)
+	width := inner.width + 2
+	data := make([]byte, width*7)
+	for row := 0; row < 7; row++ {
+		for col := 0; col < width; col++ {
+			value := byte(1)
+			if row > 0 && row < 6 && col > 0 && col < width - 1 {
+				value = 1 - inner.data[row*inner.width + col - 1]
+			}
+			data[row*width + col] = value
+		}
+	}
+	return rawMask{ width: width, data: data }
+}
+
+// Joins the masks for each code point in the label with
+// one pixel of separation, like DrawLine() would.
+func labelMask(label string) rawMask {
+	var masks []rawMask
+	width := -1
+	for _, codePoint := range label {
+		mask, found := pkgMasks[codePoint]
+		if !found || IsKeyGlyph(codePoint) { mask = pkgMasks['?'] }
+		masks = append(masks, mask)
+		width += mask.width + 1
+	}
+	if width <= 0 { return pkgMasks['?'] }
+
+	data := make([]byte, width*7)
+	x := 0
+	for _, mask := range masks {
+		for row := 0; row < 7; row++ {
+			copy(data[row*width + x : ], mask.data[row*mask.width : (row + 1)*mask.width])
+		}
+		x += mask.width + 1
+	}
+	return rawMask{ width: width, data: data }
+}
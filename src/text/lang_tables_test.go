@@ -0,0 +1,80 @@
+package text
+
+import "fmt"
+import "strings"
+import "testing"
+
+import "github.com/tinne26/transition/src/lang"
+
+// Every language must define exactly the same string IDs as the
+// fallback language, all strings must have valid markup, and all
+// strings must fit where they are shown with the game font: "msg."
+// strings can't take more lines than a message box page, and raw
+// lines can't be wider than the screen. "tip." and "dlg." strings
+// are paged, so they can have any length.
+
+const maxRawLineWidth = 640 - 2*20
+
+func TestLangTables(t *testing.T) {
+	loadTestFont(t)
+	codes, err := lang.Available(testFS)
+	if err != nil { t.Fatal(err) }
+	fallback, err := lang.LoadTable(testFS, lang.Fallback)
+	if err != nil { t.Fatal(err) }
+
+	for _, code := range codes {
+		table, err := lang.LoadTable(testFS, code)
+		if err != nil { t.Fatal(err) }
+		for _, problem := range checkTable(table, fallback) {
+			t.Errorf("%s: %s", code, problem)
+		}
+	}
+}
+
+func checkTable(table, fallback *lang.Table) []string {
+	var problems []string
+	for _, id := range fallback.IDs() {
+		if _, found := table.Lookup(id); !found {
+			problems = append(problems, "missing string '" + id + "'")
+		}
+	}
+
+	for _, id := range table.IDs() {
+		if _, found := fallback.Lookup(id); !found {
+			problems = append(problems, "unknown string '" + id + "'")
+			continue
+		}
+		str, _ := table.Lookup(id)
+		problem := checkString(id, str)
+		if problem != "" { problems = append(problems, id + ": " + problem) }
+	}
+	return problems
+}
+
+func checkString(id string, str string) string {
+	if strings.HasSuffix(id, "]") { // plural, measure with a wide count
+		str = strings.ReplaceAll(str, "{n}", "88")
+	}
+	for _, line := range strings.Split(str, "\n") {
+		err := ValidateMarkup(line)
+		if err != nil { return err.Error() }
+	}
+
+	switch {
+	case strings.HasPrefix(id, "msg."):
+		lines := WrapLines(str, MsgWrapWidth)
+		if len(lines) > MsgPageLines {
+			return fmt.Sprintf("takes %d lines, but message boxes only fit %d", len(lines), MsgPageLines)
+		}
+	case strings.HasPrefix(id, "tip."), strings.HasPrefix(id, "dlg."):
+		// paged, any length is fine
+	default:
+		for _, line := range strings.Split(str, "\n") {
+			width := measureLocalizedLine(line)
+			if width > maxRawLineWidth {
+				return fmt.Sprintf("line '%s' is %dpx wide (max is %dpx)", line, width, maxRawLineWidth)
+			}
+		}
+	}
+	return ""
+}
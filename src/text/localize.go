@@ -0,0 +1,27 @@
+package text
+
+import "strings"
+
+import "github.com/tinne26/transition/src/lang"
+
+// Strings starting with '@' are references to localized strings
+// (e.g. "@tip.move", see the lang package). References are resolved
+// whenever the strings are drawn or measured, so text doesn't need to
+// be recreated when the language changes. Use "@@" for strings that
+// must start with a literal '@'.
+
+func Localize(str string) string {
+	if !strings.HasPrefix(str, "@") { return str }
+	if strings.HasPrefix(str, "@@") { return str[1 : ] }
+	return lang.Get(str[1 : ])
+}
+
+// Localizes the given lines. Localized strings with line
+// breaks are expanded into multiple lines.
+func LocalizeLines(lines []string) []string {
+	var localized []string
+	for _, line := range lines {
+		localized = append(localized, strings.Split(Localize(line), "\n")...)
+	}
+	return localized
+}
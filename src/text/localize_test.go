@@ -0,0 +1,48 @@
+package text
+
+import "os"
+import "testing"
+import "image/color"
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// The repository root, where the assets are.
+var testFS = os.DirFS("../..")
+
+func loadTestFont(t *testing.T) {
+	t.Helper()
+	if pkgFont != pkgBuiltinFont { return } // already loaded
+	err := LoadAndSetFont(testFS, "assets/fonts/main.txt")
+	if err != nil { t.Fatal(err) }
+	if !pkgFont.HasGlyph('É') { t.Fatal("main font loaded without 'É'") }
+}
+
+// "@@" strings must be localized exactly once: a second pass would
+// turn them into references (and no language is loaded here, so
+// that would panic).
+func TestLiteralAtStrings(t *testing.T) {
+	loadTestFont(t)
+	canvas := ebiten.NewImage(640, 360)
+	white := color.RGBA{255, 255, 255, 255}
+	const Literal = "@@AT SIGN"
+
+	if MeasureLineWidth(Literal) != measureLocalizedLine("@AT SIGN") {
+		t.Fatalf("'%s' not measured as '@AT SIGN'", Literal)
+	}
+	DrawLine(canvas, Literal, 0, 0, white)
+	CenterRawDraw(canvas, []string{Literal, "PLAIN"}, white)
+
+	for _, msg := range []*Message{
+		NewMsg1(Literal, white),
+		NewWrappedMsg(Literal, white),
+	} {
+		lines := msg.Lines()
+		if len(lines) != 1 || lines[0] != "@AT SIGN" {
+			t.Fatalf("expected lines [@AT SIGN], got %q", lines)
+		}
+		if msg.NumPageGlyphs() != countLineGlyphs("@AT SIGN") {
+			t.Fatalf("got %d page glyphs for '%s'", msg.NumPageGlyphs(), Literal)
+		}
+		Draw(canvas, 320, 180, msg)
+	}
+}
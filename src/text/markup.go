@@ -0,0 +1,155 @@
+package text
+
+import "errors"
+import "strings"
+import "image/color"
+import "unicode/utf8"
+
+import "github.com/tinne26/transition/src/input"
+import "github.com/tinne26/transition/src/game/clr"
+
+// All text drawn and measured by this package can use a small markup:
+//   {key:<action>}              key cap for the key bound to the action
+//   {color:<name>}...{/color}   text color from the clr palette
+//   {{                          a literal '{'
+// Actions use the names from input.Action.String(), and a few short
+// aliases ("left", "right", "reverse"). Color spans can be nested.
+// Invalid markup panics when drawn, so text coming from files should
+// be checked first with ValidateMarkup().
+
+var markupKeyAliases = map[string]input.Action{
+	"left": input.ActionMoveLeft,
+	"right": input.ActionMoveRight,
+	"reverse": input.ActionOutReverse,
+}
+
+// A line with the markup resolved: key tags are replaced by action
+// key glyphs, and color spans refer to byte ranges of the text.
+type markupLine struct {
+	text string
+	spans []markupSpan
+}
+
+type markupSpan struct {
+	start, end int
+	color color.RGBA
+}
+
+// Returns the color for the code point at the given byte index.
+// Nested spans come after their parents, so the last match wins.
+func (self *markupLine) colorAt(index int, defaultColor color.RGBA) color.RGBA {
+	rgba := defaultColor
+	for _, span := range self.spans {
+		if index >= span.start && index < span.end { rgba = span.color }
+	}
+	return rgba
+}
+
+func ValidateMarkup(str string) error {
+	_, err := parseMarkup(str)
+	return err
+}
+
+func mustParseMarkup(str string) markupLine {
+	line, err := parseMarkup(str)
+	if err != nil { panic(err) }
+	return line
+}
+
+func parseMarkup(str string) (markupLine, error) {
+	return parseMarkupFragment(str, false)
+}
+
+// Like parseMarkup(), but if lenient is true, unmatched closing tags
+// are ignored. Used to measure fragments of lines while wrapping.
+func parseMarkupFragment(str string, lenient bool) (markupLine, error) {
+	if strings.IndexByte(str, '{') == -1 { return markupLine{ text: str }, nil }
+
+	var builder strings.Builder
+	var spans []markupSpan
+	var openSpans []int // indices into spans
+	for index := 0; index < len(str); {
+		token, err := nextMarkupToken(str, index)
+		if err != nil { return markupLine{}, err }
+		index = token.end
+		if token.tag == "" {
+			builder.WriteRune(token.codePoint)
+			continue
+		}
+
+		name, arg, _ := strings.Cut(token.tag, ":")
+		switch name {
+		case "key":
+			action, found := markupKeyAliases[arg]
+			if !found {
+				action, found = input.ActionFromName(arg)
+				if !found { return markupLine{}, errors.New("text: unknown markup key action '" + arg + "'") }
+			}
+			builder.WriteRune(ActionKeyGlyph(action))
+		case "color":
+			rgba, found := clr.ByName(arg)
+			if !found { return markupLine{}, errors.New("text: unknown markup color '" + arg + "'") }
+			openSpans = append(openSpans, len(spans))
+			spans = append(spans, markupSpan{ start: builder.Len(), end: -1, color: rgba })
+		case "/color":
+			if len(openSpans) == 0 {
+				if lenient { continue }
+				return markupLine{}, errors.New("text: unmatched markup {/color}")
+			}
+			spans[openSpans[len(openSpans) - 1]].end = builder.Len()
+			openSpans = openSpans[ : len(openSpans) - 1]
+		default:
+			return markupLine{}, errors.New("text: unknown markup tag '{" + token.tag + "}'")
+		}
+	}
+
+	// unclosed spans extend until the end of the line
+	for _, spanIndex := range openSpans {
+		spans[spanIndex].end = builder.Len()
+	}
+	return markupLine{ text: builder.String(), spans: spans }, nil
+}
+
+// A tag or a single visible code point of the markup source.
+type markupToken struct {
+	end int // byte index after the token
+	tag string // "" for code points
+	codePoint rune
+}
+
+func nextMarkupToken(str string, index int) (markupToken, error) {
+	if str[index] != '{' {
+		codePoint, size := utf8.DecodeRuneInString(str[index : ])
+		return markupToken{ end: index + size, codePoint: codePoint }, nil
+	}
+	if strings.HasPrefix(str[index : ], "{{") {
+		return markupToken{ end: index + 2, codePoint: '{' }, nil
+	}
+	closing := strings.IndexByte(str[index : ], '}')
+	if closing == -1 { return markupToken{}, errors.New("text: unterminated markup tag") }
+	return markupToken{ end: index + closing + 1, tag: str[index + 1 : index + closing] }, nil
+}
+
+// Wrapping can split color spans across lines. This closes the spans
+// still open at the end of each line and reopens them at the start
+// of the next one, so each line can be drawn on its own.
+func balanceMarkupLines(lines []string) []string {
+	var openTags []string
+	for i, line := range lines {
+		if len(openTags) == 0 && strings.IndexByte(line, '{') == -1 { continue }
+
+		prefix := strings.Join(openTags, "")
+		for index := 0; index < len(line); {
+			token, err := nextMarkupToken(line, index)
+			if err != nil { panic(err) }
+			index = token.end
+			if strings.HasPrefix(token.tag, "color:") {
+				openTags = append(openTags, "{" + token.tag + "}")
+			} else if token.tag == "/color" && len(openTags) > 0 {
+				openTags = openTags[ : len(openTags) - 1]
+			}
+		}
+		lines[i] = prefix + line + strings.Repeat("{/color}", len(openTags))
+	}
+	return lines
+}
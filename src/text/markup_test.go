@@ -0,0 +1,115 @@
+package text
+
+import "strings"
+import "testing"
+import "image/color"
+
+import "github.com/tinne26/transition/src/input"
+import "github.com/tinne26/transition/src/game/clr"
+
+func TestParseMarkup(t *testing.T) {
+	jump, left := string(ActionKeyGlyph(input.ActionJump)), string(ActionKeyGlyph(input.ActionMoveLeft))
+	tests := []struct{
+		str string
+		text string
+		spans []markupSpan
+	}{
+		{ "PLAIN TEXT", "PLAIN TEXT", nil },
+		{ "{{A}", "{A}", nil },
+		{ "{{{{", "{{", nil },
+		{ "PRESS {key:jump} OR {key:left}", "PRESS " + jump + " OR " + left, nil },
+		{ "A {color:horns}B{/color} C", "A B C", []markupSpan{{2, 3, clr.HornsText}} },
+		{ "{color:wings}A{color:horns}B{/color}C{/color}", "ABC", []markupSpan{{0, 3, clr.WingsText}, {1, 2, clr.HornsText}} },
+		{ "{color:dark}AB", "AB", []markupSpan{{0, 2, clr.Dark}} }, // unclosed
+		{ "É{color:dark}É{/color}", "ÉÉ", []markupSpan{{2, 4, clr.Dark}} }, // byte ranges
+	}
+	for _, test := range tests {
+		line, err := parseMarkup(test.str)
+		if err != nil { t.Errorf("%q: %s", test.str, err); continue }
+		if line.text != test.text { t.Errorf("%q: got text %q, expected %q", test.str, line.text, test.text) }
+		if !sameSpans(line.spans, test.spans) {
+			t.Errorf("%q: got spans %v, expected %v", test.str, line.spans, test.spans)
+		}
+	}
+
+	// nested spans take precedence over their parents
+	line := mustParseMarkup("{color:wings}A{color:horns}B{/color}C{/color}D")
+	white := textTestColor
+	for i, expected := range []color.RGBA{clr.WingsText, clr.HornsText, clr.WingsText, white} {
+		if line.colorAt(i, white) != expected { t.Errorf("color at %d is %v, expected %v", i, line.colorAt(i, white), expected) }
+	}
+}
+
+func TestParseMarkupErrors(t *testing.T) {
+	tests := []struct{ str, errMsg string }{
+		{ "{bold}A", "unknown markup tag '{bold}'" },
+		{ "{key:fly}", "unknown markup key action 'fly'" },
+		{ "{color:pink}A{/color}", "unknown markup color 'pink'" },
+		{ "{color:horns_text}A{/color}", "unknown markup color 'horns_text'" },
+		{ "{color:dark A", "unterminated markup tag" },
+		{ "A{/color}", "unmatched markup {/color}" },
+	}
+	for _, test := range tests {
+		err := ValidateMarkup(test.str)
+		if err == nil || !strings.Contains(err.Error(), test.errMsg) {
+			t.Errorf("%q: expected error containing \"%s\", got %v", test.str, test.errMsg, err)
+		}
+	}
+
+	// unmatched closing tags are tolerated on fragments
+	line, err := parseMarkupFragment("A{/color}B", true)
+	if err != nil || line.text != "AB" || len(line.spans) != 0 {
+		t.Fatalf("got %+v, %v for lenient fragment", line, err)
+	}
+}
+
+func TestBalanceMarkupLines(t *testing.T) {
+	tests := []struct{ lines, expected []string }{
+		{ []string{"A", "B"}, []string{"A", "B"} },
+		{
+			[]string{"A {color:horns}B", "C{/color} D"},
+			[]string{"A {color:horns}B{/color}", "{color:horns}C{/color} D"},
+		},
+		{
+			[]string{"{color:wings}A {color:horns}B", "C", "D{/color} E{/color}", "F"},
+			[]string{
+				"{color:wings}A {color:horns}B{/color}{/color}",
+				"{color:wings}{color:horns}C{/color}{/color}",
+				"{color:wings}{color:horns}D{/color} E{/color}",
+				"F",
+			},
+		},
+		{ []string{"{{color:horns}", "A"}, []string{"{{color:horns}", "A"} }, // escaped
+	}
+	for _, test := range tests {
+		input := append([]string(nil), test.lines...)
+		lines := balanceMarkupLines(input)
+		if !sameLines(lines, test.expected) {
+			t.Errorf("balanceMarkupLines(%q) = %q, expected %q", test.lines, lines, test.expected)
+		}
+		for _, line := range lines {
+			err := ValidateMarkup(line)
+			if err != nil { t.Errorf("%q: %s", line, err) }
+		}
+	}
+}
+
+func TestWrapColorSpans(t *testing.T) {
+	maxWidth := measureFragment("AAAA")
+	lines := WrapLines("{color:horns}AAAA BBBB{/color} CC", maxWidth)
+	expected := []string{"{color:horns}AAAA{/color}", "{color:horns}BBBB{/color}", "CC"}
+	if !sameLines(lines, expected) { t.Fatalf("got %q, expected %q", lines, expected) }
+
+	// long words are split without breaking the tags
+	lines = WrapLines("{color:horns}AAAAAA{/color}", maxWidth)
+	expected = []string{"{color:horns}AAAA{/color}", "{color:horns}AA{/color}"}
+	if !sameLines(lines, expected) { t.Fatalf("got %q, expected %q", lines, expected) }
+}
+
+func sameSpans(a, b []markupSpan) bool {
+	if len(a) != len(b) { return false }
+	for i, _ := range a {
+		if a[i] != b[i] { return false }
+	}
+	return true
+}
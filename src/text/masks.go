@@ -5,12 +5,46 @@ import "github.com/hajimehoshi/ebiten/v2"
 import "github.com/tinne26/transition/src/utils"
 
 func init() {
-	for _, bitmap := range pkgBitmaps {
-		bounds := bitmap.Bounds()
-		if bounds.Dx() <= 0 || bounds.Dy() != 7 { panic(bounds) }
+	for codePoint, mask := range pkgMasks {
+		if mask.width <= 0 || len(mask.data) != mask.width*7 { panic(codePoint) }
+		pkgBitmaps[codePoint] = utils.RawAlphaMaskToWhiteMask(mask.width, mask.data)
 	}
+	pkgBuiltinFont = newBuiltinFont()
+	pkgFont = pkgBuiltinFont
 }
 
+// The built-in font only has uppercase letters and some punctuation.
+// It's used until a proper font is loaded with LoadAndSetFont().
+var pkgBuiltinFont *Font
+
+func newBuiltinFont() *Font {
+	font := &Font{
+		glyphs: make(map[rune]fontGlyph, len(pkgBitmaps)),
+		kerning: make(map[[2]rune]int),
+		spaceAdvance: 4,
+		letterSpacing: 1,
+		fallback: '?',
+	}
+	for codePoint, bitmap := range pkgBitmaps {
+		if IsKeyGlyph(codePoint) { continue }
+		font.glyphs[codePoint] = fontGlyph{ image: bitmap, advance: bitmap.Bounds().Dx() }
+	}
+	return font
+}
+
+// Raw alpha masks are kept around so we can compose new
+// glyphs from them later (see SetKeyGlyphLabel()).
+type rawMask struct {
+	width int
+	data []byte
+}
+
+func newRawMask(width int, data []byte) rawMask {
+	return rawMask{ width: width, data: data }
+}
+
+var pkgBitmaps = make(map[rune]*ebiten.Image, len(pkgMasks))
+
 const KeyO   = '\x01'
 const KeyI   = '\x02'
 const KeyJ   = '\x03'
@@ -22,9 +56,9 @@ const KeyTAB = '\x09'
 
 const KeyMsgI = '\x10'
 
-var pkgBitmaps = map[rune]*ebiten.Image{
+var pkgMasks = map[rune]rawMask{
 	// --- special hacks ----
-	KeyTAB: utils.RawAlphaMaskToWhiteMask(13, []byte{
+	KeyTAB: newRawMask(13, []byte{
 		1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
 		1, 0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 1, 1,
 		1, 1, 0, 1, 1, 0, 1, 0, 1, 0, 1, 0, 1,
@@ -33,7 +67,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 1, 0, 1, 1, 0, 1, 0, 1, 0, 0, 1, 1,
 		1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
 	}),
-	KeyO: utils.RawAlphaMaskToWhiteMask(6, []byte{
+	KeyO: newRawMask(6, []byte{
 		1, 1, 1, 1, 1, 1,
 		1, 1, 0, 0, 1, 1,
 		1, 0, 1, 1, 0, 1,
@@ -42,7 +76,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 1, 0, 0, 1, 1,
 		1, 1, 1, 1, 1, 1,
 	}),
-	KeyI: utils.RawAlphaMaskToWhiteMask(5, []byte{
+	KeyI: newRawMask(5, []byte{
 		1, 1, 1, 1, 1,
 		1, 0, 0, 0, 1,
 		1, 1, 0, 1, 1,
@@ -51,7 +85,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 0, 0, 0, 1,
 		1, 1, 1, 1, 1,
 	}),
-	KeyK: utils.RawAlphaMaskToWhiteMask(6, []byte{
+	KeyK: newRawMask(6, []byte{
 		1, 1, 1, 1, 1, 1,
 		1, 0, 1, 1, 0, 1,
 		1, 0, 1, 0, 1, 1,
@@ -60,7 +94,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 0, 1, 1, 0, 1,
 		1, 1, 1, 1, 1, 1,
 	}),
-	KeyL: utils.RawAlphaMaskToWhiteMask(5, []byte{
+	KeyL: newRawMask(5, []byte{
 		1, 1, 1, 1, 1,
 		1, 0, 1, 1, 1,
 		1, 0, 1, 1, 1,
@@ -69,7 +103,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 0, 0, 0, 1,
 		1, 1, 1, 1, 1,
 	}),
-	KeyJ: utils.RawAlphaMaskToWhiteMask(6, []byte{
+	KeyJ: newRawMask(6, []byte{
 		1, 1, 1, 1, 1, 1,
 		1, 1, 0, 0, 0, 1,
 		1, 1, 1, 0, 1, 1,
@@ -78,7 +112,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 1, 0, 1, 1, 1,
 		1, 1, 1, 1, 1, 1,
 	}),
-	KeyD: utils.RawAlphaMaskToWhiteMask(6, []byte{
+	KeyD: newRawMask(6, []byte{
 		1, 1, 1, 1, 1, 1,
 		1, 0, 0, 0, 1, 1,
 		1, 0, 1, 1, 0, 1,
@@ -87,7 +121,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 0, 0, 0, 1, 1,
 		1, 1, 1, 1, 1, 1,
 	}),
-	KeyA: utils.RawAlphaMaskToWhiteMask(5, []byte{
+	KeyA: newRawMask(5, []byte{
 		1, 1, 1, 1, 1,
 		1, 0, 0, 0, 1,
 		1, 0, 1, 0, 1,
@@ -96,7 +130,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 0, 1, 0, 1,
 		1, 1, 1, 1, 1,
 	}),
-	KeyMsgI: utils.RawAlphaMaskToWhiteMask(3, []byte{
+	KeyMsgI: newRawMask(3, []byte{
 		0, 0, 0,
 		1, 1, 1,
 		0, 1, 0,
@@ -107,7 +141,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 	}),
 
 	// --- main alphabet ---
-	'A': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'A': newRawMask(3, []byte{
 		0, 0, 0,
 		1, 1, 1,
 		1, 0, 1,
@@ -116,7 +150,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 0, 1,
 		0, 0, 0,
 	}),
-	'B': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'B': newRawMask(3, []byte{
 		0, 0, 0,
 		1, 1, 0,
 		1, 0, 1,
@@ -125,7 +159,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 1, 0,
 		0, 0, 0,
 	}),
-	'C': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'C': newRawMask(3, []byte{
 		0, 0, 0,
 		1, 1, 1,
 		1, 0, 0,
@@ -134,7 +168,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 1, 1,
 		0, 0, 0,
 	}),
-	'D': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'D': newRawMask(3, []byte{
 		0, 0, 0,
 		1, 1, 0,
 		1, 0, 1,
@@ -143,7 +177,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 1, 0,
 		0, 0, 0,
 	}),
-	'E': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'E': newRawMask(3, []byte{
 		0, 0, 0,
 		1, 1, 1,
 		1, 0, 0,
@@ -152,7 +186,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 1, 1,
 		0, 0, 0,
 	}),
-	'F': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'F': newRawMask(3, []byte{
 		0, 0, 0,
 		1, 1, 1,
 		1, 0, 0,
@@ -161,7 +195,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 0, 0,
 		0, 0, 0,
 	}),
-	'G': utils.RawAlphaMaskToWhiteMask(4, []byte{
+	'G': newRawMask(4, []byte{
 		0, 0, 0, 0,
 		1, 1, 1, 1,
 		1, 0, 0, 0,
@@ -170,7 +204,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 1, 1, 1,
 		0, 0, 0, 0,
 	}),
-	'H': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'H': newRawMask(3, []byte{
 		0, 0, 0,
 		1, 0, 1,
 		1, 0, 1,
@@ -179,7 +213,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 0, 1,
 		0, 0, 0,
 	}),
-	'I': utils.RawAlphaMaskToWhiteMask(1, []byte{
+	'I': newRawMask(1, []byte{
 		0,
 		1,
 		1,
@@ -188,7 +222,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1,
 		0,
 	}),
-	'J': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'J': newRawMask(3, []byte{
 		0, 0, 0,
 		0, 0, 1,
 		0, 0, 1,
@@ -197,7 +231,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		0, 1, 0,
 		0, 0, 0,
 	}),
-	'K': utils.RawAlphaMaskToWhiteMask(4, []byte{
+	'K': newRawMask(4, []byte{
 		0, 0, 0, 0,
 		1, 0, 0, 1,
 		1, 0, 1, 0,
@@ -206,7 +240,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 0, 0, 1,
 		0, 0, 0, 0,
 	}),
-	'L': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'L': newRawMask(3, []byte{
 		0, 0, 0,
 		1, 0, 0,
 		1, 0, 0,
@@ -215,7 +249,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 1, 1,
 		0, 0, 0,
 	}),
-	'M': utils.RawAlphaMaskToWhiteMask(5, []byte{
+	'M': newRawMask(5, []byte{
 		0, 0, 0, 0, 0,
 		1, 1, 0, 1, 1,
 		1, 1, 0, 1, 1,
@@ -224,7 +258,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 0, 0, 0, 1,
 		0, 0, 0, 0, 0,
 	}),
-	'N': utils.RawAlphaMaskToWhiteMask(4, []byte{
+	'N': newRawMask(4, []byte{
 		0, 0, 0, 0,
 		1, 0, 0, 1,
 		1, 1, 0, 1,
@@ -233,7 +267,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 0, 0, 1,
 		0, 0, 0, 0,
 	}),
-	'O': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'O': newRawMask(3, []byte{
 		0, 0, 0,
 		1, 1, 1,
 		1, 0, 1,
@@ -242,7 +276,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 1, 1,
 		0, 0, 0,
 	}),
-	'P': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'P': newRawMask(3, []byte{
 		0, 0, 0,
 		1, 1, 1,
 		1, 0, 1,
@@ -251,7 +285,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 0, 0,
 		0, 0, 0,
 	}),
-	'Q': utils.RawAlphaMaskToWhiteMask(4, []byte{
+	'Q': newRawMask(4, []byte{
 		0, 0, 0, 0,
 		1, 1, 1, 1,
 		1, 0, 0, 1,
@@ -260,7 +294,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 1, 1, 1,
 		0, 0, 1, 0,
 	}),
-	'R': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'R': newRawMask(3, []byte{
 		0, 0, 0,
 		1, 1, 1,
 		1, 0, 1,
@@ -269,7 +303,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 0, 1,
 		0, 0, 0,
 	}),
-	'S': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'S': newRawMask(3, []byte{
 		0, 0, 0,
 		1, 1, 1,
 		1, 0, 0,
@@ -278,7 +312,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 1, 1,
 		0, 0, 0,
 	}),
-	'T': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'T': newRawMask(3, []byte{
 		0, 0, 0,
 		1, 1, 1,
 		0, 1, 0,
@@ -287,7 +321,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		0, 1, 0,
 		0, 0, 0,
 	}),
-	'U': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'U': newRawMask(3, []byte{
 		0, 0, 0,
 		1, 0, 1,
 		1, 0, 1,
@@ -296,7 +330,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 1, 1,
 		0, 0, 0,
 	}),
-	'V': utils.RawAlphaMaskToWhiteMask(5, []byte{
+	'V': newRawMask(5, []byte{
 		0, 0, 0, 0, 0,
 		1, 0, 0, 0, 1,
 		1, 0, 0, 0, 1,
@@ -305,7 +339,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		0, 0, 1, 0, 0,
 		0, 0, 0, 0, 0,
 	}),
-	'W': utils.RawAlphaMaskToWhiteMask(5, []byte{
+	'W': newRawMask(5, []byte{
 		0, 0, 0, 0, 0,
 		1, 0, 0, 0, 1,
 		1, 0, 0, 0, 1,
@@ -314,7 +348,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		0, 1, 0, 1, 0,
 		0, 0, 0, 0, 0,
 	}),
-	'X': utils.RawAlphaMaskToWhiteMask(5, []byte{
+	'X': newRawMask(5, []byte{
 		0, 0, 0, 0, 0,
 		1, 0, 0, 0, 1,
 		0, 1, 0, 1, 0,
@@ -323,7 +357,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 0, 0, 0, 1,
 		0, 0, 0, 0, 0,
 	}),
-	'Y': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'Y': newRawMask(3, []byte{
 		0, 0, 0,
 		1, 0, 1,
 		1, 0, 1,
@@ -332,7 +366,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		0, 1, 0,
 		0, 0, 0,
 	}),
-	'Z': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'Z': newRawMask(3, []byte{
 		0, 0, 0,
 		1, 1, 1,
 		0, 0, 1,
@@ -344,7 +378,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 	// ---- symbols and punctuation ----
 	// Note: space is special and only shifts the
 	//       position 4 pixels forwards.
-	'.': utils.RawAlphaMaskToWhiteMask(1, []byte{
+	'.': newRawMask(1, []byte{
 		0,
 		0,
 		0,
@@ -353,7 +387,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1,
 		0,
 	}),
-	',': utils.RawAlphaMaskToWhiteMask(1, []byte{
+	',': newRawMask(1, []byte{
 		0,
 		0,
 		0,
@@ -362,7 +396,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1,
 		1,
 	}),
-	':': utils.RawAlphaMaskToWhiteMask(1, []byte{
+	':': newRawMask(1, []byte{
 		0,
 		0,
 		1,
@@ -371,7 +405,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		0,
 		0,
 	}),
-	';': utils.RawAlphaMaskToWhiteMask(2, []byte{
+	';': newRawMask(2, []byte{
 		0, 0,
 		0, 0,
 		0, 1,
@@ -380,7 +414,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		0, 1,
 		1, 0,
 	}),
-	'!': utils.RawAlphaMaskToWhiteMask(1, []byte{
+	'!': newRawMask(1, []byte{
 		0,
 		1,
 		1,
@@ -389,7 +423,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1,
 		0,
 	}),
-	'?': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'?': newRawMask(3, []byte{
 		0, 0, 0,
 		1, 1, 0,
 		0, 0, 1,
@@ -398,7 +432,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		0, 1, 0,
 		0, 0, 0,
 	}),
-	'\'': utils.RawAlphaMaskToWhiteMask(1, []byte{
+	'\'': newRawMask(1, []byte{
 		0,
 		1,
 		1,
@@ -407,7 +441,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		0,
 		0,
 	}),
-	'(': utils.RawAlphaMaskToWhiteMask(2, []byte{
+	'(': newRawMask(2, []byte{
 		0, 1,
 		1, 0,
 		1, 0,
@@ -416,7 +450,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 0,
 		0, 1,
 	}),
-	')': utils.RawAlphaMaskToWhiteMask(2, []byte{
+	')': newRawMask(2, []byte{
 		1, 0,
 		0, 1,
 		0, 1,
@@ -425,7 +459,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		0, 1,
 		1, 0,
 	}),
-	'[': utils.RawAlphaMaskToWhiteMask(2, []byte{
+	'[': newRawMask(2, []byte{
 		1, 1,
 		1, 0,
 		1, 0,
@@ -434,7 +468,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		1, 0,
 		1, 1,
 	}),
-	']': utils.RawAlphaMaskToWhiteMask(2, []byte{
+	']': newRawMask(2, []byte{
 		1, 1,
 		0, 1,
 		0, 1,
@@ -443,7 +477,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		0, 1,
 		1, 1,
 	}),
-	'"': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'"': newRawMask(3, []byte{
 		0, 0, 0,
 		1, 0, 1,
 		1, 0, 1,
@@ -452,7 +486,7 @@ var pkgBitmaps = map[rune]*ebiten.Image{
 		0, 0, 0,
 		0, 0, 0,
 	}),
-	'_': utils.RawAlphaMaskToWhiteMask(3, []byte{
+	'_': newRawMask(3, []byte{
 		0, 0, 0,
 		0, 0, 0,
 		0, 0, 0,
@@ -2,18 +2,190 @@ package text
 
 import "image/color"
 
+import "github.com/tinne26/transition/src/lang"
+
+// Max number of lines shown at once in a message box.
+const MsgPageLines = 2
+
+// Default max line width for wrapped messages, in pixels.
+const MsgWrapWidth = 240
+
+// Messages can be created from one or two explicit lines (NewMsg1(),
+// NewMsg2() and similar), or from arbitrary text that gets wrapped
+// to a max width and split into pages of up to MsgPageLines lines
+// (NewWrappedMsg() and similar). Wrapped messages show one page at a
+// time, and NextPage() moves to the next one.
+//
+// Messages can also be revealed progressively, glyph by glyph, with
+// SetRevealedGlyphs(). The box size doesn't change while revealing.
 type Message struct {
 	FirstLine string
 	SecondLine string // use "" if empty
 	Color color.RGBA
 	IsDialogue bool
 	IsSkippable bool
+	Speaker string // dialogue messages only, "" if none
+
+	// wrapped messages only
+	text string
+	wrapWidth int
+	unpaged bool
+	pages [][]string // laid out lazily, see layout()
+	layoutFont *Font
+	layoutLangRevision int
+	page int
+
+	revealedGlyphs int // only used if isPartiallyRevealed
+	isPartiallyRevealed bool
 }
 
 func (self *Message) HasTwoLines() bool {
 	return self.SecondLine != ""
 }
 
+func (self *Message) IsWrapped() bool {
+	return self.wrapWidth > 0
+}
+
+// Returns the original text of a wrapped message (not localized).
+func (self *Message) Text() string {
+	return self.text
+}
+
+// Changes the max line width of a wrapped message and goes
+// back to the first page.
+func (self *Message) SetWrapWidth(width int) {
+	if !self.IsWrapped() { panic("can't set wrap width on non-wrapped message") }
+	if width <= 0 { panic("wrap width must be positive") }
+	self.wrapWidth = width
+	self.pages = nil
+	self.page = 0
+}
+
+// Shows all the lines of a wrapped message at once instead of
+// splitting them into pages.
+func (self *Message) DisablePaging() {
+	if !self.IsWrapped() { panic("can't disable paging on non-wrapped message") }
+	self.unpaged = true
+	self.pages = nil
+	self.page = 0
+}
+
+// Returns the localized lines of the current page.
+func (self *Message) Lines() []string {
+	if !self.IsWrapped() {
+		if self.HasTwoLines() { return []string{Localize(self.FirstLine), Localize(self.SecondLine)} }
+		return []string{Localize(self.FirstLine)}
+	}
+	self.layout()
+	return self.pages[self.page]
+}
+
+func (self *Message) NumPages() int {
+	if !self.IsWrapped() { return 1 }
+	self.layout()
+	return len(self.pages)
+}
+
+func (self *Message) Page() int {
+	return self.page
+}
+
+func (self *Message) HasNextPage() bool {
+	return self.page + 1 < self.NumPages()
+}
+
+// Moves to the next page. Returns false if the message was already
+// on its last page, in which case skippable messages are typically
+// dismissed instead.
+func (self *Message) NextPage() bool {
+	if !self.HasNextPage() { return false }
+	self.page += 1
+	return true
+}
+
+// Goes back to the first page.
+func (self *Message) ResetPages() {
+	self.page = 0
+}
+
+// Limits the number of glyphs drawn for the current page. Spaces
+// don't count as glyphs. Use RevealAll() to draw the whole page again.
+func (self *Message) SetRevealedGlyphs(n int) {
+	if n < 0 { panic("negative revealed glyphs") }
+	self.revealedGlyphs = n
+	self.isPartiallyRevealed = true
+}
+
+func (self *Message) RevealAll() {
+	self.isPartiallyRevealed = false
+}
+
+func (self *Message) IsFullyRevealed() bool {
+	return !self.isPartiallyRevealed || self.revealedGlyphs >= self.NumPageGlyphs()
+}
+
+// Returns the number of glyphs in the current page.
+func (self *Message) NumPageGlyphs() int {
+	numGlyphs := 0
+	for _, line := range self.Lines() {
+		numGlyphs += countLineGlyphs(line)
+	}
+	return numGlyphs
+}
+
+// Returns the number of glyphs that can be drawn for the current
+// page, or -1 if there's no limit.
+func (self *Message) glyphLimit() int {
+	if !self.isPartiallyRevealed { return -1 }
+	return self.revealedGlyphs
+}
+
+// Returns the width of the longest line in the message. For wrapped
+// messages, all pages are considered, so the box size stays stable
+// while paging.
+func (self *Message) maxLineWidth() int {
+	lines := self.Lines()
+	if self.IsWrapped() {
+		lines = nil
+		for _, page := range self.pages { lines = append(lines, page...) }
+	}
+
+	maxWidth := 0
+	for _, line := range lines {
+		lineWidth := measureLocalizedLine(line)
+		if lineWidth > maxWidth { maxWidth = lineWidth }
+	}
+	return maxWidth
+}
+
+// Returns the max number of lines in a page of the message.
+func (self *Message) maxPageLines() int {
+	lines := self.Lines()
+	if self.IsWrapped() { return len(self.pages[0]) }
+	return len(lines)
+}
+
+// Wraps and pages the text if it hasn't been done yet, or if the
+// font or the language changed since the last time.
+func (self *Message) layout() {
+	langRevision := lang.Revision()
+	if self.pages != nil && self.layoutFont == pkgFont && self.layoutLangRevision == langRevision { return }
+	lines := WrapLines(Localize(self.text), self.wrapWidth)
+	pageLines := MsgPageLines
+	if self.unpaged { pageLines = len(lines) }
+	self.pages = self.pages[ : 0]
+	for start := 0; start < len(lines); start += pageLines {
+		end := start + pageLines
+		if end > len(lines) { end = len(lines) }
+		self.pages = append(self.pages, lines[start : end])
+	}
+	if len(self.pages) == 0 { self.pages = append(self.pages, []string{""}) }
+	if self.page >= len(self.pages) { self.page = len(self.pages) - 1 }
+	self.layoutFont = pkgFont
+	self.layoutLangRevision = langRevision
+}
+
 func NewMsg1(line string, clr color.RGBA) *Message {
 	return &Message{
 		FirstLine: line,
@@ -74,3 +246,27 @@ func NewSkippableMsg2(line1, line2 string, clr color.RGBA) *Message {
 		IsSkippable: true,
 	}
 }
+
+// Message with arbitrary text, wrapped to MsgWrapWidth.
+func NewWrappedMsg(txt string, clr color.RGBA) *Message {
+	return &Message{
+		Color: clr,
+		IsDialogue: false,
+		IsSkippable: false,
+		text: txt,
+		wrapWidth: MsgWrapWidth,
+	}
+}
+
+func NewSkippableWrappedMsg(txt string, clr color.RGBA) *Message {
+	msg := NewWrappedMsg(txt, clr)
+	msg.IsSkippable = true
+	return msg
+}
+
+func NewDialogueWrappedMsg(txt string, clr color.RGBA) *Message {
+	msg := NewWrappedMsg(txt, clr)
+	msg.IsDialogue = true
+	msg.IsSkippable = true
+	return msg
+}
@@ -0,0 +1,59 @@
+package text
+
+import "strings"
+import "testing"
+import "image/color"
+
+var textTestColor = color.RGBA{255, 255, 255, 255}
+
+func TestMessagePaging(t *testing.T) {
+	tests := []struct{
+		txt string
+		pages [][]string
+	}{
+		{ "", [][]string{{""}} },
+		{ "A\nB", [][]string{{"A", "B"}} },
+		{ "A\nB\n", [][]string{{"A", "B"}} },
+		{ "A\nB\nC", [][]string{{"A", "B"}, {"C"}} },
+		{ "A\nB\nC\nD\n", [][]string{{"A", "B"}, {"C", "D"}} },
+		{ "A\nB\nC\nD\nE", [][]string{{"A", "B"}, {"C", "D"}, {"E"}} },
+	}
+	for _, test := range tests {
+		msg := NewWrappedMsg(test.txt, textTestColor)
+		if msg.NumPages() != len(test.pages) {
+			t.Errorf("%q: got %d pages, expected %d", test.txt, msg.NumPages(), len(test.pages))
+			continue
+		}
+		for i, page := range test.pages {
+			if msg.Page() != i { t.Fatalf("%q: on page %d, expected %d", test.txt, msg.Page(), i) }
+			if !sameLines(msg.Lines(), page) {
+				t.Errorf("%q: page %d is %q, expected %q", test.txt, i, msg.Lines(), page)
+			}
+			if msg.NextPage() != (i + 1 < len(test.pages)) {
+				t.Errorf("%q: unexpected NextPage() result on page %d", test.txt, i)
+			}
+		}
+		msg.ResetPages()
+		if msg.Page() != 0 || !sameLines(msg.Lines(), test.pages[0]) {
+			t.Errorf("%q: ResetPages() didn't go back to the first page", test.txt)
+		}
+
+		msg.DisablePaging()
+		if msg.NumPages() != 1 || len(msg.Lines()) != len(strings.Split(strings.TrimSuffix(test.txt, "\n"), "\n")) {
+			t.Errorf("%q: got lines %q with paging disabled", test.txt, msg.Lines())
+		}
+	}
+}
+
+func TestExplicitLinesMessage(t *testing.T) {
+	long := strings.Repeat("LONG LINE ", 50) // never wrapped
+	msg := NewMsg1(long, textTestColor)
+	if !sameLines(msg.Lines(), []string{long}) { t.Fatalf("got lines %q", msg.Lines()) }
+	if msg.IsWrapped() || msg.NumPages() != 1 || msg.NextPage() { t.Fatal("explicit line message paging") }
+
+	msg = NewMsg2("FIRST", long, textTestColor)
+	if !sameLines(msg.Lines(), []string{"FIRST", long}) { t.Fatalf("got lines %q", msg.Lines()) }
+	if msg.NumPages() != 1 || msg.NextPage() { t.Fatal("explicit lines message paging") }
+	msg = NewMsg2("FIRST", "", textTestColor)
+	if !sameLines(msg.Lines(), []string{"FIRST"}) { t.Fatalf("got lines %q", msg.Lines()) }
+}
@@ -13,15 +13,16 @@ const LineInterspace = 2
 
 // for long text and so on
 func CenterRawDraw(canvas *ebiten.Image, lines []string, clr color.RGBA) {
+	lines = LocalizeLines(lines)
 	bounds := canvas.Bounds()
 	w, h := bounds.Dx(), bounds.Dy()
 
 	textHeight := len(lines)*LineHeight + (len(lines) - 1)*LineInterspace
 	y := h/2 - textHeight/2
 	for _, line := range lines {
-		lineWidth := MeasureLineWidth(line)
+		lineWidth := measureLocalizedLine(line)
 		x := w/2 - lineWidth/2
-		DrawLine(canvas, line, x, y, clr)
+		_ = drawLineGlyphs(canvas, line, x, y, clr, -1)
 		y += LineHeight + LineInterspace
 	}
 }
@@ -33,18 +34,17 @@ func Draw(canvas *ebiten.Image, cx, cy int, msg *Message) {
 	}
 	
 	// start calculating width and height
+	lines := msg.Lines()
 	height := 8*2 + 7
-	if msg.HasTwoLines() { height += LineHeight + LineInterspace }
+	height += (msg.maxPageLines() - 1)*(LineHeight + LineInterspace)
 	width := 9*2
 
 	// see which line is longest
-	w1 := MeasureLineWidth(msg.FirstLine)
-	w2 := MeasureLineWidth(msg.SecondLine)
-	if w2 > w1 { w1 = w2 }
-	width += w1
+	maxLineWidth := msg.maxLineWidth()
+	width += maxLineWidth
 
 	// determine start point
-	ox, oy := cx - w1/2, cy - height/2
+	ox, oy := cx - maxLineWidth/2, cy - height/2
 
 	// draw main box
 	fill(canvas, ox, oy, width, height, BackColor)
@@ -55,24 +55,35 @@ func Draw(canvas *ebiten.Image, cx, cy int, msg *Message) {
 	fill(canvas, ox + 1, oy + 2, 1, height - 4, FrontColor)
 	fill(canvas, ox + width - 2, oy + 2, 1, height - 4, FrontColor)
 
-	// draw first line
-	DrawLine(canvas, msg.FirstLine, ox + 9, oy + 8, msg.Color)
-	
-	// draw first line
-	if msg.HasTwoLines() {
-		DrawLine(canvas, msg.SecondLine, ox + 9, oy + 8 + LineHeight + LineInterspace, msg.Color)
+	// draw lines (only up to the glyph limit if partially revealed)
+	glyphsLeft := msg.glyphLimit()
+	for i, line := range lines {
+		y := oy + 8 + i*(LineHeight + LineInterspace)
+		numDrawn := drawLineGlyphs(canvas, line, ox + 9, y, msg.Color, glyphsLeft)
+		if glyphsLeft >= 0 { glyphsLeft -= numDrawn }
 	}
 
-	// apply skippable decoration
-	if msg.IsSkippable {
+	// apply skippable decoration (also used to indicate
+	// that there are more pages left)
+	if msg.IsSkippable || msg.HasNextPage() {
 		fill(canvas, ox + width - 14, oy + height, 11, 4, BackColor)
 		fill(canvas, ox + width - 13, oy + height - 6, 9, 9, FrontColor)
 		fill(canvas, ox + width - 12, oy + height - 5, 7, 7, BackColor)
-		DrawLine(canvas, string(KeyMsgI), ox + width - 10, oy + height - 5, msg.Color)
+		msgIWidth := pkgBitmaps[KeyMsgI].Bounds().Dx() // may change with SetKeyGlyphLabel()
+		DrawLine(canvas, string(KeyMsgI), ox + width - 9 - msgIWidth/2, oy + height - 5, msg.Color)
 	}
 
-	// apply dialogue decoration
-	if msg.IsDialogue {
+	// apply dialogue decoration (speaker name tab if any)
+	if msg.IsDialogue && msg.Speaker != "" {
+		nameWidth := MeasureLineWidth(msg.Speaker)
+		tabWidth, tabHeight := nameWidth + 12, LineHeight + 10
+		tabY := oy - tabHeight + 5
+		fill(canvas, ox + 3, tabY, tabWidth, tabHeight, BackColor)
+		fill(canvas, ox + 4, tabY + 1, tabWidth - 2, 1, FrontColor)
+		fill(canvas, ox + 4, tabY + 2, 1, tabHeight - 3, FrontColor)
+		fill(canvas, ox + 3 + tabWidth - 2, tabY + 2, 1, tabHeight - 3, FrontColor)
+		DrawLine(canvas, msg.Speaker, ox + 9, tabY + 4, msg.Color)
+	} else if msg.IsDialogue {
 		fill(canvas, ox + 3, oy - 2, 11, 2, BackColor)
 		fill(canvas, ox + 4, oy - 1, 9, 5, FrontColor)
 		fill(canvas, ox + 5, oy, 7, 3, BackColor)
@@ -81,38 +92,43 @@ func Draw(canvas *ebiten.Image, cx, cy int, msg *Message) {
 }
 
 func MeasureLineWidth(line string) int {
-	var prevIsSpace bool
-	width := 0
-	for i, codePoint := range line {
-		if codePoint == ' ' {
-			width += 4
-			prevIsSpace = true
-		} else {
-			if i != 0 && !prevIsSpace { width += 1 }
-			prevIsSpace = false
-			width += pkgBitmaps[codePoint].Bounds().Dx() // panic here => missing letter bitmap
-		}
-	}
-	return width
+	return measureLocalizedLine(Localize(line))
 }
 
 func DrawLine(canvas *ebiten.Image, line string, ox, oy int, textColor color.RGBA) {
-	var prevIsSpace bool
-	x := 0
+	_ = drawLineGlyphs(canvas, Localize(line), ox, oy, textColor, -1)
+}
+
+// The helpers below take lines that have already been localized.
+// Localizing twice would turn "@@" strings into "@" references.
+
+func measureLocalizedLine(line string) int {
+	return pkgFont.layout(mustParseMarkup(line).text, nil)
+}
+
+// Like DrawLine(), but drawing at most maxGlyphs glyphs (spaces not
+// included). Negative values mean no limit. Returns the number of
+// glyphs drawn.
+func drawLineGlyphs(canvas *ebiten.Image, line string, ox, oy int, textColor color.RGBA, maxGlyphs int) int {
+	markup := mustParseMarkup(line)
 	opts := ebiten.DrawImageOptions{}
-	opts.ColorScale.ScaleWithColor(textColor)
-	for i, codePoint := range line {
-		if codePoint == ' ' {
-			x += 4
-			prevIsSpace = true
-		} else {
-			if i != 0 && !prevIsSpace { x += 1 }
-			prevIsSpace = false
-			img := pkgBitmaps[codePoint]
-			opts.GeoM.Translate(float64(ox + x), float64(oy))
-			canvas.DrawImage(img, &opts)
-			opts.GeoM.Reset()
-			x += img.Bounds().Dx()
-		}
-	}
+	numGlyphs := 0
+	pkgFont.layout(markup.text, func(img *ebiten.Image, index, x, y int) {
+		if maxGlyphs >= 0 && numGlyphs >= maxGlyphs { return }
+		numGlyphs += 1
+		opts.ColorScale.ScaleWithColor(markup.colorAt(index, textColor))
+		opts.GeoM.Translate(float64(ox + x), float64(oy + y))
+		canvas.DrawImage(img, &opts)
+		opts.GeoM.Reset()
+		opts.ColorScale.Reset()
+	})
+	return numGlyphs
+}
+
+func countLineGlyphs(line string) int {
+	numGlyphs := 0
+	pkgFont.layout(mustParseMarkup(line).text, func(*ebiten.Image, int, int, int) {
+		numGlyphs += 1
+	})
+	return numGlyphs
 }
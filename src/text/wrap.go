@@ -0,0 +1,66 @@
+package text
+
+import "strings"
+
+// Splits the text into lines no wider than maxWidth pixels, breaking
+// at spaces when possible. Newlines force line breaks, and words too
+// long to fit in a line on their own are split wherever necessary.
+// A trailing newline doesn't add an empty line at the end. Widths
+// are measured with the current font. Markup color spans split across
+// lines are closed and reopened as needed.
+func WrapLines(txt string, maxWidth int) []string {
+	if maxWidth <= 0 { panic("maxWidth must be positive") }
+	var lines []string
+	for _, paragraph := range strings.Split(strings.TrimSuffix(txt, "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			if line != "" {
+				candidate := line + " " + word
+				if measureFragment(candidate) <= maxWidth {
+					line = candidate
+					continue
+				}
+				lines = append(lines, line)
+				line = ""
+			}
+
+			// split words that don't fit on their own
+			for measureFragment(word) > maxWidth {
+				head, tail := splitWordAt(word, maxWidth)
+				if tail == "" { break } // single code point, can't split
+				lines = append(lines, head)
+				word = tail
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return balanceMarkupLines(lines)
+}
+
+// Splits the word at the last point where the head still fits in
+// maxWidth. The head will have at least one code point. Markup tags
+// are never split.
+func splitWordAt(word string, maxWidth int) (string, string) {
+	split, firstSplit := 0, 0
+	for index := 0; index < len(word); {
+		token, err := nextMarkupToken(word, index)
+		if err != nil { panic(err) }
+		index = token.end
+		if strings.HasPrefix(token.tag, "color:") || token.tag == "/color" { continue }
+		if index == len(word) { continue }
+		if firstSplit == 0 { firstSplit = index }
+		if measureFragment(word[ : index]) > maxWidth { break }
+		split = index
+	}
+	if split == 0 { split = firstSplit } // not even a single code point fits
+	if split == 0 { return word, "" }
+	return word[ : split], word[split : ]
+}
+
+// Like MeasureLineWidth(), but tolerating unmatched closing tags.
+func measureFragment(fragment string) int {
+	line, err := parseMarkupFragment(fragment, true)
+	if err != nil { panic(err) }
+	return pkgFont.layout(line.text, nil)
+}
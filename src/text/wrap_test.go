@@ -0,0 +1,40 @@
+package text
+
+import "testing"
+
+func TestWrapLines(t *testing.T) {
+	tests := []struct{
+		txt string
+		widthOf string // max width is the width of this string
+		expected []string
+	}{
+		{ "", "AAAA", []string{""} },
+		{ "AB CD EF", "AB CD", []string{"AB CD", "EF"} },
+		{ "AB   CD", "AB CD EF", []string{"AB CD"} },
+		{ "AAAAAAAAAA", "AAAA", []string{"AAAA", "AAAA", "AA"} },
+		{ "AB CCCCCC", "CCCC", []string{"AB", "CCCC", "CC"} },
+		{ "AB\nCD", "AB CD EF", []string{"AB", "CD"} },
+		{ "AB\n\nCD", "AB CD EF", []string{"AB", "", "CD"} },
+		{ "AB\n", "AB CD EF", []string{"AB"} },
+		{ "AB\n\n", "AB CD EF", []string{"AB", ""} },
+		{ "\nAB", "AB CD EF", []string{"", "AB"} },
+	}
+	for _, test := range tests {
+		lines := WrapLines(test.txt, measureFragment(test.widthOf))
+		if !sameLines(lines, test.expected) {
+			t.Errorf("WrapLines(%q) = %q, expected %q", test.txt, lines, test.expected)
+		}
+	}
+
+	// single glyphs wider than the max width can't be split
+	lines := WrapLines("AB", 1)
+	if !sameLines(lines, []string{"A", "B"}) { t.Fatalf("got %q", lines) }
+}
+
+func sameLines(a, b []string) bool {
+	if len(a) != len(b) { return false }
+	for i, _ := range a {
+		if a[i] != b[i] { return false }
+	}
+	return true
+}
@@ -10,6 +10,18 @@ func OsArgReceived(arg string) bool {
 	return false
 }
 
+// Returns the value of an argument given either as "--arg value"
+// or "--arg=value". Linear, slow, whatever too.
+func OsArgValue(arg string) (string, bool) {
+	for i, osArg := range os.Args {
+		if osArg == arg && i + 1 < len(os.Args) { return os.Args[i + 1], true }
+		if len(osArg) > len(arg) && osArg[len(arg)] == '=' && osArg[ : len(arg)] == arg {
+			return osArg[len(arg) + 1 : ], true
+		}
+	}
+	return "", false
+}
+
 func FastFill[T any](buffer []T, value T) {
 	if len(buffer) <= 24 { // no-copy case
 		for i, _ := range buffer {